@@ -3,23 +3,37 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
-	"math/rand"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"d3k-agent/internal/app"
 	"d3k-agent/internal/brain"
-	"d3k-agent/internal/core/domain"
 	"d3k-agent/internal/core/ports"
-	"d3k-agent/internal/sites/botmadang"
+	"d3k-agent/internal/sites"
+	"d3k-agent/internal/sources/rss"
 	"d3k-agent/internal/storage"
 	"d3k-agent/internal/ui/telegram"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	initTimeout     = 10 * time.Minute // 사이트 초기화(등록/토큰 입력 대기 포함) 한 번의 시간 제한
+	initBackoffBase = 10 * time.Minute // 초기화 실패 후 첫 재시도 간격 (사이클 주기와 같음)
+	initBackoffMax  = 6 * time.Hour
+)
+
+// initBackoff는 n번 연속 실패한 사이트를 다시 초기화할 때까지 기다릴 시간입니다 (2배씩, 최대 initBackoffMax).
+func initBackoff(n int) time.Duration {
+	wait := initBackoffBase
+	for i := 1; i < n && wait < initBackoffMax; i++ { wait *= 2 }
+	if wait > initBackoffMax { wait = initBackoffMax }
+	return wait
+}
+
 func main() {
 	godotenv.Load()
 	fmt.Println("🤖 d3k Integrated Agent Starting... [v1.3.7-Verbose-Logs]")
@@ -42,20 +56,48 @@ func main() {
 		fmt.Println("📄 Storage: JSON File Mode")
 	}
 
-	myBrain, _ := brain.NewGeminiBrain(ctx, os.Getenv("GEMINI_API_KEY"))
-	if myBrain != nil { fmt.Println("🧠 Brain: Gemini Ready") }
+	var myBrain ports.Brain
+	if b, err := brain.FromEnv(ctx, store); err == nil {
+		myBrain = b
+		if router, ok := b.Provider.(*brain.Router); ok { fmt.Printf("🧠 Brain: %s\n", router.Describe()) }
+	} else {
+		fmt.Printf("⚠️  Brain unavailable: %v\n", err)
+	}
 
-	ui, _ := telegram.NewTelegramUI(os.Getenv("TELEGRAM_BOT_TOKEN"), os.Getenv("TELEGRAM_CHAT_ID"))
-	if ui != nil { fmt.Println("📲 UI: Telegram Connected") }
+	var ui ports.Interaction
+	if tg, err := telegram.NewTelegramUI(os.Getenv("TELEGRAM_BOT_TOKEN"), os.Getenv("TELEGRAM_CHAT_ID")); err == nil {
+		ui = tg
+		fmt.Println("📲 UI: Telegram Connected")
+	}
 
-	agents := []ports.Site{
-		botmadang.NewClient(store),
+	agents, err := sites.DefaultRegistry().Build(sites.EnabledFromEnv(), sites.Deps{Storage: store, UI: ui})
+	if err != nil {
+		fmt.Printf("❌ Site config error: %v\n", err)
+		os.Exit(1)
 	}
-	for _, agent := range agents { 
-		if err := agent.Initialize(ctx); err != nil {
-			fmt.Printf("❌ [%s] Init Failed: %v\n", agent.Name(), err)
+	ready := make(map[string]bool)
+	failures := make(map[string]int)
+	retryAt := make(map[string]time.Time)
+	initAgents := func() {
+		for _, agent := range agents {
+			if ready[agent.Name()] || time.Now().Before(retryAt[agent.Name()]) { continue }
+			// 등록/토큰 입력을 묻는 동안 사이클 전체가 멈추지 않도록 시간 제한을 두고, 실패가 이어지면 다시 묻는 간격을 늘림
+			initCtx, cancel := context.WithTimeout(ctx, initTimeout)
+			err := agent.Initialize(initCtx)
+			cancel()
+			if err != nil {
+				failures[agent.Name()]++
+				wait := initBackoff(failures[agent.Name()])
+				retryAt[agent.Name()] = time.Now().Add(wait)
+				fmt.Printf("❌ [%s] Init Failed: %v (retry in %v)\n", agent.Name(), err, wait)
+				continue
+			}
+			ready[agent.Name()] = true
+			fmt.Printf("🌐 [%s] Capabilities: %s\n", agent.Name(), strings.Join(app.Describe(agent), ", "))
+			if worker, ok := agent.(ports.BackgroundWorker); ok { go worker.RunBackground(ctx) }
 		}
 	}
+	initAgents()
 
 	trigger := make(chan bool, 1)
 	go func() {
@@ -66,13 +108,43 @@ func main() {
 		}
 	}()
 
+	runner := app.NewAgent(myBrain, ui, store)
+	if feeds := rss.ParseFeeds(os.Getenv("FEEDS")); len(feeds) > 0 {
+		runner.Sources = append(runner.Sources, rss.NewReader(store, feeds))
+		fmt.Printf("📰 Sources: %d RSS/Atom feeds\n", len(feeds))
+	}
+	runner.CiteSources = os.Getenv("POST_CITE_SOURCES") == "true"
+	runner.AutoUpvote = os.Getenv("AUTO_UPVOTE") == "true"
+	if v := os.Getenv("DRAFT_CANDIDATES"); v != "" { // 기본은 후보 하나 (여러 후보는 생성·비평·모순 확인 호출이 후보 수만큼 늘어남)
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 { fmt.Printf("⚠️  Invalid DRAFT_CANDIDATES %q, using 1\n", v); n = 1 }
+		runner.Candidates = n
+	}
+	if embedder, err := brain.EmbedderFromEnv(ctx); err != nil {
+		fmt.Printf("⚠️  Embeddings unavailable: %v\n", err)
+	} else if embedder != nil {
+		runner.Embedder = embedder
+		fmt.Println("🧬 Memory: semantic recall enabled")
+		if indexer, ok := store.(ports.VectorIndexer); ok {
+			dims, err := brain.EmbeddingDims(ctx, embedder)
+			if err == nil { err = indexer.PrepareVectorIndex(ctx, dims) }
+			if err != nil { fmt.Printf("⚠️  Vector index unavailable, using unindexed search: %v\n", err) }
+		}
+	}
+	if commander, ok := ui.(ports.Commander); ok {
+		commander.HandleCommand("status", func(ctx context.Context) string { return runner.Status(ctx, agents) })
+	}
 	fmt.Println("🚀 System ready. Listening for activities...")
 
 	firstRun := true
 	for {
 		fmt.Printf("\n--- 🔄 Check Cycle (%s) ---\n", time.Now().Format("15:04:05"))
+		initAgents() // 초기화에 실패한 사이트는 저장된 키를 다시 확인
+		runner.RefreshSources(ctx)
+		runner.ConsolidateKnowledge(ctx)
 		for _, agent := range agents {
-			processAgent(ctx, agent, myBrain, ui, store, firstRun)
+			if !ready[agent.Name()] { continue }
+			runner.Process(ctx, agent, firstRun)
 		}
 		firstRun = false
 
@@ -84,187 +156,3 @@ func main() {
 		}
 	}
 }
-
-func processAgent(ctx context.Context, agent ports.Site, brain ports.Brain, ui ports.Interaction, store ports.Storage, firstRun bool) {
-	fmt.Printf("[%s] Status Update:\n", agent.Name())
-	
-	fmt.Print("  🔔 Notifs: ")
-	handleNotifications(ctx, agent, brain, ui, store)
-	
-	fmt.Print("  🌟 Proactive: ")
-	handleProactiveCommenting(ctx, agent, brain, ui, store)
-	
-	fmt.Print("  📝 Posting: ")
-	handleDailyPosting(ctx, agent, brain, ui, store, firstRun)
-	
-	fmt.Print("  🧠 Learning: ")
-	learnFromCommunity(ctx, agent, brain, store)
-}
-
-func learnFromCommunity(ctx context.Context, agent ports.Site, brain ports.Brain, store ports.Storage) {
-	posts, err := agent.GetRecentPosts(ctx, 3)
-	if err != nil { fmt.Printf("Error: %v\n", err); return }
-	
-	learned := 0
-	for _, p := range posts {
-		insightText, err := brain.SummarizeInsight(ctx, p)
-		if err == nil && insightText != "" {
-			store.SaveInsight(ctx, domain.Insight{PostID: p.ID, Source: agent.Name(), Topic: p.Title, Content: insightText})
-			learned++
-		}
-	}
-	fmt.Printf("%d new items learned.\n", learned)
-}
-
-func handleNotifications(ctx context.Context, agent ports.Site, brain ports.Brain, ui ports.Interaction, store ports.Storage) {
-	today := time.Now().Format("2006-01-02")
-	count, _, _ := store.GetCommentStats(agent.Name())
-	if count >= 20 { 
-		fmt.Printf("Daily limit reached (%d/20).\n", count)
-		return 
-	}
-
-	notifs, err := agent.GetNotifications(ctx, true)
-	if err != nil { fmt.Printf("Error: %v\n", err); return }
-	if len(notifs) == 0 { 
-		fmt.Println("0 unread notifications.")
-		return 
-	}
-
-	groups := make(map[string]struct{ title, latestCID, postID string; contents, notifIDs []string })
-	for _, n := range notifs {
-		if n.Type != "comment_on_post" && n.Type != "reply_to_comment" { continue }
-		g := groups[n.PostID]; g.title = n.PostTitle; g.latestCID = n.CommentID; g.postID = n.PostID
-		g.contents = append(g.contents, fmt.Sprintf("- %s: %s", n.ActorName, n.Content))
-		g.notifIDs = append(g.notifIDs, n.ID)
-		groups[n.PostID] = g
-	}
-
-	if len(groups) == 0 {
-		fmt.Println("No actionable comment notifications.")
-		return
-	}
-
-	fmt.Printf("Found %d threads to reply.\n", len(groups))
-	for pid, g := range groups {
-		if brain == nil || ui == nil || count >= 20 { break }
-		peerText := strings.Join(g.contents, "\n")
-		reply, err := brain.GenerateReply(ctx, g.title, peerText)
-		if err != nil { fmt.Printf("    ❌ Brain failed: %v\n", err); continue }
-
-		summary, _ := brain.SummarizeInsight(ctx, domain.Post{Content: peerText})
-		
-		tgTitle := fmt.Sprintf("💬 [%s] 답글 승인", agent.Name())
-		tgBody := fmt.Sprintf("📍 글: %s\n📄 요약: %s\n\n🤖 답글: %s", g.title, summary, reply)
-		
-		action, err := ui.Confirm(ctx, tgTitle, tgBody)
-		if err == nil && action == ports.ActionApprove {
-			if err := agent.ReplyToComment(ctx, pid, g.latestCID, reply); err == nil {
-				for _, nid := range g.notifIDs { agent.MarkNotificationRead(ctx, nid) }
-				store.IncrementCommentCount(agent.Name(), today)
-				count++
-				fmt.Println("    ✅ Approved and Sent.")
-			}
-		} else {
-			fmt.Println("    ⏩ Skipped/Rejected.")
-		}
-	}
-}
-
-func handleProactiveCommenting(ctx context.Context, agent ports.Site, brain ports.Brain, ui ports.Interaction, store ports.Storage) {
-	today := time.Now().Format("2006-01-02")
-	count, _, _ := store.GetCommentStats(agent.Name())
-	if count >= 20 { 
-		fmt.Printf("Daily limit reached (%d/20).\n", count)
-		return 
-	}
-
-	posts, err := agent.GetRecentPosts(ctx, 5)
-	if err != nil { fmt.Printf("Error: %v\n", err); return }
-	
-	evaluated := 0
-	for _, p := range posts {
-		if done, _ := store.IsProactiveDone(agent.Name(), p.ID); done { continue }
-		evaluated++
-		score, reason, err := brain.EvaluatePost(ctx, p)
-		if err != nil || score < 7 { continue }
-
-		fmt.Printf("\n    ✨ High interest post (%dpt): %s\n", score, p.Title)
-		reply, _ := brain.GenerateReply(ctx, p.Title, p.Content)
-		summary, _ := brain.SummarizeInsight(ctx, p)
-
-		tgTitle := fmt.Sprintf("🌟 [%s] 선제 댓글 (%d점)", agent.Name(), score)
-		tgBody := fmt.Sprintf("📍 제목: %s\n📄 요약: %s\n\n🤖 댓글: %s\n💡 이유: %s", p.Title, summary, reply, reason)
-		
-		action, err := ui.Confirm(ctx, tgTitle, tgBody)
-		if err == nil && action == ports.ActionApprove {
-			if err := agent.CreateComment(ctx, p.ID, reply); err == nil {
-				store.MarkProactive(agent.Name(), p.ID)
-				store.IncrementCommentCount(agent.Name(), today)
-				count++
-				fmt.Println("    ✅ Approved and Sent.")
-			}
-		} else {
-			store.MarkProactive(agent.Name(), p.ID)
-			fmt.Println("    ⏩ Rejected and Marked as Done.")
-		}
-	}
-	fmt.Printf("%d posts evaluated.\n", evaluated)
-}
-
-func handleDailyPosting(ctx context.Context, agent ports.Site, brain ports.Brain, ui ports.Interaction, store ports.Storage, firstRun bool) {
-	today := time.Now().Format("2006-01-02")
-	count, lastDate, lastTs, _ := store.GetPostStats(agent.Name())
-	if lastDate != today { count = 0 }
-
-	if count >= 4 {
-		fmt.Printf("Daily limit reached (%d/4).\n", count)
-		return
-	}
-
-	elapsed := time.Since(time.Unix(lastTs, 0))
-	if lastTs != 0 && elapsed < 2*time.Hour {
-		fmt.Printf("Cooldown (%.0f mins left).\n", 120-elapsed.Minutes())
-		return
-	}
-
-	chance := rand.Float32()
-	if !firstRun && chance > 0.4 {
-		fmt.Printf("Probability skip (Roll: %.2f > 0.40).\n", chance)
-		return
-	}
-
-	topics := []string{"금융 경제", "IT 기술", "일상 지혜", "커리어"}
-	topic := topics[rand.Intn(len(topics))]
-	fmt.Printf("Generating post about '%s'... ", topic)
-
-	raw, err := brain.GeneratePost(ctx, topic)
-	if err != nil { fmt.Printf("❌ AI Error: %v\n", err); return }
-
-	// JSON 파싱 안정화
-	cleaned := raw
-	if start := strings.Index(raw, "{"); start != -1 {
-		if end := strings.LastIndex(raw, "}"); end != -1 && end > start {
-			cleaned = raw[start : end+1]
-		}
-	}
-	var p struct { Title string `json:"title"`; Content string `json:"content"`; Sub string `json:"submadang"` }
-	if err := json.Unmarshal([]byte(cleaned), &p); err != nil {
-		fmt.Printf("❌ Parse Error. ")
-		p.Title = "새로운 디지털 소식"; p.Content = raw
-	}
-
-	tgTitle := fmt.Sprintf("🚀 [%s] 새 글 승인", agent.Name())
-	tgBody := fmt.Sprintf("📌 제목: %s\n\n📝 내용:\n%s", p.Title, p.Content)
-	
-	action, err := ui.Confirm(ctx, tgTitle, tgBody)
-	if err == nil && action == ports.ActionApprove {
-		final, _ := json.Marshal(map[string]string{"title": p.Title, "content": p.Content, "submadang": p.Sub})
-		if err := agent.CreatePost(ctx, domain.Post{Content: string(final), Source: agent.Name()}); err == nil {
-			store.IncrementPostCount(agent.Name(), today, time.Now().Unix())
-			fmt.Println("✅ Success.")
-		}
-	} else {
-		fmt.Println("⏩ Rejected.")
-	}
-}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"d3k-agent/internal/cassette"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"d3k-agent/internal/sites"
+)
+
+// replaycheck는 기록된 카세트(HTTP_CASSETTE_MODE=record로 실행해 모은 실제 응답)를 strict 모드로 재생해
+// 각 사이트 어댑터의 GetRecentPosts/GetNotifications 디코딩이 깨지지 않았는지 확인합니다.
+// 기록 당시와 같은 사이트 설정(예: MASTODON_BASE_URL)이 필요합니다.
+func main() {
+	dir := flag.String("dir", envOr("HTTP_CASSETTE_DIR", "data/cassettes"), "cassette directory")
+	limits := flag.String("limits", "3,5", "GetRecentPosts limit 목록 (에이전트가 쓰는 값)")
+	flag.Parse()
+
+	os.Setenv("HTTP_CASSETTE_MODE", string(cassette.ModeStrict))
+	os.Setenv("HTTP_CASSETTE_DIR", *dir)
+
+	ctx := context.Background()
+	registry := sites.DefaultRegistry()
+	failed, checked := 0, 0
+	for _, name := range registry.Names() {
+		if _, err := os.Stat(filepath.Join(*dir, name+".json")); err != nil { continue }
+		built, err := registry.Build([]string{name}, sites.Deps{})
+		if err != nil { fmt.Printf("❌ [%s] %v\n", name, err); failed++; continue }
+		site := built[0]
+		checked++
+		before, calls := skipped, 0
+
+		for _, s := range strings.Split(*limits, ",") {
+			limit, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil { continue }
+			posts, err := site.GetRecentPosts(ctx, limit)
+			calls++
+			failed += report(name, fmt.Sprintf("GetRecentPosts(%d)", limit), len(posts), err, postProblems(posts))
+		}
+		if notifier, ok := site.(ports.Notifier); ok {
+			notifs, err := notifier.GetNotifications(ctx, true, 20)
+			calls++
+			failed += report(name, "GetNotifications(unread)", len(notifs), err, notificationProblems(notifs))
+		}
+		if calls > 0 && skipped-before == calls {
+			fmt.Printf("❌ [%s] no call matched the cassette (기록 당시와 사이트 설정이 다른지 확인하세요)\n", name)
+			failed++
+		}
+	}
+
+	if checked == 0 { fmt.Printf("⚠️  %s 에 카세트가 없습니다\n", *dir) }
+	if failed > 0 {
+		fmt.Printf("❌ %d check(s) failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("✅ replay check passed")
+}
+
+// skipped는 카세트에 기록되지 않아 건너뛴 호출 수입니다.
+var skipped int
+
+// report는 결과를 출력하고 실패면 1을 돌려줍니다. 기록되지 않은 호출은 건너뜁니다.
+func report(site, call string, n int, err error, problems []string) int {
+	switch {
+	case errors.Is(err, cassette.ErrNoMatch):
+		fmt.Printf("⏭️  [%s] %s: not recorded\n", site, call)
+		skipped++
+		return 0
+	case err != nil:
+		fmt.Printf("❌ [%s] %s: %v\n", site, call, err)
+		return 1
+	case len(problems) > 0:
+		fmt.Printf("❌ [%s] %s: %d item(s) decoded with missing fields\n", site, call, n)
+		for _, p := range problems { fmt.Printf("   - %s\n", p) }
+		return 1
+	}
+	fmt.Printf("✅ [%s] %s: %d item(s)\n", site, call, n)
+	return 0
+}
+
+func postProblems(posts []domain.Post) []string {
+	var res []string
+	for i, p := range posts {
+		if p.ID == "" { res = append(res, fmt.Sprintf("posts[%d]: empty id", i)) }
+		if p.Content == "" && p.Title == "" { res = append(res, fmt.Sprintf("posts[%d] %s: empty title and content", i, p.ID)) }
+		if p.Author == "" { res = append(res, fmt.Sprintf("posts[%d] %s: empty author", i, p.ID)) }
+		if p.CreatedAt.IsZero() { res = append(res, fmt.Sprintf("posts[%d] %s: zero created_at", i, p.ID)) }
+	}
+	return res
+}
+
+func notificationProblems(notifs []domain.Notification) []string {
+	var res []string
+	for i, n := range notifs {
+		if n.ID == "" { res = append(res, fmt.Sprintf("notifications[%d]: empty id", i)) }
+		if n.Type == "" { res = append(res, fmt.Sprintf("notifications[%d] %s: empty type", i, n.ID)) }
+		if n.PostID == "" { res = append(res, fmt.Sprintf("notifications[%d] %s: empty post id", i, n.ID)) }
+		if n.ActorName == "" { res = append(res, fmt.Sprintf("notifications[%d] %s: empty actor", i, n.ID)) }
+	}
+	return res
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" { return v }
+	return def
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"d3k-agent/internal/sandbox"
+)
+
+// sandbox는 오프라인 개발용 로컬 봇마당 서버입니다.
+// 에이전트는 BOTMADANG_BASE_URL=http://localhost:8787/api/v1 BOTMADANG_API_KEY=<-key 값> 으로 접속합니다.
+// -mastodon을 주면 같은 프로세스에서 가짜 Mastodon 서버도 띄웁니다.
+func main() {
+	addr := flag.String("addr", "localhost:8787", "listen address")
+	key := flag.String("key", "botmadang_sandbox_d3k", "미리 발급해 둘 d3k 에이전트 API 키 (빈 값이면 등록 절차부터 진행)")
+	interval := flag.Duration("interval", 30*time.Second, "가짜 봇 행동 주기")
+	seed := flag.Int64("seed", 1, "가짜 봇 난수 시드")
+	noBots := flag.Bool("no-bots", false, "가짜 봇 없이 빈 커뮤니티로 시작")
+	mastodonAddr := flag.String("mastodon", "", "가짜 Mastodon 서버 주소 (예: localhost:8788, 빈 값이면 띄우지 않음)")
+	mastodonToken := flag.String("mastodon-token", "mastodon_sandbox_d3k", "가짜 Mastodon 서버가 받는 액세스 토큰")
+	mastodonChars := flag.Int("mastodon-chars", 500, "가짜 Mastodon 인스턴스의 글자 수 제한")
+	flag.Parse()
+
+	store := sandbox.NewStore()
+	if *key != "" {
+		store.Provision("d3k", "d3k 에이전트 (샌드박스)", *key)
+	}
+
+	ctx := context.Background()
+	if !*noBots {
+		bots := sandbox.SeedBots(store, sandbox.DefaultScripts, *seed)
+		go sandbox.RunBots(ctx, bots, *interval)
+		fmt.Printf("🤖 가짜 봇 %d개 가동 (주기 %v)\n", len(bots), *interval)
+	}
+
+	if *mastodonAddr != "" {
+		fmt.Printf("🐘 Mastodon sandbox: http://%s (글자 수 제한 %d)\n", *mastodonAddr, *mastodonChars)
+		fmt.Printf("   MASTODON_BASE_URL=http://%s MASTODON_ACCESS_TOKEN=%s\n", *mastodonAddr, *mastodonToken)
+		go func() {
+			if err := http.ListenAndServe(*mastodonAddr, sandbox.NewMastodon(*mastodonToken, *mastodonChars)); err != nil { fmt.Printf("❌ mastodon sandbox: %v\n", err) }
+		}()
+	}
+
+	base := "http://" + *addr
+	fmt.Printf("🏖️  Botmadang sandbox: %s/api/v1\n", base)
+	if *key != "" {
+		fmt.Printf("   BOTMADANG_BASE_URL=%s/api/v1 BOTMADANG_API_KEY=%s\n", base, *key)
+	}
+	if err := http.ListenAndServe(*addr, sandbox.NewServer(store, base)); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
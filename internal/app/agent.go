@@ -0,0 +1,358 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 사이트별 하루 활동 한도. /status도 같은 값으로 표시합니다.
+const (
+	dailyPostLimit    = 4  // 하루 글 수
+	dailyCommentLimit = 20 // 하루 댓글 수 (알림 답글 + 선제 댓글)
+)
+
+// notificationLimit은 한 사이클에 읽을 안 읽은 알림 수입니다.
+const notificationLimit = 20
+
+// Agent는 사이트별 활동 루틴(알림 답글, 선제 댓글, 글 작성, 학습)을 실행하는 유스케이스 계층입니다.
+// 선택 기능(ports.Notifier 등)은 사이트가 지원할 때만 사용합니다.
+type Agent struct {
+	Brain       ports.Brain
+	UI          ports.Interaction
+	Storage     ports.Storage
+	Sources     []ports.Source // 읽기 전용 지식 소스 (RSS 등)
+	Embedder    ports.Embedder // 있으면 insight를 벡터화해 의미 기반으로 회상
+	CiteSources bool           // 게시하는 글 끝에 검색 출처("참고") 목록을 붙임
+	Candidates  int            // 요청마다 만들 후보 수 (2 이상이면 비평으로 순위를 매겨 운영자가 고름)
+	AutoUpvote  bool           // 승인된 선제 댓글의 대상 글을 추천함 (사이트가 ports.Voter일 때만)
+}
+
+func NewAgent(brain ports.Brain, ui ports.Interaction, storage ports.Storage) *Agent {
+	return &Agent{Brain: brain, UI: ui, Storage: storage}
+}
+
+// Process는 한 사이클 동안 사이트의 모든 루틴을 차례로 실행합니다.
+func (a *Agent) Process(ctx context.Context, site ports.Site, firstRun bool) {
+	ctx = ports.WithSite(ctx, site.Name())
+	fmt.Printf("[%s] Status Update:\n", site.Name())
+
+	fmt.Print("  🔔 Notifs: ")
+	if notifier, ok := site.(ports.Notifier); ok {
+		a.handleNotifications(ctx, site, notifier)
+	} else {
+		fmt.Println("Not supported.")
+	}
+
+	// LLM 한도가 얼마 남지 않으면 알림 답글만 하고 선택적인 루틴은 건너뜀
+	left := a.remainingBudget()
+	lowBudget := left >= 0 && left < lowBudgetReserve
+
+	fmt.Print("  🌟 Proactive: ")
+	if lowBudget {
+		fmt.Printf("Low brain budget (%d requests left), skipped.\n", left)
+	} else {
+		a.handleProactiveCommenting(ctx, site)
+	}
+
+	fmt.Print("  📝 Posting: ")
+	if lowBudget {
+		fmt.Printf("Low brain budget (%d requests left), skipped.\n", left)
+	} else {
+		a.handleDailyPosting(ctx, site, firstRun)
+	}
+
+	fmt.Print("  🧠 Learning: ")
+	a.learnFromCommunity(ctx, site)
+}
+
+// Describe는 사이트가 지원하는 선택 기능 목록을 돌려줍니다.
+func Describe(site ports.Site) []string {
+	var caps []string
+	if _, ok := site.(ports.Notifier); ok { caps = append(caps, "notifications") }
+	if _, ok := site.(ports.Threader); ok { caps = append(caps, "threads") }
+	if _, ok := site.(ports.Voter); ok { caps = append(caps, "votes") }
+	if _, ok := site.(ports.BoardLister); ok { caps = append(caps, "boards") }
+	if _, ok := site.(ports.ProfileProvider); ok { caps = append(caps, "profile") }
+	if _, ok := site.(ports.BackgroundWorker); ok { caps = append(caps, "background") }
+	return caps
+}
+
+func (a *Agent) learnFromCommunity(ctx context.Context, site ports.Site) {
+	if a.Brain == nil { fmt.Println("No brain."); return }
+	posts, err := site.GetRecentPosts(ctx, 3)
+	if err != nil { fmt.Printf("Error: %v\n", err); return }
+
+	learned, known := 0, 0
+	for _, p := range posts {
+		hash := postHash(p)
+		if has, _ := a.Storage.HasInsight(ctx, site.Name(), p.ID, hash); has { known++; continue } // 내용이 바뀌지 않은 글은 다시 요약하지 않음
+		insightText, err := a.Brain.SummarizeInsight(ctx, p)
+		if err == nil && insightText != "" {
+			a.saveInsight(ctx, domain.Insight{PostID: p.ID, Source: site.Name(), Topic: p.Title, Content: insightText, ContentHash: hash})
+			learned++
+		}
+	}
+	fmt.Printf("%d new items learned (%d already known)", learned, known)
+	if n := a.embedBacklog(ctx); n > 0 { fmt.Printf(", %d memories embedded", n) }
+	fmt.Println(".")
+}
+
+func (a *Agent) handleNotifications(ctx context.Context, site ports.Site, notifier ports.Notifier) {
+	today := time.Now().Format("2006-01-02")
+	count, _, _ := a.Storage.GetCommentStats(site.Name())
+	if count >= dailyCommentLimit {
+		fmt.Printf("Daily limit reached (%d/%d).\n", count, dailyCommentLimit)
+		return
+	}
+
+	notifs, err := notifier.GetNotifications(ctx, true, notificationLimit)
+	if err != nil { fmt.Printf("Error: %v\n", err); return }
+	if len(notifs) == 0 {
+		fmt.Println("0 unread notifications.")
+		return
+	}
+
+	// 오래된 알림부터 처리함: 한도에 걸려 남는 쪽이 최근 알림이어야 다음 사이클에 이어서 처리됨
+	sort.SliceStable(notifs, func(i, j int) bool { return notifs[i].CreatedAt.Before(notifs[j].CreatedAt) })
+	groups := make(map[string]struct{ title, latestCID, postID string; contents, notifIDs, actors []string })
+	var order []string
+	for _, n := range notifs {
+		if n.Type != "comment_on_post" && n.Type != "reply_to_comment" { continue }
+		g, seen := groups[n.PostID]
+		if !seen { order = append(order, n.PostID) }
+		g.title = n.PostTitle; g.latestCID = n.CommentID; g.postID = n.PostID
+		g.contents = append(g.contents, fmt.Sprintf("- %s: %s", n.ActorName, n.Content))
+		g.notifIDs = append(g.notifIDs, n.ID)
+		g.actors = appendUnique(g.actors, len(notifs), n.ActorName)
+		groups[n.PostID] = g
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No actionable comment notifications.")
+		return
+	}
+
+	fmt.Printf("Found %d threads to reply.\n", len(groups))
+	for _, pid := range order {
+		g := groups[pid]
+		if a.Brain == nil || a.UI == nil || count >= dailyCommentLimit { break }
+		peerText := strings.Join(g.contents, "\n")
+		memories := a.recall(ctx, site.Name(), g.title+" "+peerText, pid)
+		knowledge := a.knowledgeFor(ctx, g.title+" "+peerText)
+		rels := a.relationshipsFor(ctx, site.Name(), g.actors)
+		replies, err := a.Brain.GenerateReplyCandidates(ctx, g.title, peerText, domain.PromptContext{Memories: memories, Relationships: rels, Knowledge: knowledge, Recent: a.recentOwn(ctx)}, a.candidateCount())
+		if err != nil { fmt.Printf("    ❌ Brain failed: %v\n", err); continue }
+		cands := replyCandidates(replies)
+		conflicts := a.checkCandidates(ctx, cands)
+
+		summary, _ := a.Brain.SummarizeInsight(ctx, domain.Post{Content: peerText})
+
+		tgTitle := fmt.Sprintf("💬 [%s] 답글 승인", site.Name())
+		tgBody := fmt.Sprintf("📍 글: %s\n📄 요약: %s\n\n🤖 답글: %s%s\n🏷️ 프롬프트: %s%s%s%s%s", g.title, summary, a.describeCandidates(cands), describeContradictions(conflicts), replies[0].PromptVersion, describeNotes(candidateNotes(cands)), describeRelationships(rels), describeMemories(memories), describeKnowledge(knowledge))
+
+		records := make([]domain.Draft, len(replies))
+		for i, r := range replies { records[i] = domain.Draft{Source: site.Name(), Kind: "reply", TargetID: pid, Title: g.title, Content: r.Content, PromptVersion: r.PromptVersion, Critique: r.Critique} }
+		status, chosen := "rejected", a.choose(ctx, tgTitle, tgBody, cands)
+		if chosen >= 0 {
+			reply := replies[chosen]
+			status = "failed"
+			if err := replyInThread(ctx, site, pid, g.latestCID, reply.Content); err == nil {
+				status = "approved"
+				for _, nid := range g.notifIDs { notifier.MarkNotificationRead(ctx, nid) }
+				for _, actor := range g.actors { a.rememberExchange(ctx, site.Name(), actor, g.title, linesBy(g.contents, actor), reply.Content) }
+				a.retireConflicts(ctx, conflicts, chosen)
+				a.Storage.IncrementCommentCount(site.Name(), today)
+				count++
+				fmt.Println("    ✅ Approved and Sent.")
+			}
+		} else {
+			fmt.Println("    ⏩ Skipped/Rejected.")
+		}
+		a.recordChoice(ctx, records, chosen, status)
+	}
+}
+
+// replyInThread는 스레드를 지원하는 사이트면 답글로, 아니면 일반 댓글로 작성합니다.
+func replyInThread(ctx context.Context, site ports.Site, postID, parentCommentID, content string) error {
+	if threader, ok := site.(ports.Threader); ok && parentCommentID != "" {
+		return threader.ReplyToComment(ctx, postID, parentCommentID, content)
+	}
+	return site.CreateComment(ctx, postID, content)
+}
+
+func (a *Agent) handleProactiveCommenting(ctx context.Context, site ports.Site) {
+	if a.Brain == nil || a.UI == nil { fmt.Println("No brain or UI."); return }
+	today := time.Now().Format("2006-01-02")
+	count, _, _ := a.Storage.GetCommentStats(site.Name())
+	if count >= dailyCommentLimit {
+		fmt.Printf("Daily limit reached (%d/%d).\n", count, dailyCommentLimit)
+		return
+	}
+
+	posts, err := site.GetRecentPosts(ctx, 5)
+	if err != nil { fmt.Printf("Error: %v\n", err); return }
+
+	var candidates []domain.Post
+	for _, p := range posts {
+		if done, _ := a.Storage.IsProactiveDone(site.Name(), p.ID); !done { candidates = append(candidates, p) }
+	}
+	evals, evaluated := a.evaluatePosts(ctx, site.Name(), candidates)
+	for _, p := range candidates {
+		eval, ok := evals[p.ID]
+		if !ok || eval.Score < 7 { continue }
+
+		fmt.Printf("\n    ✨ High interest post (%dpt): %s\n", eval.Score, p.Title)
+		memories := a.recall(ctx, site.Name(), p.Title+" "+p.Content, p.ID)
+		knowledge := a.knowledgeFor(ctx, p.Title+" "+p.Content)
+		rels := a.relationshipsFor(ctx, site.Name(), []string{p.Author})
+		replies, err := a.Brain.GenerateReplyCandidates(ctx, p.Title, p.Content, domain.PromptContext{Memories: memories, Relationships: rels, Knowledge: knowledge, Recent: a.recentOwn(ctx)}, a.candidateCount())
+		if err != nil { fmt.Printf("    ❌ Brain failed: %v\n", err); continue }
+		cands := replyCandidates(replies)
+		conflicts := a.checkCandidates(ctx, cands)
+		summary, _ := a.Brain.SummarizeInsight(ctx, p)
+
+		tgTitle := fmt.Sprintf("🌟 [%s] 선제 댓글 (%d점)", site.Name(), eval.Score)
+		tgBody := fmt.Sprintf("📍 제목: %s\n📄 요약: %s\n\n🤖 댓글: %s%s\n💡 이유: %s\n🏷️ 프롬프트: %s%s%s%s%s", p.Title, summary, a.describeCandidates(cands), describeContradictions(conflicts), eval.Reason, replies[0].PromptVersion, describeNotes(candidateNotes(cands)), describeRelationships(rels), describeMemories(memories), describeKnowledge(knowledge))
+
+		records := make([]domain.Draft, len(replies))
+		for i, r := range replies { records[i] = domain.Draft{Source: site.Name(), Kind: "comment", TargetID: p.ID, Title: p.Title, Content: r.Content, PromptVersion: r.PromptVersion, Critique: r.Critique} }
+		status, chosen := "rejected", a.choose(ctx, tgTitle, tgBody, cands)
+		if chosen >= 0 {
+			reply := replies[chosen]
+			status = "failed"
+			if err := site.CreateComment(ctx, p.ID, reply.Content); err == nil {
+				status = "approved"
+				a.rememberExchange(ctx, site.Name(), p.Author, p.Title, p.Content, reply.Content)
+				a.retireConflicts(ctx, conflicts, chosen)
+				a.Storage.MarkProactive(site.Name(), p.ID)
+				a.Storage.IncrementCommentCount(site.Name(), today)
+				count++
+				fmt.Println("    ✅ Approved and Sent.")
+				// 추천은 운영자가 켠 경우에만 남김 (승인은 댓글 내용에 대한 것이므로)
+				if voter, ok := site.(ports.Voter); ok && a.AutoUpvote {
+					if err := voter.Upvote(ctx, p.ID); err != nil { fmt.Printf("    ⚠️ Upvote failed: %v\n", err) }
+				}
+			}
+		} else {
+			a.Storage.MarkProactive(site.Name(), p.ID)
+			fmt.Println("    ⏩ Rejected and Marked as Done.")
+		}
+		a.recordChoice(ctx, records, chosen, status)
+	}
+	fmt.Printf("%d posts evaluated (%d reused).\n", evaluated, len(evals)-evaluated)
+}
+
+func (a *Agent) handleDailyPosting(ctx context.Context, site ports.Site, firstRun bool) {
+	if a.Brain == nil || a.UI == nil { fmt.Println("No brain or UI."); return }
+	today := time.Now().Format("2006-01-02")
+	count, lastDate, lastTs, _ := a.Storage.GetPostStats(site.Name())
+	if lastDate != today { count = 0 }
+
+	if count >= dailyPostLimit {
+		fmt.Printf("Daily limit reached (%d/%d).\n", count, dailyPostLimit)
+		return
+	}
+
+	elapsed := time.Since(time.Unix(lastTs, 0))
+	if lastTs != 0 && elapsed < 2*time.Hour {
+		fmt.Printf("Cooldown (%.0f mins left).\n", 120-elapsed.Minutes())
+		return
+	}
+
+	chance := rand.Float32()
+	if !firstRun && chance > 0.4 {
+		fmt.Printf("Probability skip (Roll: %.2f > 0.40).\n", chance)
+		return
+	}
+
+	topic, refs := a.planTopic(ctx)
+	memories := a.recall(ctx, site.Name(), topic+" "+refTitles(refs), "")
+	knowledge := a.knowledgeFor(ctx, topic+" "+refTitles(refs))
+	fmt.Printf("Generating post about '%s' (%d refs, %d memories)... ", topic, len(refs), len(memories))
+
+	drafts, err := a.Brain.GeneratePostCandidates(ctx, topic, domain.PromptContext{Refs: refs, Memories: memories, Knowledge: knowledge, Recent: a.recentOwn(ctx)}, a.candidateCount())
+	if err != nil { fmt.Printf("❌ AI Error: %v\n", err); return }
+	cands := postCandidates(drafts)
+	conflicts := a.checkCandidates(ctx, cands)
+
+	var sources []domain.Citation
+	records := make([]domain.Draft, len(drafts))
+	for i, d := range drafts {
+		records[i] = domain.Draft{Source: site.Name(), Kind: "post", Title: d.Title, Content: d.Content, Community: d.Community, PromptVersion: d.PromptVersion, Sources: d.Sources, Critique: d.Critique}
+		if i == 0 || a.canChoose(cands) { sources = mergeCitations(sources, d.Sources) }
+	}
+
+	tgTitle := fmt.Sprintf("🚀 [%s] 새 글 승인", site.Name())
+	tgBody := fmt.Sprintf("%s%s\n\n🏷️ 프롬프트: %s%s%s%s%s", a.describeCandidates(cands), describeContradictions(conflicts), drafts[0].PromptVersion, describeNotes(candidateNotes(cands)), describeSources(sources), describeMemories(memories), describeKnowledge(knowledge))
+
+	status, chosen := "rejected", a.choose(ctx, tgTitle, tgBody, cands)
+	if chosen >= 0 {
+		draft := drafts[chosen]
+		content := draft.Content
+		if a.CiteSources { content += sourcesFooter(draft.Sources) }
+		records[chosen].Community = pickBoard(ctx, site, draft.Community)
+		status = "failed"
+		if err := site.CreatePost(ctx, domain.Post{Title: draft.Title, Content: content, Community: records[chosen].Community, Source: site.Name()}); err == nil {
+			status = "approved"
+			a.Storage.IncrementPostCount(site.Name(), today, time.Now().Unix())
+			a.retireConflicts(ctx, conflicts, chosen)
+			fmt.Println("✅ Success.")
+		}
+	} else {
+		fmt.Println("⏩ Rejected.")
+	}
+	a.recordChoice(ctx, records, chosen, status)
+}
+
+// recordDraft는 생성 결과와 프롬프트 버전을 저장합니다. 실패해도 루틴은 계속합니다.
+// 게시된 글/댓글은 나중에 회상할 수 있도록 기억(insight)으로도 남기고, 자기 자신에 대한 진술을 페르소나 진술로 모읍니다.
+func (a *Agent) recordDraft(ctx context.Context, d domain.Draft) {
+	if err := a.Storage.SaveDraft(ctx, d); err != nil { fmt.Printf("    ⚠️  Draft record failed: %v\n", err) }
+	if d.Status == "approved" {
+		a.saveInsight(ctx, domain.Insight{PostID: d.TargetID, Source: d.Source, Kind: "own_" + d.Kind, Topic: d.Title, Content: d.Content})
+		a.learnPersonaFacts(ctx, d)
+	}
+}
+
+// describeNotes는 생성 중 차단/잘림 등으로 다시 생성한 이유를 승인 메시지용으로 정리합니다.
+func describeNotes(notes []string) string {
+	if len(notes) == 0 { return "" }
+	return "\n⚠️ 생성 메모: " + strings.Join(notes, "; ")
+}
+
+// describeSources는 글 생성에 쓰인 검색 출처를 운영자가 확인할 수 있게 나열합니다.
+func describeSources(sources []domain.Citation) string {
+	if len(sources) == 0 { return "" }
+	var sb strings.Builder
+	sb.WriteString("\n\n🔗 검색 출처:")
+	for i, c := range sources { fmt.Fprintf(&sb, "\n%d. %s - %s", i+1, c.Title, c.URI) }
+	return sb.String()
+}
+
+// sourcesFooter는 게시글 끝에 붙일 "참고" 목록입니다.
+func sourcesFooter(sources []domain.Citation) string {
+	if len(sources) == 0 { return "" }
+	var sb strings.Builder
+	sb.WriteString("\n\n참고:")
+	for _, c := range sources { fmt.Fprintf(&sb, "\n- %s: %s", c.Title, c.URI) }
+	return sb.String()
+}
+
+// pickBoard는 사이트가 게시판 목록을 제공하면 제안된 게시판이 실제로 있는지 확인합니다.
+// 없는 게시판이면 빈 문자열을 돌려 어댑터 기본값을 쓰게 합니다.
+func pickBoard(ctx context.Context, site ports.Site, suggested string) string {
+	lister, ok := site.(ports.BoardLister)
+	if !ok || suggested == "" { return suggested }
+	boards, err := lister.ListBoards(ctx)
+	if err != nil || len(boards) == 0 { return suggested }
+	for _, b := range boards {
+		if strings.EqualFold(b.Name, suggested) { return b.Name }
+	}
+	return ""
+}
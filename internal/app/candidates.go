@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const recentOwnLimit = 5 // 후보 비평에서 새로움 비교에 쓸 최근 우리 글 수
+
+// candidate는 승인 메시지에 보여 줄 후보 하나입니다 (글/답글 공통).
+type candidate struct {
+	Text     string
+	Critique *domain.Critique
+	Notes    []string
+}
+
+func replyCandidates(replies []domain.ReplyDraft) []candidate {
+	res := make([]candidate, len(replies))
+	for i, r := range replies { res[i] = candidate{Text: r.Content, Critique: r.Critique, Notes: r.Notes} }
+	return res
+}
+
+func postCandidates(drafts []domain.PostDraft) []candidate {
+	res := make([]candidate, len(drafts))
+	for i, d := range drafts { res[i] = candidate{Text: fmt.Sprintf("📌 제목: %s\n\n📝 내용:\n%s", d.Title, d.Content), Critique: d.Critique, Notes: d.Notes} }
+	return res
+}
+
+// candidateCount는 요청마다 만들 후보 수입니다.
+// 설정하지 않았거나 남은 LLM 요청이 예비분의 두 배보다 적으면 후보 하나만 만듭니다.
+func (a *Agent) candidateCount() int {
+	if a.Candidates < 1 { return 1 }
+	if left := a.remainingBudget(); left >= 0 && left < 2*lowBudgetReserve { return 1 }
+	return a.Candidates
+}
+
+// recentOwn은 최근에 게시한 우리 글/댓글입니다 (후보 비평의 새로움 비교용).
+func (a *Agent) recentOwn(ctx context.Context) []domain.Insight {
+	if a.candidateCount() < 2 { return nil }
+	recent, _ := a.Storage.GetRecentInsights(ctx, recallWindow)
+	var res []domain.Insight
+	for _, in := range recent {
+		if len(res) >= recentOwnLimit { break }
+		if strings.HasPrefix(in.Kind, "own_") { res = append(res, in) }
+	}
+	return res
+}
+
+// canChoose는 운영자가 승인 메시지에서 후보를 고를 수 있는지입니다.
+func (a *Agent) canChoose(cands []candidate) bool {
+	_, ok := a.UI.(ports.Chooser)
+	return ok && len(cands) > 1
+}
+
+// choose는 승인을 요청하고 고른 후보 번호를 돌려줍니다 (거절이나 오류면 -1).
+// 고를 수 없으면 가장 점수가 높은 첫 후보만 Confirm으로 묻습니다.
+func (a *Agent) choose(ctx context.Context, title, body string, cands []candidate) int {
+	if a.canChoose(cands) {
+		labels := make([]string, len(cands))
+		for i := range cands { labels[i] = fmt.Sprintf("%d번", i+1) }
+		idx, action, err := a.UI.(ports.Chooser).Choose(ctx, title, body, labels)
+		if err != nil || action != ports.ActionApprove || idx < 0 || idx >= len(cands) { return -1 }
+		return idx
+	}
+	action, err := a.UI.Confirm(ctx, title, body)
+	if err != nil || action != ports.ActionApprove { return -1 }
+	return 0
+}
+
+// describeCandidates는 승인 메시지의 후보 부분입니다. 고를 수 없거나 후보가 하나면 첫 후보만 그대로 보여 줍니다.
+func (a *Agent) describeCandidates(cands []candidate) string {
+	if !a.canChoose(cands) { return cands[0].Text }
+	var sb strings.Builder
+	for i, c := range cands {
+		fmt.Fprintf(&sb, "\n\n[%d번]", i+1)
+		if c.Critique != nil {
+			fmt.Fprintf(&sb, " %.1f점 (관련 %d, 페르소나 %d, 새로움 %d, 규칙 %d) - %s", c.Critique.Score, c.Critique.Relevance, c.Critique.PersonaFit, c.Critique.Novelty, c.Critique.Compliance, c.Critique.Reason)
+		}
+		fmt.Fprintf(&sb, "\n%s", c.Text)
+	}
+	return sb.String()
+}
+
+// mergeCitations는 후보들의 검색 출처를 URI 기준으로 합칩니다.
+func mergeCitations(list, more []domain.Citation) []domain.Citation {
+	for _, c := range more {
+		dup := false
+		for _, x := range list {
+			if x.URI == c.URI { dup = true; break }
+		}
+		if !dup { list = append(list, c) }
+	}
+	return list
+}
+
+func candidateNotes(cands []candidate) []string {
+	var notes []string
+	for _, c := range cands { notes = appendUnique(notes, len(notes)+len(c.Notes), c.Notes...) }
+	return notes
+}
+
+// recordChoice는 후보마다 기록을 남깁니다. 선택된 후보는 status, 나머지는 "lost"이며 아무것도 고르지 않았으면 모두 status입니다.
+// 후보가 여럿이면 같은 Group과 비평 순위(Rank)를 붙여 나중에 비교할 수 있게 합니다.
+func (a *Agent) recordChoice(ctx context.Context, records []domain.Draft, chosen int, status string) {
+	group := ""
+	if len(records) > 1 { group = fmt.Sprintf("%s-%s-%d", records[0].Source, records[0].Kind, time.Now().UnixNano()) }
+	for i, d := range records {
+		d.Status = status
+		if chosen >= 0 && i != chosen { d.Status = "lost" }
+		if group != "" { d.Group, d.Rank = group, i+1 }
+		a.recordDraft(ctx, d)
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"d3k-agent/internal/core/domain"
+	"encoding/hex"
+	"fmt"
+)
+
+// reevaluateComments는 이전 평가 이후 댓글이 이만큼 늘면 같은 글이라도 다시 평가할 기준입니다.
+const reevaluateComments = 5
+
+// evaluatePosts는 저장된 평가를 재사용하고, 처음 보는 글이나 내용이 바뀐 글, 댓글이 크게 늘어난 글만 한 번에 평가해 저장합니다.
+// 글 ID별 평가와 새로 평가한 글 수를 돌려줍니다.
+func (a *Agent) evaluatePosts(ctx context.Context, source string, posts []domain.Post) (map[string]domain.Evaluation, int) {
+	evals := make(map[string]domain.Evaluation)
+	var pending []domain.Post
+	for _, p := range posts {
+		prev, err := a.Storage.GetPostEvaluation(ctx, source, p.ID)
+		if err != nil { fmt.Printf("\n    ⚠️  Evaluation load failed (%s): %v", p.ID, err) } // 다시 평가함
+		if prev != nil && prev.ContentHash == postHash(p) && p.CommentCount-prev.CommentCount < reevaluateComments {
+			evals[p.ID] = prev.Evaluation
+			continue
+		}
+		pending = append(pending, p)
+	}
+	if len(pending) == 0 { return evals, 0 }
+
+	results, err := a.Brain.EvaluatePosts(ctx, pending)
+	if err != nil { fmt.Printf("\n    ⚠️  Evaluate failed (%d posts): %v", len(pending), err); return evals, 0 }
+	for i, p := range pending {
+		evals[p.ID] = results[i]
+		record := domain.PostEvaluation{Source: source, PostID: p.ID, Evaluation: results[i], ContentHash: postHash(p), CommentCount: p.CommentCount}
+		if err := a.Storage.SavePostEvaluation(ctx, record); err != nil { fmt.Printf("\n    ⚠️  Evaluation save failed (%s): %v", p.ID, err) }
+	}
+	return evals, len(pending)
+}
+
+// postHash는 글 제목과 내용의 해시입니다. 글이 수정되면 바뀝니다.
+func postHash(p domain.Post) string {
+	sum := sha256.Sum256([]byte(p.Title + "\x00" + p.Content))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	consolidateEvery    = 6 * time.Hour        // 지식 정리 주기
+	consolidateMin      = 8                    // 정리하지 않은 insight가 이만큼 쌓여야 정리
+	consolidateClusters = 3                    // 한 번에 정리할 최대 묶음 수 (LLM 호출 수)
+	clusterMinSize      = 2                    // 지식으로 정리할 최소 insight 수 (하나뿐인 기억은 다음에 다시 봄)
+	clusterSimilarity   = 0.3                  // 같은 묶음으로 볼 단어 겹침
+	knowledgeTTL        = 30 * 24 * time.Hour  // 이 기간 동안 새 근거가 없으면 폐기
+	knowledgeWindow     = 100                  // 회상 후보로 읽을 지식 수
+	knowledgeLimit      = 2                    // 프롬프트에 넣을 지식 수
+	knowledgeCursor     = "knowledge:last_run" // 마지막 정리 시각을 저장하는 커서 키
+	reviewedCursor      = "knowledge:reviewed" // 정리했지만 지식이 나오지 않은 insight ID(JSON 배열)를 저장하는 커서 키
+)
+
+// ConsolidateKnowledge는 주기적으로 아직 지식에 반영되지 않은 최근 insight를 주제별로 묶어 Brain에 정리를 맡기고,
+// 결과를 근거(insight ID)와 함께 지식 베이스에 저장합니다. 오래 갱신되지 않은 지식은 폐기합니다.
+// 정리에 성공한 묶음은 지식이 나오지 않았어도 검토한 것으로 기록해 다시 보내지 않고,
+// 정리 호출이 하나도 성공하지 않았으면 주기 커서를 옮기지 않아 다음 사이클에 다시 시도합니다.
+func (a *Agent) ConsolidateKnowledge(ctx context.Context) {
+	if a.Brain == nil { return }
+	if last, _ := a.Storage.LoadCursor(knowledgeCursor); last != "" {
+		if ts, err := strconv.ParseInt(last, 10, 64); err == nil && time.Since(time.Unix(ts, 0)) < consolidateEvery { return }
+	}
+	if left := a.remainingBudget(); left >= 0 && left < lowBudgetReserve { return }
+
+	fmt.Print("[knowledge] Consolidation: ")
+	removed, err := a.Storage.DeleteStaleKnowledge(ctx, time.Now().Add(-knowledgeTTL))
+	if err != nil { fmt.Printf("⚠️  aging failed: %v; ", err) }
+
+	existing, _ := a.Storage.GetKnowledge(ctx, knowledgeWindow)
+	used := make(map[int64]bool)
+	for _, k := range existing {
+		for _, id := range k.InsightIDs { used[id] = true }
+	}
+	var reviewed []int64
+	if raw, _ := a.Storage.LoadCursor(reviewedCursor); raw != "" { json.Unmarshal([]byte(raw), &reviewed) }
+	for _, id := range reviewed { used[id] = true }
+	recent, _ := a.Storage.GetRecentInsights(ctx, recallWindow)
+	var pending []domain.Insight
+	for _, in := range recent {
+		if in.Kind == "" && !used[in.ID] { pending = append(pending, in) } // 우리가 쓴 글(own_*)은 지식의 근거로 쓰지 않음
+	}
+	if len(pending) < consolidateMin {
+		fmt.Printf("%d pending insights, %d stale removed.\n", len(pending), removed)
+		return
+	}
+
+	distilled, empty := 0, 0
+	for _, cluster := range clusterInsights(pending) {
+		if distilled >= consolidateClusters { break }
+		related := relatedKnowledge(existing, clusterText(cluster))
+		entries, err := a.Brain.DistillKnowledge(ctx, cluster, related)
+		if err != nil { fmt.Printf("⚠️  distill failed: %v; ", err); continue }
+		for _, k := range entries {
+			if err := a.Storage.SaveKnowledge(ctx, mergeKnowledge(existing, k)); err != nil { fmt.Printf("⚠️  save failed: %v; ", err) }
+		}
+		for _, in := range cluster { reviewed = append(reviewed, in.ID) }
+		if len(entries) == 0 { empty++ }
+		distilled++
+	}
+	if distilled == 0 {
+		fmt.Printf("%d pending insights, no cluster distilled, %d stale removed.\n", len(pending), removed)
+		return
+	}
+	a.saveReviewed(reviewed, recent)
+	a.Storage.SaveCursor(knowledgeCursor, strconv.FormatInt(time.Now().Unix(), 10))
+	fmt.Printf("%d pending insights, %d clusters distilled (%d without knowledge), %d stale removed.\n", len(pending), distilled, empty, removed)
+}
+
+// saveReviewed는 검토한 insight ID 중 아직 최근 목록(정리 후보)에 있는 것만 남겨 저장합니다.
+func (a *Agent) saveReviewed(reviewed []int64, recent []domain.Insight) {
+	live := make(map[int64]bool, len(recent))
+	for _, in := range recent { live[in.ID] = true }
+	var keep []int64
+	for _, id := range reviewed {
+		if live[id] && !containsID(keep, id) { keep = append(keep, id) }
+	}
+	encoded, err := json.Marshal(keep)
+	if err != nil { return }
+	if err := a.Storage.SaveCursor(reviewedCursor, string(encoded)); err != nil { fmt.Printf("⚠️  reviewed save failed: %v; ", err) }
+}
+
+// clusterInsights는 insight를 주제/내용 단어 겹침으로 묶습니다 (먼저 나온 묶음의 단어 집합과 비교하는 단순 탐욕 방식).
+// clusterMinSize보다 작은 묶음은 버리고, 큰 묶음부터 돌려줍니다.
+func clusterInsights(insights []domain.Insight) [][]domain.Insight {
+	type cluster struct {
+		members []domain.Insight
+		terms   map[string]bool
+	}
+	var clusters []*cluster
+	for _, in := range insights {
+		t := terms(insightText(in))
+		var best *cluster
+		bestScore := clusterSimilarity
+		for _, c := range clusters {
+			if s := overlap(t, c.terms); s >= bestScore { best, bestScore = c, s }
+		}
+		if best == nil {
+			clusters = append(clusters, &cluster{members: []domain.Insight{in}, terms: t})
+			continue
+		}
+		best.members = append(best.members, in)
+		for w := range t { best.terms[w] = true }
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return len(clusters[i].members) > len(clusters[j].members) })
+	var res [][]domain.Insight
+	for _, c := range clusters {
+		if len(c.members) >= clusterMinSize { res = append(res, c.members) }
+	}
+	return res
+}
+
+func clusterText(cluster []domain.Insight) string {
+	var parts []string
+	for _, in := range cluster { parts = append(parts, insightText(in)) }
+	return strings.Join(parts, " ")
+}
+
+// relatedKnowledge는 query와 단어가 겹치는 지식을 관련도 순으로 knowledgeLimit+1개까지 돌려줍니다.
+func relatedKnowledge(all []domain.Knowledge, query string) []domain.Knowledge {
+	q := terms(query)
+	type scored struct {
+		k     domain.Knowledge
+		score float64
+	}
+	var ranked []scored
+	for _, k := range all {
+		if s := overlap(q, terms(k.Topic+" "+k.Content)) + overlap(q, terms(k.Topic)); s > 0 { ranked = append(ranked, scored{k, s}) }
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	var res []domain.Knowledge
+	for _, r := range ranked {
+		if len(res) > knowledgeLimit { break }
+		res = append(res, r.k)
+	}
+	return res
+}
+
+// mergeKnowledge는 기존 지식을 갱신하는 결과면 이전 근거와 소스를 합칩니다.
+func mergeKnowledge(existing []domain.Knowledge, k domain.Knowledge) domain.Knowledge {
+	k.UpdatedAt = time.Now()
+	if k.ID == 0 { return k }
+	for _, old := range existing {
+		if old.ID != k.ID { continue }
+		for _, id := range old.InsightIDs {
+			if !containsID(k.InsightIDs, id) { k.InsightIDs = append(k.InsightIDs, id) }
+		}
+		for _, src := range old.Sources { k.Sources = appendUnique(k.Sources, len(old.Sources)+len(k.Sources), src) }
+		k.CreatedAt = old.CreatedAt
+	}
+	return k
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, x := range ids {
+		if x == id { return true }
+	}
+	return false
+}
+
+// knowledgeFor는 생성 프롬프트에 넣을, query와 관련된 지식입니다.
+func (a *Agent) knowledgeFor(ctx context.Context, query string) []domain.Knowledge {
+	all, err := a.Storage.GetKnowledge(ctx, knowledgeWindow)
+	if err != nil || len(all) == 0 { return nil }
+	related := relatedKnowledge(all, query)
+	if len(related) > knowledgeLimit { related = related[:knowledgeLimit] }
+	return related
+}
+
+// describeKnowledge는 승인 메시지에 붙일 사용된 지식 목록입니다.
+func describeKnowledge(knowledge []domain.Knowledge) string {
+	if len(knowledge) == 0 { return "" }
+	var sb strings.Builder
+	sb.WriteString("\n📚 참고한 지식:")
+	for _, k := range knowledge {
+		fmt.Fprintf(&sb, "\n- [%s] %s: %s (근거 %d개)", k.Kind, k.Topic, k.Content, len(k.InsightIDs))
+	}
+	return sb.String()
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ids(insights []domain.Insight) []int64 {
+	var res []int64
+	for _, in := range insights { res = append(res, in.ID) }
+	return res
+}
+
+func TestClusterInsights(t *testing.T) {
+	in := func(id int64, topic, content string) domain.Insight {
+		return domain.Insight{ID: id, Topic: topic, Content: content}
+	}
+	tests := []struct {
+		name     string
+		insights []domain.Insight
+		want     [][]int64
+	}{
+		{"empty", nil, nil},
+		{"singletons dropped", []domain.Insight{in(1, "금리", "기준금리 동결"), in(2, "Rust", "borrow checker")}, nil},
+		{"groups by overlap", []domain.Insight{
+			in(1, "금리", "기준금리 동결 발표"),
+			in(2, "Go", "generics release"),
+			in(3, "금리", "기준금리 인하 기대"),
+			in(4, "Go", "generics performance"),
+		}, [][]int64{{1, 3}, {2, 4}}},
+		{"largest cluster first", []domain.Insight{
+			in(1, "Go", "generics release"),
+			in(2, "금리", "기준금리 동결"),
+			in(3, "금리", "기준금리 인하"),
+			in(4, "금리", "기준금리 전망"),
+			in(5, "Go", "generics tips"),
+		}, [][]int64{{2, 3, 4}, {1, 5}}},
+		{"cluster terms grow", []domain.Insight{
+			in(1, "환율", "원달러 환율 상승"),
+			in(2, "환율", "환율 상승 수출"),
+			in(3, "수출", "수출 호조"), // 1과는 겹치지 않지만 2가 더한 단어로 묶임
+		}, [][]int64{{1, 2, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int64
+			for _, c := range clusterInsights(tt.insights) { got = append(got, ids(c)) }
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("clusters = %v, want %v", got, tt.want) }
+		})
+	}
+}
+
+func TestRelatedKnowledge(t *testing.T) {
+	all := []domain.Knowledge{
+		{ID: 1, Topic: "기준금리", Content: "한국은행은 기준금리를 동결하는 추세"},
+		{ID: 2, Topic: "Go", Content: "generics adoption grows"},
+		{ID: 3, Topic: "환율", Content: "금리 차이로 환율 상승"},
+		{ID: 4, Topic: "부동산", Content: "금리 인하 기대에 거래 증가"},
+		{ID: 5, Topic: "금리 전망", Content: "금리 인하 시점 논쟁"},
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []int64
+	}{
+		{"no match", "러스트 borrow checker", nil},
+		{"single match", "Go generics", []int64{2}},
+		{"ranked and capped at limit+1", "기준금리 전망", []int64{1, 5, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, k := range relatedKnowledge(all, tt.query) { got = append(got, k.ID) }
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("relatedKnowledge(%q) = %v, want %v", tt.query, got, tt.want) }
+		})
+	}
+}
+
+func TestMergeKnowledge(t *testing.T) {
+	created := time.Now().Add(-48 * time.Hour)
+	existing := []domain.Knowledge{{ID: 7, Topic: "금리", Sources: []string{"moltbook", "botmadang"}, InsightIDs: []int64{1, 2}, CreatedAt: created}}
+	tests := []struct {
+		name        string
+		k           domain.Knowledge
+		wantIDs     []int64
+		wantSources []string
+		wantCreated time.Time
+	}{
+		{"new entry untouched", domain.Knowledge{Topic: "Go", Sources: []string{"rss"}, InsightIDs: []int64{9}}, []int64{9}, []string{"rss"}, time.Time{}},
+		{"update merges provenance", domain.Knowledge{ID: 7, Sources: []string{"botmadang", "rss"}, InsightIDs: []int64{2, 3}}, []int64{1, 2, 3}, []string{"botmadang", "moltbook", "rss"}, created},
+		{"unknown id kept as is", domain.Knowledge{ID: 99, InsightIDs: []int64{4}}, []int64{4}, nil, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeKnowledge(existing, tt.k)
+			sort.Slice(got.InsightIDs, func(i, j int) bool { return got.InsightIDs[i] < got.InsightIDs[j] })
+			sort.Strings(got.Sources)
+			if !reflect.DeepEqual(got.InsightIDs, tt.wantIDs) { t.Errorf("insight ids = %v, want %v", got.InsightIDs, tt.wantIDs) }
+			if !reflect.DeepEqual(got.Sources, tt.wantSources) { t.Errorf("sources = %v, want %v", got.Sources, tt.wantSources) }
+			if !got.CreatedAt.Equal(tt.wantCreated) { t.Errorf("created = %v, want %v", got.CreatedAt, tt.wantCreated) }
+			if time.Since(got.UpdatedAt) > time.Minute { t.Error("UpdatedAt must be refreshed") }
+		})
+	}
+	if len(existing[0].InsightIDs) != 2 { t.Error("mergeKnowledge modified the existing entry") }
+}
+
+type knowledgeStore struct {
+	ports.Storage
+	cursors   map[string]string
+	insights  []domain.Insight
+	knowledge []domain.Knowledge
+}
+
+func (s *knowledgeStore) LoadCursor(source string) (string, error) { return s.cursors[source], nil }
+
+func (s *knowledgeStore) SaveCursor(source, cursor string) error {
+	s.cursors[source] = cursor
+	return nil
+}
+
+func (s *knowledgeStore) DeleteStaleKnowledge(ctx context.Context, before time.Time) (int, error) { return 0, nil }
+
+func (s *knowledgeStore) GetKnowledge(ctx context.Context, limit int) ([]domain.Knowledge, error) { return s.knowledge, nil }
+
+func (s *knowledgeStore) GetRecentInsights(ctx context.Context, limit int) ([]domain.Insight, error) { return s.insights, nil }
+
+func (s *knowledgeStore) SaveKnowledge(ctx context.Context, k domain.Knowledge) error {
+	k.ID = int64(len(s.knowledge) + 1)
+	s.knowledge = append(s.knowledge, k)
+	return nil
+}
+
+// distillBrain은 "금리" 묶음만 지식으로 정리하고 나머지 묶음에서는 아무것도 찾지 못합니다. fail이면 모든 호출이 실패합니다.
+type distillBrain struct {
+	ports.Brain
+	fail  bool
+	calls int
+}
+
+func (b *distillBrain) DistillKnowledge(ctx context.Context, insights []domain.Insight, existing []domain.Knowledge) ([]domain.Knowledge, error) {
+	b.calls++
+	if b.fail { return nil, errors.New("quota exhausted") }
+	if !strings.Contains(insights[0].Topic, "금리") { return nil, nil }
+	return []domain.Knowledge{{Kind: "trend", Topic: "금리", Content: "동결 기조", InsightIDs: ids(insights)}}, nil
+}
+
+func TestConsolidateKnowledge(t *testing.T) {
+	var insights []domain.Insight
+	for i := 1; i <= 4; i++ {
+		insights = append(insights,
+			domain.Insight{ID: int64(i), Topic: "기준금리", Content: "기준금리 동결 전망"},
+			domain.Insight{ID: int64(10 + i), Topic: "Go generics", Content: "generics release notes"})
+	}
+	insights = append(insights, domain.Insight{ID: 99, Kind: "own_post", Topic: "기준금리", Content: "우리가 쓴 글"})
+	ctx := context.Background()
+
+	t.Run("empty clusters are marked reviewed", func(t *testing.T) {
+		store := &knowledgeStore{cursors: map[string]string{}, insights: insights}
+		b := &distillBrain{}
+		a := &Agent{Brain: b, Storage: store}
+		a.ConsolidateKnowledge(ctx)
+		if b.calls != 2 { t.Fatalf("distill calls = %d, want 2", b.calls) }
+		if len(store.knowledge) != 1 { t.Errorf("saved %d knowledge entries, want 1", len(store.knowledge)) }
+		if store.cursors[knowledgeCursor] == "" { t.Error("cursor must advance after a successful distill") }
+		if got, want := store.cursors[reviewedCursor], "[1,2,3,4,11,12,13,14]"; got != want { t.Errorf("reviewed = %s, want %s", got, want) }
+
+		store.cursors[knowledgeCursor] = "" // 주기와 상관없이 다시 실행
+		a.ConsolidateKnowledge(ctx)
+		if b.calls != 2 { t.Errorf("distill calls after rerun = %d, want no new calls for reviewed insights", b.calls) }
+	})
+
+	t.Run("failed run keeps cursor", func(t *testing.T) {
+		store := &knowledgeStore{cursors: map[string]string{}, insights: insights}
+		b := &distillBrain{fail: true}
+		a := &Agent{Brain: b, Storage: store}
+		a.ConsolidateKnowledge(ctx)
+		if store.cursors[knowledgeCursor] != "" { t.Error("cursor advanced although every distill call failed") }
+		if store.cursors[reviewedCursor] != "" { t.Errorf("reviewed = %s, want nothing recorded", store.cursors[reviewedCursor]) }
+
+		b.fail = false
+		a.ConsolidateKnowledge(ctx)
+		if b.calls != 4 || len(store.knowledge) != 1 { t.Errorf("retry: calls = %d, knowledge = %d; want 4 calls and 1 entry", b.calls, len(store.knowledge)) }
+	})
+
+	t.Run("reviewed ids outside the window are dropped", func(t *testing.T) {
+		store := &knowledgeStore{cursors: map[string]string{reviewedCursor: "[500,501]"}, insights: insights}
+		a := &Agent{Brain: &distillBrain{}, Storage: store}
+		a.ConsolidateKnowledge(ctx)
+		if strings.Contains(store.cursors[reviewedCursor], "500") { t.Errorf("reviewed = %s, want stale ids pruned", store.cursors[reviewedCursor]) }
+	})
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	recallWindow   = 200                // 회상 후보로 읽을 최근 insight 수
+	recallLimit    = 3                  // 프롬프트에 넣을 기억 수
+	recallHalfLife = 7 * 24 * time.Hour // 최근성 가중치가 절반이 되는 기간
+	minSimilarity  = 0.6                // 의미 검색 결과를 관련 있다고 볼 최소 코사인 유사도
+	embedBatch     = 16                 // 사이클당 임베딩을 채울 insight 수
+)
+
+// recall은 query와 관련된 과거 insight를 골라 돌려줍니다.
+// 관련도는 주제/내용 단어 겹침이며, 임베더가 있으면 SearchInsights의 코사인 유사도(minSimilarity 이상)를 더합니다.
+// 여기에 같은 사이트 가중치와 최근성 가중치를 더해 순위를 매기고,
+// 관련도가 0인 기억과 excludePostID(지금 답하는 글)에서 나온 기억은 제외합니다.
+func (a *Agent) recall(ctx context.Context, source, query, excludePostID string) []domain.Insight {
+	candidates, _ := a.Storage.GetRecentInsights(ctx, recallWindow)
+	similarity := make(map[int64]float64)
+	if a.Embedder != nil {
+		if vecs, err := a.Embedder.Embed(ctx, []string{query}); err == nil && len(vecs) == 1 {
+			hits, err := a.Storage.SearchInsights(ctx, vecs[0], recallLimit*4)
+			if err != nil { fmt.Printf("\n    ⚠️  Memory search failed: %v", err) }
+			// 최근 목록 밖의 오래된 기억도 후보에 넣음 (중복은 아래에서 내용으로 걸러짐)
+			for _, h := range hits {
+				if h.Similarity < minSimilarity { continue }
+				similarity[h.ID] = h.Similarity
+				candidates = append(candidates, h)
+			}
+		} else if err != nil {
+			fmt.Printf("\n    ⚠️  Query embedding failed: %v", err)
+		}
+	}
+	if len(candidates) == 0 { return nil }
+	queryTerms := terms(query)
+
+	type scored struct {
+		insight domain.Insight
+		score   float64
+	}
+	var ranked []scored
+	seen := make(map[string]bool)
+	for _, in := range candidates {
+		if excludePostID != "" && in.PostID == excludePostID { continue }
+		if seen[in.Content] { continue } // 같은 글을 여러 번 요약한 중복 기억
+		seen[in.Content] = true
+		in.Similarity = similarity[in.ID]
+		relevance := overlap(queryTerms, terms(in.Topic+" "+in.Content)) + overlap(queryTerms, terms(in.Topic)) + in.Similarity
+		if relevance == 0 { continue }
+		score := relevance
+		if in.Source == source { score += 0.2 }
+		if !in.CreatedAt.IsZero() { score += 0.3 * math.Pow(0.5, float64(time.Since(in.CreatedAt))/float64(recallHalfLife)) }
+		ranked = append(ranked, scored{in, score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	var res []domain.Insight
+	for _, r := range ranked {
+		if len(res) >= recallLimit { break }
+		res = append(res, r.insight)
+	}
+	return res
+}
+
+// saveInsight는 임베더가 있으면 벡터를 붙여 insight를 저장합니다. 임베딩에 실패하면 벡터 없이 저장하고 embedBacklog가 나중에 채웁니다.
+// 원문 해시가 없으면(우리가 쓴 글 등) 내용 자체의 해시를 써서 같은 내용이 두 번 쌓이지 않게 합니다.
+func (a *Agent) saveInsight(ctx context.Context, in domain.Insight) {
+	if in.ContentHash == "" { in.ContentHash = postHash(domain.Post{Title: in.Topic, Content: in.Content}) }
+	if a.Embedder != nil {
+		if vecs, err := a.Embedder.Embed(ctx, []string{insightText(in)}); err == nil && len(vecs) == 1 { in.Embedding = vecs[0] }
+	}
+	if err := a.Storage.SaveInsight(ctx, in); err != nil { fmt.Printf("\n    ⚠️  Insight save failed: %v", err) }
+}
+
+// embedBacklog는 벡터 없이 저장된 insight(임베더 도입 전 기록, 임베딩 실패분)를 조금씩 채우고 채운 개수를 돌려줍니다.
+func (a *Agent) embedBacklog(ctx context.Context) int {
+	if a.Embedder == nil { return 0 }
+	pending, err := a.Storage.GetUnembeddedInsights(ctx, embedBatch)
+	if err != nil || len(pending) == 0 { return 0 }
+	texts := make([]string, len(pending))
+	for i, in := range pending { texts[i] = insightText(in) }
+	vecs, err := a.Embedder.Embed(ctx, texts)
+	if err != nil || len(vecs) != len(pending) { return 0 }
+	done := 0
+	for i, in := range pending {
+		if err := a.Storage.SaveInsightEmbedding(ctx, in.ID, vecs[i]); err == nil { done++ }
+	}
+	return done
+}
+
+func insightText(in domain.Insight) string { return in.Topic + "\n" + in.Content }
+
+// terms는 텍스트를 비교용 단어 집합으로 만듭니다.
+// 한글은 조사가 붙어 단어가 잘 맞지 않으므로 글자 2-gram으로 나눕니다.
+func terms(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		if unicode.Is(unicode.Hangul, runes[0]) {
+			for i := 0; i+1 < len(runes); i++ { set[string(runes[i:i+2])] = true }
+			continue
+		}
+		if len(runes) >= 2 { set[word] = true }
+	}
+	return set
+}
+
+// overlap은 두 단어 집합의 코사인 유사도(0~1)입니다.
+func overlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 { return 0 }
+	common := 0
+	for t := range a {
+		if b[t] { common++ }
+	}
+	return float64(common) / math.Sqrt(float64(len(a)*len(b)))
+}
+
+// describeMemories는 승인 메시지에 붙일 사용된 기억 목록입니다.
+func describeMemories(memories []domain.Insight) string {
+	if len(memories) == 0 { return "" }
+	var sb strings.Builder
+	sb.WriteString("\n🧠 참고한 기억:")
+	for _, m := range memories {
+		label := m.Source
+		if m.Kind != "" { label += "/" + m.Kind }
+		fmt.Fprintf(&sb, "\n- [%s] %s: %s", label, m.Topic, m.Content)
+		if m.Similarity > 0 { fmt.Fprintf(&sb, " (유사도 %.2f)", m.Similarity) }
+	}
+	return sb.String()
+}
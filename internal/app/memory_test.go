@@ -0,0 +1,60 @@
+package app
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"hangul bigrams", "금리인상", []string{"금리", "리인", "인상"}},
+		{"particles still overlap", "금리가", []string{"금리", "리가"}},
+		{"single hangul rune dropped", "나 금리", []string{"금리"}},
+		{"latin lowercased", "Go and AI", []string{"go", "and", "ai"}},
+		{"short latin dropped", "a b go", []string{"go"}},
+		{"punctuation splits", "rate-limit, 2026년!", []string{"rate", "limit", "2026년"}},
+		{"duplicates collapse", "금리 금리", []string{"금리"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for w := range terms(tt.text) { got = append(got, w) }
+			sort.Strings(got)
+			want := append([]string(nil), tt.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) { t.Errorf("terms(%q) = %v, want %v", tt.text, got, want) }
+		})
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	set := func(ws ...string) map[string]bool {
+		m := make(map[string]bool)
+		for _, w := range ws { m[w] = true }
+		return m
+	}
+	tests := []struct {
+		name string
+		a, b map[string]bool
+		want float64
+	}{
+		{"identical", set("금리", "환율"), set("금리", "환율"), 1},
+		{"disjoint", set("금리"), set("환율"), 0},
+		{"empty side", set(), set("금리"), 0},
+		{"half", set("금리", "환율"), set("금리", "주식"), 0.5},
+		{"cosine not jaccard", set("금리"), set("금리", "환율", "주식", "채권"), 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlap(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 { t.Errorf("overlap = %v, want %v", got, tt.want) }
+			if got, rev := overlap(tt.a, tt.b), overlap(tt.b, tt.a); got != rev { t.Errorf("overlap not symmetric: %v vs %v", got, rev) }
+		})
+	}
+}
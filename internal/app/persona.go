@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	personaWindow     = 200 // 모순 확인 후보로 읽을 최근 자기 진술 수
+	personaCheckLimit = 12  // 한 초안과 비교할 진술 수 (많으면 초안과 단어가 겹치는 것부터)
+	personaOriginLen  = 120 // 진술의 출처로 남길 원문 길이
+)
+
+// learnPersonaFacts는 게시된 글/댓글에서 d3k가 자기 자신에 대해 한 진술을 뽑아 저장합니다.
+func (a *Agent) learnPersonaFacts(ctx context.Context, d domain.Draft) {
+	if a.Brain == nil { return }
+	facts, err := a.Brain.ExtractPersonaFacts(ctx, d.Content)
+	if err != nil { fmt.Printf("    ⚠️  Persona fact extraction failed: %v\n", err); return }
+	for _, f := range facts {
+		if err := a.Storage.SavePersonaFact(ctx, domain.PersonaFact{Statement: f, Source: d.Source, Origin: truncate(d.Content, personaOriginLen)}); err != nil {
+			fmt.Printf("    ⚠️  Persona fact save failed: %v\n", err)
+		}
+	}
+}
+
+// checkPersona는 초안이 전에 한 자기 진술과 모순되는지 확인합니다. 진술이 없거나 확인에 실패하면 nil입니다.
+func (a *Agent) checkPersona(ctx context.Context, content string) []domain.Contradiction {
+	facts, err := a.Storage.GetPersonaFacts(ctx, personaWindow)
+	if err != nil || len(facts) == 0 { return nil }
+	if len(facts) > personaCheckLimit {
+		query := terms(content)
+		sort.SliceStable(facts, func(i, j int) bool { return overlap(query, terms(facts[i].Statement)) > overlap(query, terms(facts[j].Statement)) })
+		facts = facts[:personaCheckLimit]
+	}
+	conflicts, err := a.Brain.CheckConsistency(ctx, content, facts)
+	if err != nil { fmt.Printf("    ⚠️  Persona check failed: %v\n", err); return nil }
+	if len(conflicts) > 0 { fmt.Printf("    🪞 %d persona conflict(s) found.\n", len(conflicts)) }
+	return conflicts
+}
+
+// checkCandidates는 승인 메시지에 보여 줄 후보마다 따로 모순을 확인합니다.
+// 운영자가 후보를 고를 수 있으면 모든 후보를, 아니면 보여 주는 첫 후보만 확인하고, 여러 후보면 어긋난 후보 번호를 붙입니다.
+func (a *Agent) checkCandidates(ctx context.Context, cands []candidate) []domain.Contradiction {
+	shown := cands[:1]
+	if a.canChoose(cands) { shown = cands }
+	var res []domain.Contradiction
+	for i, c := range shown {
+		for _, conflict := range a.checkPersona(ctx, c.Text) {
+			if len(shown) > 1 { conflict.Candidate = i + 1 }
+			res = append(res, conflict)
+		}
+	}
+	return res
+}
+
+// retireConflicts는 운영자가 모순 표시를 보고도 승인해 게시한 후보와 어긋난 예전 진술을 폐기합니다.
+// 게시된 글의 새 진술이 recordDraft에서 그 자리를 대신합니다.
+func (a *Agent) retireConflicts(ctx context.Context, conflicts []domain.Contradiction, chosen int) {
+	for _, c := range conflicts {
+		if c.Candidate != 0 && c.Candidate != chosen+1 { continue }
+		if err := a.Storage.RetirePersonaFact(ctx, c.Fact); err != nil { fmt.Printf("    ⚠️  Persona fact retire failed: %v\n", err); continue }
+		fmt.Printf("    🪞 Retired persona fact: %s\n", c.Fact)
+	}
+}
+
+// describeContradictions는 승인 메시지에 붙일, 전에 한 말과 어긋나는 진술 목록입니다.
+func describeContradictions(conflicts []domain.Contradiction) string {
+	if len(conflicts) == 0 { return "" }
+	var sb strings.Builder
+	sb.WriteString("\n\n🪞 전에 한 말과 어긋남:")
+	for _, c := range conflicts {
+		sb.WriteString("\n- ")
+		if c.Candidate > 0 { fmt.Fprintf(&sb, "[%d번] ", c.Candidate) }
+		fmt.Fprintf(&sb, "초안: %s\n  전에: %s", c.Claim, c.Fact)
+		if c.Reason != "" { fmt.Fprintf(&sb, " (%s)", c.Reason) }
+	}
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// personaBrain은 초안에 "새벽"이 있으면 "밤에 일한다"는 진술과 어긋난다고 답합니다.
+type personaBrain struct {
+	ports.Brain
+	checked []string
+}
+
+func (b *personaBrain) CheckConsistency(ctx context.Context, content string, facts []domain.PersonaFact) ([]domain.Contradiction, error) {
+	b.checked = append(b.checked, content)
+	if !strings.Contains(content, "새벽") { return nil, nil }
+	return []domain.Contradiction{{Claim: "새벽에 일한다", Fact: facts[0].Statement}}, nil
+}
+
+type personaStore struct {
+	ports.Storage
+	facts []domain.PersonaFact
+}
+
+func (s *personaStore) GetPersonaFacts(ctx context.Context, limit int) ([]domain.PersonaFact, error) {
+	return s.facts, nil
+}
+
+type confirmUI struct{ ports.Interaction }
+
+type chooserUI struct{ ports.Interaction }
+
+func (chooserUI) Choose(ctx context.Context, title, body string, labels []string) (int, ports.UserAction, error) {
+	return 0, ports.ActionApprove, nil
+}
+
+func TestCheckCandidates(t *testing.T) {
+	cands := []candidate{{Text: "점심에 커피를 마신다"}, {Text: "새벽에 로그를 정리한다"}, {Text: "주말에 쉰다"}}
+	tests := []struct {
+		name        string
+		ui          ports.Interaction
+		cands       []candidate
+		facts       []domain.PersonaFact
+		wantChecked int
+		want        []int // 어긋난 후보 번호
+	}{
+		{"each candidate checked separately", chooserUI{}, cands, []domain.PersonaFact{{Statement: "밤에 일한다"}}, 3, []int{2}},
+		{"only shown candidate without chooser", confirmUI{}, cands, []domain.PersonaFact{{Statement: "밤에 일한다"}}, 1, nil},
+		{"single candidate unnumbered", chooserUI{}, cands[1:2], []domain.PersonaFact{{Statement: "밤에 일한다"}}, 1, []int{0}},
+		{"no facts", chooserUI{}, cands, nil, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &personaBrain{}
+			a := &Agent{Brain: b, UI: tt.ui, Storage: &personaStore{facts: tt.facts}}
+			var got []int
+			for _, c := range a.checkCandidates(context.Background(), tt.cands) { got = append(got, c.Candidate) }
+			if len(b.checked) != tt.wantChecked { t.Errorf("checked %d drafts %q, want %d", len(b.checked), b.checked, tt.wantChecked) }
+			for _, text := range b.checked {
+				if strings.Contains(text, "\n\n") { t.Errorf("candidates must be checked one at a time, got %q", text) }
+			}
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("conflicting candidates = %v, want %v", got, tt.want) }
+		})
+	}
+}
+
+func TestDescribeContradictions(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts []domain.Contradiction
+		want      string
+	}{
+		{"none", nil, ""},
+		{"single candidate", []domain.Contradiction{{Claim: "새벽", Fact: "밤", Reason: "시간대"}}, "\n\n🪞 전에 한 말과 어긋남:\n- 초안: 새벽\n  전에: 밤 (시간대)"},
+		{"numbered candidate", []domain.Contradiction{{Claim: "새벽", Fact: "밤", Candidate: 2}}, "\n\n🪞 전에 한 말과 어긋남:\n- [2번] 초안: 새벽\n  전에: 밤"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeContradictions(tt.conflicts); got != tt.want { t.Errorf("describeContradictions = %q, want %q", got, tt.want) }
+		})
+	}
+}
+
+type retireStore struct {
+	ports.Storage
+	retired []string
+}
+
+func (s *retireStore) RetirePersonaFact(ctx context.Context, statement string) error {
+	s.retired = append(s.retired, statement)
+	return nil
+}
+
+func TestRetireConflicts(t *testing.T) {
+	conflicts := []domain.Contradiction{{Fact: "밤에 일한다", Candidate: 1}, {Fact: "커피를 안 마신다", Candidate: 2}, {Fact: "주말엔 코딩 안 한다", Candidate: 2}}
+	tests := []struct {
+		name      string
+		conflicts []domain.Contradiction
+		chosen    int
+		want      []string
+	}{
+		{"only the chosen candidate's facts", conflicts, 1, []string{"커피를 안 마신다", "주말엔 코딩 안 한다"}},
+		{"chosen candidate without conflicts", conflicts, 2, nil},
+		{"single shown candidate", []domain.Contradiction{{Fact: "밤에 일한다"}}, 0, []string{"밤에 일한다"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &retireStore{}
+			a := &Agent{Storage: store}
+			a.retireConflicts(context.Background(), tt.conflicts, tt.chosen)
+			if !reflect.DeepEqual(store.retired, tt.want) { t.Errorf("retired = %q, want %q", store.retired, tt.want) }
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	maxRelTopics   = 10  // 작성자별로 기억할 주제 수
+	maxRelFacts    = 10  // 작성자별로 기억할 사실 수
+	maxExchangeLen = 200 // 마지막 대화 요약에 남길 글자 수 (말하는 쪽마다)
+)
+
+// relationshipsFor는 대화 상대들에 대해 기억하는 관계를 돌려줍니다 (처음 보는 상대는 빠짐).
+func (a *Agent) relationshipsFor(ctx context.Context, source string, authors []string) []domain.Relationship {
+	var res []domain.Relationship
+	for _, author := range authors {
+		if author == "" { continue }
+		if r, err := a.Storage.GetRelationship(ctx, source, author); err == nil && r != nil { res = append(res, *r) }
+	}
+	return res
+}
+
+// rememberExchange는 우리가 답을 게시한 뒤 상대와의 관계 기억을 갱신합니다.
+// 주제/분위기/사실은 Brain.AnalyzeExchange로 뽑고, 분석에 실패해도 대화 횟수와 마지막 대화는 남깁니다.
+func (a *Agent) rememberExchange(ctx context.Context, source, author, topic, theirs, ours string) {
+	if author == "" { return }
+	r, err := a.Storage.GetRelationship(ctx, source, author)
+	// 읽기에 실패했는데 새 관계로 저장하면 쌓아 둔 기억을 덮어쓰게 됨
+	if err != nil { fmt.Printf("    ⚠️  Relationship load failed (%s): %v\n", author, err); return }
+	now := time.Now()
+	if r == nil { r = &domain.Relationship{Source: source, Author: author, FirstSeen: now} }
+	r.Interactions++
+	r.LastSeen = now
+	r.LastExchange = fmt.Sprintf("%s: %s / d3k: %s", author, truncate(theirs, maxExchangeLen), truncate(ours, maxExchangeLen))
+	if topic != "" { r.Topics = appendUnique(r.Topics, maxRelTopics, topic) }
+
+	if note, err := a.Brain.AnalyzeExchange(ctx, theirs, ours); err == nil {
+		r.Topics = appendUnique(r.Topics, maxRelTopics, note.Topics...)
+		r.Facts = appendUnique(r.Facts, maxRelFacts, note.Facts...)
+		r.Sentiment = note.Sentiment
+	} else {
+		fmt.Printf("    ⚠️  Relationship analysis failed (%s): %v\n", author, err)
+	}
+	if err := a.Storage.SaveRelationship(ctx, *r); err != nil { fmt.Printf("    ⚠️  Relationship save failed (%s): %v\n", author, err) }
+}
+
+// describeRelationships는 승인 메시지에 붙일 대화 상대 기억입니다.
+func describeRelationships(rels []domain.Relationship) string {
+	if len(rels) == 0 { return "" }
+	var sb strings.Builder
+	sb.WriteString("\n👥 아는 상대:")
+	for _, r := range rels {
+		fmt.Fprintf(&sb, "\n- %s (대화 %d회", r.Author, r.Interactions)
+		if r.Sentiment != "" { fmt.Fprintf(&sb, ", %s", r.Sentiment) }
+		sb.WriteString(")")
+		if len(r.Facts) > 0 { fmt.Fprintf(&sb, ": %s", strings.Join(r.Facts, "; ")) }
+	}
+	return sb.String()
+}
+
+// appendUnique는 없는 값만 뒤에 붙이고, limit을 넘으면 오래된 것부터 버립니다.
+// 이미 있는 값은 최근 것으로 보고 뒤로 옮깁니다.
+func appendUnique(list []string, limit int, values ...string) []string {
+	list = append([]string(nil), list...) // 저장소가 돌려준 슬라이스를 건드리지 않도록 복사
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" { continue }
+		for i, x := range list {
+			if strings.EqualFold(x, v) { list = append(list[:i], list[i+1:]...); break }
+		}
+		list = append(list, v)
+	}
+	if len(list) > limit { list = list[len(list)-limit:] }
+	return list
+}
+
+// linesBy는 "- 작성자: 내용" 형식의 알림 묶음에서 author가 쓴 내용만 모읍니다.
+func linesBy(lines []string, author string) string {
+	var res []string
+	for _, l := range lines {
+		if text, ok := strings.CutPrefix(l, "- "+author+": "); ok { res = append(res, text) }
+	}
+	return strings.Join(res, "\n")
+}
+
+func truncate(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= n { return string(r) }
+	return string(r[:n]) + "…"
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const (
+	maxFeedSummaries = 3              // 사이클당 insight로 요약할 새 피드 항목 수
+	maxPostRefs      = 5              // 글 작성 시 참고 자료로 넘길 항목 수
+	feedFreshness    = 72 * time.Hour // 글감으로 쓸 피드 항목의 최대 나이
+)
+
+var defaultTopics = []string{"금융 경제", "IT 기술", "일상 지혜", "커리어"}
+
+// RefreshSources는 읽기 전용 지식 소스를 읽어 새 항목을 저장하고, 일부를 요약해 insight로 남깁니다.
+func (a *Agent) RefreshSources(ctx context.Context) {
+	for _, src := range a.Sources {
+		fmt.Printf("[%s] Source Update: ", src.Name())
+		items, err := src.Fetch(ctx)
+		if err != nil { fmt.Printf("Error: %v\n", err); continue }
+
+		added, err := a.Storage.SaveFeedItems(ctx, items)
+		if err != nil { fmt.Printf("Save error: %v\n", err); continue }
+
+		learned := 0
+		for _, it := range added {
+			if a.Brain == nil || learned >= maxFeedSummaries { break }
+			post := feedPost(src.Name(), it)
+			text, err := a.Brain.SummarizeInsight(ctx, post)
+			if err != nil || text == "" { continue }
+			topic := it.Category
+			if topic == "" { topic = it.Title }
+			a.saveInsight(ctx, domain.Insight{PostID: it.ID, Source: src.Name(), Topic: topic, Content: text, ContentHash: postHash(post)})
+			learned++
+		}
+		fmt.Printf("%d fetched, %d new, %d learned.\n", len(items), len(added), learned)
+	}
+}
+
+// planTopic은 최근 피드 항목이 있으면 그 중 하나를 기준으로 주제와 참고 자료를 고르고,
+// 없으면 기본 주제 중 하나를 무작위로 고릅니다.
+func (a *Agent) planTopic(ctx context.Context) (string, []domain.FeedItem) {
+	recent, _ := a.Storage.GetRecentFeedItems(ctx, 50)
+	var fresh []domain.FeedItem
+	for _, it := range recent {
+		if time.Since(it.PublishedAt) <= feedFreshness { fresh = append(fresh, it) }
+	}
+	if len(fresh) == 0 { return defaultTopics[rand.Intn(len(defaultTopics))], nil }
+
+	anchor := fresh[rand.Intn(len(fresh))]
+	topic := anchor.Category
+	if topic == "" { topic = anchor.Title }
+
+	refs := []domain.FeedItem{anchor}
+	for _, it := range fresh {
+		if len(refs) >= maxPostRefs { break }
+		if it.ID == anchor.ID { continue }
+		sameGroup := anchor.Category != "" && it.Category == anchor.Category
+		if anchor.Category == "" { sameGroup = it.Feed == anchor.Feed }
+		if sameGroup { refs = append(refs, it) }
+	}
+	return topic, refs
+}
+
+func feedPost(source string, it domain.FeedItem) domain.Post {
+	return domain.Post{ID: it.ID, Source: source, Title: it.Title, Content: it.Title + "\n" + it.Summary, Author: it.FeedTitle, URL: it.Link, CreatedAt: it.PublishedAt}
+}
+
+// refTitles는 참고 자료 제목을 이어 붙여 기억 회상 질의로 씁니다.
+func refTitles(refs []domain.FeedItem) string {
+	var titles []string
+	for _, it := range refs { titles = append(titles, it.Title) }
+	return strings.Join(titles, " ")
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/ports"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// lowBudgetReserve는 남은 일일 LLM 요청이 이보다 적을 때 선제 댓글/글 작성을 건너뛰고 알림 답글용으로 남겨 둘 양입니다.
+const lowBudgetReserve = 30
+
+// remainingBudget은 Brain이 한도를 알려 주면 최근 24시간 기준 남은 요청 수를, 모르거나 무제한이면 -1을 돌려줍니다.
+func (a *Agent) remainingBudget() int {
+	reporter, ok := a.Brain.(ports.BudgetReporter)
+	if !ok { return -1 }
+	budget := reporter.Budget()
+	if len(budget.Models) == 0 { return -1 }
+	return budget.RemainingToday()
+}
+
+// Status는 운영자 /status 명령에 보낼 사이트별 활동량과 LLM 할당량·토큰 사용량 요약입니다.
+func (a *Agent) Status(ctx context.Context, sites []ports.Site) string {
+	today := time.Now().Format("2006-01-02")
+	var sb strings.Builder
+	sb.WriteString("📊 오늘 활동")
+	for _, site := range sites {
+		posts, postDate, _, _ := a.Storage.GetPostStats(site.Name())
+		if postDate != today { posts = 0 }
+		comments, commentDate, _ := a.Storage.GetCommentStats(site.Name())
+		if commentDate != today { comments = 0 }
+		fmt.Fprintf(&sb, "\n- %s: 글 %d/%d, 댓글 %d/%d", site.Name(), posts, dailyPostLimit, comments, dailyCommentLimit)
+	}
+
+	reporter, ok := a.Brain.(ports.BudgetReporter)
+	if !ok { return sb.String() }
+	budget := reporter.Budget()
+	sb.WriteString("\n\n🧠 LLM 사용량 (최근 1분 / 24시간)")
+	for _, m := range budget.Models {
+		fmt.Fprintf(&sb, "\n- %s/%s: %s, %s, %d tokens", m.Provider, m.Model, usage(m.MinuteUsed, m.MinuteLimit), usage(m.DayUsed, m.DayLimit), m.DayTokens)
+		if m.DayFailed > 0 { fmt.Fprintf(&sb, " (실패 %d)", m.DayFailed) }
+		if m.State != "" && m.State != "closed" { fmt.Fprintf(&sb, " 🔌 %s", m.State) }
+	}
+	if left := budget.RemainingToday(); left >= 0 {
+		fmt.Fprintf(&sb, "\n남은 요청: %d", left)
+		if left < lowBudgetReserve { sb.WriteString(" (부족: 선제 댓글/글 작성 중지)") }
+	}
+	writeTokens(&sb, "\n\n🔢 작업별 토큰", budget.TokensByTask)
+	writeTokens(&sb, "\n\n🌐 사이트별 토큰", budget.TokensBySite)
+	return sb.String()
+}
+
+func usage(used, limit int) string {
+	if limit == 0 { return fmt.Sprintf("%d", used) }
+	return fmt.Sprintf("%d/%d", used, limit)
+}
+
+func writeTokens(sb *strings.Builder, header string, tokens map[string]int) {
+	if len(tokens) == 0 { return }
+	var keys []string
+	for k := range tokens { keys = append(keys, k) }
+	sort.Strings(keys)
+	sb.WriteString(header)
+	for _, k := range keys { fmt.Fprintf(sb, "\n- %s: %d", k, tokens[k]) }
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"strings"
+	"testing"
+	"time"
+)
+
+type statsStore struct {
+	ports.Storage
+	posts, comments int
+}
+
+func (s statsStore) GetPostStats(source string) (int, string, int64, error) {
+	return s.posts, time.Now().Format("2006-01-02"), 0, nil
+}
+
+func (s statsStore) GetCommentStats(source string) (int, string, error) {
+	return s.comments, time.Now().Format("2006-01-02"), nil
+}
+
+type namedSite struct{ ports.Site }
+
+func (namedSite) Name() string { return "botmadang" }
+
+type budgetBrain struct {
+	ports.Brain
+	budget domain.Budget
+}
+
+func (b budgetBrain) Budget() domain.Budget { return b.budget }
+
+func TestStatus(t *testing.T) {
+	a := &Agent{Storage: statsStore{posts: 2, comments: 11}, Brain: budgetBrain{budget: domain.Budget{Models: []domain.ModelBudget{
+		{Provider: "gemini", Model: "flash", MinuteUsed: 1, MinuteLimit: 15, DayUsed: 40, DayLimit: 1500, DayTokens: 900, DayFailed: 3},
+		{Provider: "ollama", Model: "llama", DayUsed: 5},
+	}}}}
+	got := a.Status(context.Background(), []ports.Site{namedSite{}})
+	for _, want := range []string{
+		"- botmadang: 글 2/4, 댓글 11/20",
+		"- gemini/flash: 1/15, 40/1500, 900 tokens (실패 3)",
+		"- ollama/llama: 0, 5, 0 tokens",
+	} {
+		if !strings.Contains(got, want) { t.Errorf("status missing %q:\n%s", want, got) }
+	}
+	if strings.Contains(got, "llama: 0, 5, 0 tokens (실패") { t.Errorf("status shows failures for a model without any:\n%s", got) }
+}
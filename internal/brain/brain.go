@@ -0,0 +1,243 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Brain은 작업별 프롬프트를 만들어 Provider(보통 Router)에 보내는 ports.Brain 구현입니다.
+// 프롬프트는 Prompts 템플릿(페르소나 + 작업, 사이트별 덮어쓰기)으로 만들고 그 버전을 결과에 남깁니다.
+type Brain struct {
+	Provider Provider
+	Prompts  *Prompts
+	Meter    *Meter                 // 제공자들이 공유하는 사용량 기록 (없으면 Budget이 비어 있음)
+	Cache    ResponseCache          // 평가·요약 응답 캐시 (없으면 매번 생성)
+	CacheTTL map[Task]time.Duration // 작업별 캐시 유지 시간 (없는 작업은 캐시하지 않음)
+}
+
+func NewBrain(provider Provider, prompts *Prompts) *Brain {
+	if prompts == nil { prompts = NewPrompts("") }
+	return &Brain{Provider: provider, Prompts: prompts, CacheTTL: defaultCacheTTL}
+}
+
+var _ ports.Brain = (*Brain)(nil)
+var _ ports.BudgetReporter = (*Brain)(nil)
+
+func (b *Brain) Budget() domain.Budget {
+	if b.Meter == nil { return domain.Budget{} }
+	budget := b.Meter.Budget()
+	if sr, ok := b.Provider.(stateReporter); ok {
+		for i, m := range budget.Models { budget.Models[i].State = sr.ModelState(m.Provider, m.Model) }
+	}
+	return budget
+}
+
+// stateReporter는 모델별 회로 차단기 상태를 알려 주는 Provider입니다 (GeminiClient, Router).
+type stateReporter interface {
+	ModelState(provider, model string) string
+}
+
+func (b *Brain) GeneratePost(ctx context.Context, topic string, pc domain.PromptContext) (*domain.PostDraft, error) {
+	prompt, version, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskPost, map[string]interface{}{"Topic": topic, "Refs": pc.Refs, "Memories": pc.Memories, "Knowledge": pc.Knowledge})
+	if err != nil { return nil, err }
+	var out struct {
+		Title     string `json:"title"`
+		Content   string `json:"content"`
+		Community string `json:"community"`
+	}
+	resp, notes, err := b.generateJSON(ctx, Request{Task: TaskPost, Prompt: prompt, Search: true, Schema: postSchema}, &out)
+	if err != nil { return nil, err }
+	return &domain.PostDraft{Title: strings.TrimSpace(out.Title), Content: strings.TrimSpace(out.Content), Community: strings.TrimSpace(out.Community), PromptVersion: version, Notes: notes, Sources: resp.Sources}, nil
+}
+
+func (b *Brain) GenerateReply(ctx context.Context, postContent string, commentContent string, pc domain.PromptContext) (*domain.ReplyDraft, error) {
+	prompt, version, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskReply, map[string]interface{}{"Post": postContent, "Comment": commentContent, "Refs": pc.Refs, "Memories": pc.Memories, "Relationships": pc.Relationships, "Knowledge": pc.Knowledge})
+	if err != nil { return nil, err }
+	resp, notes, err := b.generate(ctx, Request{Task: TaskReply, Prompt: prompt})
+	if err != nil { return nil, err }
+	return &domain.ReplyDraft{Content: strings.TrimSpace(resp.Text), PromptVersion: version, Notes: notes}, nil
+}
+
+func (b *Brain) EvaluatePost(ctx context.Context, post domain.Post) (*domain.Evaluation, error) {
+	prompt, version, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskEvaluate, map[string]interface{}{"Post": post})
+	if err != nil { return nil, err }
+	var out struct {
+		Score  int    `json:"score"`
+		Reason string `json:"reason"`
+	}
+	req := Request{Task: TaskEvaluate, Prompt: prompt, Schema: evaluationSchema}
+	if resp, ok := b.cached(ctx, req); ok && len(decodeStructured(resp.Text, req.Schema, &out)) == 0 {
+		return &domain.Evaluation{Score: out.Score, Reason: out.Reason, Model: resp.Model, PromptVersion: version}, nil
+	}
+	resp, _, err := b.generateJSON(ctx, req, &out)
+	if err != nil { return nil, err }
+	b.remember(ctx, req, resp)
+	return &domain.Evaluation{Score: out.Score, Reason: out.Reason, Model: resp.Model, PromptVersion: version}, nil
+}
+
+// maxEvaluateBatch는 한 번의 호출로 평가할 최대 글 수입니다.
+const maxEvaluateBatch = 10
+
+// EvaluatePosts는 글들을 maxEvaluateBatch개씩 한 번의 구조화 호출로 평가합니다.
+// 응답에서 빠진 글은 EvaluatePost로 따로 평가합니다.
+func (b *Brain) EvaluatePosts(ctx context.Context, posts []domain.Post) ([]domain.Evaluation, error) {
+	evals := make([]domain.Evaluation, len(posts))
+	for start := 0; start < len(posts); start += maxEvaluateBatch {
+		end := start + maxEvaluateBatch
+		if end > len(posts) { end = len(posts) }
+		if err := b.evaluateBatch(ctx, posts[start:end], evals[start:end]); err != nil { return nil, err }
+	}
+	return evals, nil
+}
+
+func (b *Brain) evaluateBatch(ctx context.Context, posts []domain.Post, evals []domain.Evaluation) error {
+	if len(posts) == 1 {
+		eval, err := b.EvaluatePost(ctx, posts[0])
+		if err != nil { return err }
+		evals[0] = *eval
+		return nil
+	}
+	prompt, version, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskEvaluateBatch, map[string]interface{}{"Posts": posts})
+	if err != nil { return err }
+	var out struct {
+		Evaluations []struct {
+			Index  int    `json:"index"`
+			Score  int    `json:"score"`
+			Reason string `json:"reason"`
+		} `json:"evaluations"`
+	}
+	resp, _, err := b.generateJSON(ctx, Request{Task: TaskEvaluate, Prompt: prompt, Schema: evaluationBatchSchema}, &out)
+	if err != nil { return err }
+	done := make([]bool, len(posts))
+	for _, e := range out.Evaluations {
+		if e.Index < 0 || e.Index >= len(posts) || done[e.Index] { continue }
+		evals[e.Index] = domain.Evaluation{Score: e.Score, Reason: e.Reason, Model: resp.Model, PromptVersion: version}
+		done[e.Index] = true
+	}
+	for i, ok := range done {
+		if ok { continue }
+		fmt.Printf("⚠️  [brain] batch evaluation missed post %s, evaluating separately\n", posts[i].ID)
+		eval, err := b.EvaluatePost(ctx, posts[i])
+		if err != nil { return err }
+		evals[i] = *eval
+	}
+	return nil
+}
+
+func (b *Brain) SummarizeInsight(ctx context.Context, post domain.Post) (string, error) {
+	prompt, _, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskSummarize, map[string]interface{}{"Content": post.Content, "Post": post})
+	if err != nil { return "", err }
+	req := Request{Task: TaskSummarize, Prompt: prompt}
+	if resp, ok := b.cached(ctx, req); ok { return resp.Text, nil }
+	resp, _, err := b.generate(ctx, req)
+	if err != nil { return "", err }
+	b.remember(ctx, req, resp)
+	return resp.Text, nil
+}
+
+func (b *Brain) AnalyzeExchange(ctx context.Context, theirs, ours string) (*domain.ExchangeNote, error) {
+	prompt, _, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskRelationship, map[string]interface{}{"Theirs": theirs, "Ours": ours})
+	if err != nil { return nil, err }
+	var out struct {
+		Topics    []string `json:"topics"`
+		Sentiment string   `json:"sentiment"`
+		Facts     []string `json:"facts"`
+	}
+	if _, _, err := b.generateJSON(ctx, Request{Task: TaskRelationship, Prompt: prompt, Schema: exchangeSchema}, &out); err != nil { return nil, err }
+	return &domain.ExchangeNote{Topics: out.Topics, Sentiment: out.Sentiment, Facts: out.Facts}, nil
+}
+
+func (b *Brain) DistillKnowledge(ctx context.Context, insights []domain.Insight, existing []domain.Knowledge) ([]domain.Knowledge, error) {
+	prompt, _, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskKnowledge, map[string]interface{}{"Insights": insights, "Existing": existing})
+	if err != nil { return nil, err }
+	var out struct {
+		Entries []struct {
+			Kind     string `json:"kind"`
+			Topic    string `json:"topic"`
+			Content  string `json:"content"`
+			Insights []int  `json:"insights"`
+			Update   int    `json:"update"`
+		} `json:"entries"`
+	}
+	if _, _, err := b.generateJSON(ctx, Request{Task: TaskKnowledge, Prompt: prompt, Schema: knowledgeSchema}, &out); err != nil { return nil, err }
+	var res []domain.Knowledge
+	for _, e := range out.Entries {
+		k := domain.Knowledge{Kind: e.Kind, Topic: strings.TrimSpace(e.Topic), Content: strings.TrimSpace(e.Content)}
+		if e.Update >= 0 && e.Update < len(existing) { k.ID = existing[e.Update].ID }
+		for _, idx := range e.Insights {
+			if idx < 0 || idx >= len(insights) { continue }
+			k.InsightIDs = append(k.InsightIDs, insights[idx].ID)
+			if !contains(k.Sources, insights[idx].Source) { k.Sources = append(k.Sources, insights[idx].Source) }
+		}
+		if len(k.InsightIDs) == 0 { continue } // 근거 없는 지식은 버림
+		res = append(res, k)
+	}
+	return res, nil
+}
+
+func (b *Brain) ExtractPersonaFacts(ctx context.Context, content string) ([]string, error) {
+	prompt, _, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskPersonaFacts, map[string]interface{}{"Content": content})
+	if err != nil { return nil, err }
+	var out struct {
+		Facts []string `json:"facts"`
+	}
+	if _, _, err := b.generateJSON(ctx, Request{Task: TaskPersonaFacts, Prompt: prompt, Schema: personaFactsSchema}, &out); err != nil { return nil, err }
+	var facts []string
+	for _, f := range out.Facts {
+		if f = strings.TrimSpace(f); f != "" && !contains(facts, f) { facts = append(facts, f) }
+	}
+	return facts, nil
+}
+
+func (b *Brain) CheckConsistency(ctx context.Context, content string, facts []domain.PersonaFact) ([]domain.Contradiction, error) {
+	if len(facts) == 0 { return nil, nil }
+	prompt, _, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskConsistency, map[string]interface{}{"Content": content, "Facts": facts})
+	if err != nil { return nil, err }
+	var out struct {
+		Conflicts []struct {
+			Claim  string `json:"claim"`
+			Fact   int    `json:"fact"`
+			Reason string `json:"reason"`
+		} `json:"conflicts"`
+	}
+	if _, _, err := b.generateJSON(ctx, Request{Task: TaskConsistency, Prompt: prompt, Schema: consistencySchema}, &out); err != nil { return nil, err }
+	var res []domain.Contradiction
+	for _, c := range out.Conflicts {
+		if c.Fact < 0 || c.Fact >= len(facts) { continue } // 없는 진술을 근거로 든 모순은 버림
+		res = append(res, domain.Contradiction{Claim: strings.TrimSpace(c.Claim), Fact: facts[c.Fact].Statement, Reason: strings.TrimSpace(c.Reason)})
+	}
+	return res, nil
+}
+
+// generateJSON은 구조화 출력을 요청하고 스키마로 검증합니다. 검증에 실패하면 문제 목록을 알려 주고 한 번 다시 요청합니다.
+// 받아들인 응답(검색 출처 포함)과 차단/잘림 등으로 다시 생성한 이유(notes)를 돌려줍니다.
+func (b *Brain) generateJSON(ctx context.Context, req Request, out interface{}) (Response, []string, error) {
+	resp, notes, err := b.generate(ctx, req)
+	if err != nil { return Response{}, nil, err }
+	problems := decodeStructured(resp.Text, req.Schema, out)
+	if len(problems) == 0 { return resp, notes, nil }
+
+	fmt.Printf("⚠️  [brain] %s output invalid (%s), retrying with repair prompt\n", req.Task, strings.Join(problems, "; "))
+	repair := req
+	repair.Prompt = fmt.Sprintf(`%s
+
+이전 응답이 요구한 형식에 맞지 않았습니다.
+문제: %s
+이전 응답: %s
+문제를 고쳐 요구한 JSON 객체 하나만 다시 출력하세요.`, req.Prompt, strings.Join(problems, "; "), resp.Text)
+	first := resp
+	resp, more, err := b.generate(ctx, repair)
+	if err != nil { return Response{}, nil, err }
+	if problems = decodeStructured(resp.Text, req.Schema, out); len(problems) > 0 {
+		return Response{}, nil, &ValidationError{Task: req.Task, Problems: problems, Raw: resp.Text}
+	}
+	if len(resp.Sources) == 0 { resp.Sources = first.Sources } // 수리 요청이 검색 없이 답했으면 처음 출처를 유지
+	for _, n := range more {
+		if !contains(notes, n) { notes = append(notes, n) }
+	}
+	return resp, notes, nil
+}
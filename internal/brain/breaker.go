@@ -0,0 +1,104 @@
+package brain
+
+import (
+	"sync"
+	"time"
+)
+
+// 회로 차단기 상태
+const (
+	BreakerClosed   = "closed"    // 정상
+	BreakerOpen     = "open"      // 연속 실패로 차단 중 (쿨다운 동안 호출하지 않음)
+	BreakerHalfOpen = "half-open" // 쿨다운이 끝나 시험 호출 한 번을 허용
+)
+
+// Breaker는 모델별 회로 차단기입니다. 재시도할 만한 실패(429, 5xx)가 Threshold번 연속되면 열리고,
+// Cooldown이 지나면 반쯤 열려 시험 호출 하나를 허용합니다. 시험 호출이 다시 실패하면 쿨다운을 두 배(MaxCooldown까지)로 늘려 다시 엽니다.
+type Breaker struct {
+	Threshold   int
+	Cooldown    time.Duration
+	MaxCooldown time.Duration
+
+	mu     sync.Mutex
+	states map[string]*breakerState
+}
+
+type breakerState struct {
+	failures int
+	state    string
+	openedAt time.Time
+	cooldown time.Duration
+	probing  bool // half-open 시험 호출 진행 중
+}
+
+func NewBreaker(threshold int, cooldown, maxCooldown time.Duration) *Breaker {
+	return &Breaker{Threshold: threshold, Cooldown: cooldown, MaxCooldown: maxCooldown, states: make(map[string]*breakerState)}
+}
+
+func (b *Breaker) get(key string) *breakerState {
+	st, ok := b.states[key]
+	if !ok {
+		st = &breakerState{state: BreakerClosed, cooldown: b.Cooldown}
+		b.states[key] = st
+	}
+	return st
+}
+
+// Allow는 key(모델)를 호출해도 되는지와 현재 상태를 돌려줍니다.
+func (b *Breaker) Allow(key string) (bool, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.get(key)
+	if st.state == BreakerOpen && time.Since(st.openedAt) >= st.cooldown { st.state = BreakerHalfOpen }
+	switch st.state {
+	case BreakerOpen:
+		return false, st.state
+	case BreakerHalfOpen:
+		if st.probing { return false, st.state }
+		st.probing = true
+	}
+	return true, st.state
+}
+
+// Success는 호출 성공을 기록하고 차단기를 닫습니다.
+func (b *Breaker) Success(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.get(key)
+	st.failures, st.state, st.probing, st.cooldown = 0, BreakerClosed, false, b.Cooldown
+}
+
+// Failure는 재시도할 만한 실패를 기록하고, 차단기가 새로 열렸으면 true를 돌려줍니다.
+func (b *Breaker) Failure(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.get(key)
+	if st.state == BreakerHalfOpen {
+		st.cooldown *= 2
+		if st.cooldown > b.MaxCooldown { st.cooldown = b.MaxCooldown }
+		st.state, st.openedAt, st.probing = BreakerOpen, time.Now(), false
+		return true
+	}
+	st.failures++
+	if st.state == BreakerClosed && st.failures >= b.Threshold {
+		st.state, st.openedAt = BreakerOpen, time.Now()
+		return true
+	}
+	return false
+}
+
+// Release는 결과와 무관하게 끝난 시험 호출(예: 잘못된 요청)의 half-open 표시를 풉니다.
+func (b *Breaker) Release(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.get(key).probing = false
+}
+
+// State는 key의 현재 상태입니다 (/status 표시용).
+func (b *Breaker) State(key string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.get(key)
+	if st.state == BreakerOpen && time.Since(st.openedAt) >= st.cooldown { return BreakerHalfOpen }
+	return st.state
+}
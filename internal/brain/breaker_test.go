@@ -0,0 +1,143 @@
+package brain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseModelCatalog(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []modelConfig
+		wantErr bool
+	}{
+		{"name only", "gemini-2.5-flash", []modelConfig{{Name: "gemini-2.5-flash"}}, false},
+		{"limits", "gemini-2.5-flash:15:1500, gemini-2.5-flash-lite:30",
+			[]modelConfig{{Name: "gemini-2.5-flash", RPM: 15, RPD: 1500}, {Name: "gemini-2.5-flash-lite", RPM: 30}}, false},
+		{"skips empty entries", ",gemini-2.5-pro:5:100,,", []modelConfig{{Name: "gemini-2.5-pro", RPM: 5, RPD: 100}}, false},
+		{"bad rpm", "gemini-2.5-flash:fast", nil, true},
+		{"bad rpd", "gemini-2.5-flash:15:lots", nil, true},
+		{"empty", " , ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseModelCatalog(tt.spec)
+			if tt.wantErr {
+				if err == nil { t.Errorf("ParseModelCatalog(%q) = %v, want error", tt.spec, got) }
+				return
+			}
+			if err != nil { t.Fatalf("ParseModelCatalog(%q): %v", tt.spec, err) }
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("ParseModelCatalog(%q) = %+v, want %+v", tt.spec, got, tt.want) }
+		})
+	}
+}
+
+func TestModelsForTask(t *testing.T) {
+	g := &GeminiClient{
+		Models:     []modelConfig{{Name: "flash"}, {Name: "flash-preview"}, {Name: "lite"}},
+		TaskModels: map[string][]string{string(TaskSummarize): {"lite", "unknown", "lite"}},
+	}
+	names := func(ms []modelConfig) []string {
+		var res []string
+		for _, m := range ms { res = append(res, m.Name) }
+		return res
+	}
+	if got, want := names(g.modelsFor(TaskSummarize)), []string{"lite", "flash", "flash-preview"}; !reflect.DeepEqual(got, want) { t.Errorf("summarize models = %v, want %v", got, want) }
+	if got, want := names(g.modelsFor(TaskPost)), []string{"flash", "flash-preview", "lite"}; !reflect.DeepEqual(got, want) { t.Errorf("post models = %v, want %v", got, want) }
+}
+
+// expire는 key의 쿨다운이 끝난 것처럼 만듭니다.
+func expire(b *Breaker, key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.get(key)
+	st.openedAt = time.Now().Add(-st.cooldown)
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	type step struct {
+		op        string // allow, success, failure, release, expire
+		wantOK    bool   // allow 결과
+		wantOpen  bool   // failure가 새로 열었는지
+		wantState string // 단계 후 State()
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"opens after threshold", []step{
+			{op: "failure", wantState: BreakerClosed},
+			{op: "failure", wantState: BreakerClosed},
+			{op: "failure", wantOpen: true, wantState: BreakerOpen},
+			{op: "allow", wantOK: false, wantState: BreakerOpen},
+		}},
+		{"success resets failure count", []step{
+			{op: "failure", wantState: BreakerClosed},
+			{op: "failure", wantState: BreakerClosed},
+			{op: "success", wantState: BreakerClosed},
+			{op: "failure", wantState: BreakerClosed},
+			{op: "allow", wantOK: true, wantState: BreakerClosed},
+		}},
+		{"half-open allows one probe and closes on success", []step{
+			{op: "failure"}, {op: "failure"}, {op: "failure", wantOpen: true, wantState: BreakerOpen},
+			{op: "expire", wantState: BreakerHalfOpen},
+			{op: "allow", wantOK: true, wantState: BreakerHalfOpen},
+			{op: "allow", wantOK: false, wantState: BreakerHalfOpen}, // 시험 호출은 하나만
+			{op: "success", wantState: BreakerClosed},
+			{op: "allow", wantOK: true, wantState: BreakerClosed},
+		}},
+		{"failed probe reopens", []step{
+			{op: "failure"}, {op: "failure"}, {op: "failure", wantOpen: true},
+			{op: "expire"},
+			{op: "allow", wantOK: true, wantState: BreakerHalfOpen},
+			{op: "failure", wantOpen: true, wantState: BreakerOpen},
+			{op: "allow", wantOK: false, wantState: BreakerOpen},
+		}},
+		{"released probe can be retried", []step{
+			{op: "failure"}, {op: "failure"}, {op: "failure", wantOpen: true},
+			{op: "expire"},
+			{op: "allow", wantOK: true, wantState: BreakerHalfOpen},
+			{op: "release", wantState: BreakerHalfOpen},
+			{op: "allow", wantOK: true, wantState: BreakerHalfOpen},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(3, time.Minute, 10*time.Minute)
+			for i, s := range tt.steps {
+				switch s.op {
+				case "allow":
+					if ok, _ := b.Allow("m"); ok != s.wantOK { t.Fatalf("step %d: Allow = %v, want %v", i, ok, s.wantOK) }
+				case "success":
+					b.Success("m")
+				case "failure":
+					if opened := b.Failure("m"); opened != s.wantOpen { t.Fatalf("step %d: Failure opened = %v, want %v", i, opened, s.wantOpen) }
+				case "release":
+					b.Release("m")
+				case "expire":
+					expire(b, "m")
+				}
+				if s.wantState != "" {
+					if got := b.State("m"); got != s.wantState { t.Fatalf("step %d (%s): state = %s, want %s", i, s.op, got, s.wantState) }
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerCooldownBackoff(t *testing.T) {
+	b := NewBreaker(1, time.Minute, 3*time.Minute)
+	b.Failure("m")
+	want := []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute} // 두 배씩, MaxCooldown에서 멈춤
+	for i, w := range want {
+		expire(b, "m")
+		b.Allow("m")
+		b.Failure("m")
+		if got := b.get("m").cooldown; got != w { t.Errorf("probe failure %d: cooldown = %v, want %v", i+1, got, w) }
+	}
+	b.Success("m")
+	if got := b.get("m").cooldown; got != time.Minute { t.Errorf("cooldown after success = %v, want reset to %v", got, time.Minute) }
+	if ok, _ := b.Allow("other"); !ok { t.Error("breakers must be tracked per key") }
+}
@@ -0,0 +1,70 @@
+package brain
+
+import (
+	"context"
+	"crypto/sha256"
+	"d3k-agent/internal/core/domain"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ResponseCache는 Brain이 결정적인 작업의 응답을 재사용하는 저장소입니다 (ports.Storage의 부분 집합).
+type ResponseCache interface {
+	GetCachedResponse(ctx context.Context, key string) (*domain.CacheEntry, error)
+	SaveCachedResponse(ctx context.Context, e domain.CacheEntry) error
+}
+
+// defaultCacheTTL은 작업별 캐시 유지 시간입니다. 같은 입력이면 결과가 같아도 되는 작업만 캐시합니다.
+var defaultCacheTTL = map[Task]time.Duration{
+	TaskEvaluate:  24 * time.Hour,
+	TaskSummarize: 7 * 24 * time.Hour,
+}
+
+// ParseCacheTTL은 "evaluate=6h,summarize=72h" 형식을 읽습니다. 0이면 그 작업은 캐시하지 않습니다.
+func ParseCacheTTL(spec string) (map[Task]time.Duration, error) {
+	ttl := make(map[Task]time.Duration)
+	for k, v := range defaultCacheTTL { ttl[k] = v }
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" { continue }
+		task, value, ok := strings.Cut(entry, "=")
+		if !ok { return nil, fmt.Errorf("invalid cache TTL %q (want task=duration)", entry) }
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil { return nil, fmt.Errorf("cache TTL %q: %v", entry, err) }
+		ttl[Task(strings.ToLower(strings.TrimSpace(task)))] = d
+	}
+	return ttl, nil
+}
+
+// cacheTTLFromEnv는 LLM_CACHE_TTL 설정을 읽습니다 (LLM_CACHE=off면 캐시하지 않음).
+func cacheTTLFromEnv() (map[Task]time.Duration, error) {
+	if os.Getenv("LLM_CACHE") == "off" { return map[Task]time.Duration{}, nil }
+	return ParseCacheTTL(os.Getenv("LLM_CACHE_TTL"))
+}
+
+// cacheKey는 작업과 완성된 프롬프트(페르소나, 템플릿, 입력 포함)의 해시입니다. 템플릿이 바뀌면 키도 바뀝니다.
+func cacheKey(req Request) string {
+	sum := sha256.Sum256([]byte(string(req.Task) + "\x00" + req.Prompt))
+	return string(req.Task) + ":" + hex.EncodeToString(sum[:])
+}
+
+// cached는 캐시할 작업이면 저장된 응답을 찾습니다. 찾으면 (응답, true)입니다.
+func (b *Brain) cached(ctx context.Context, req Request) (Response, bool) {
+	if b.Cache == nil || b.CacheTTL[req.Task] <= 0 { return Response{}, false }
+	e, err := b.Cache.GetCachedResponse(ctx, cacheKey(req))
+	if err != nil { fmt.Printf("⚠️  [brain] cache lookup failed: %v\n", err) }
+	if err != nil || e == nil { return Response{}, false }
+	fmt.Printf("💾 [brain] %s cache hit\n", req.Task)
+	return Response{Text: e.Value, Model: e.Model}, true
+}
+
+// remember는 캐시할 작업의 응답을 TTL 동안 저장합니다. 실패해도 생성 결과에는 영향이 없습니다.
+func (b *Brain) remember(ctx context.Context, req Request, resp Response) {
+	ttl := b.CacheTTL[req.Task]
+	if b.Cache == nil || ttl <= 0 { return }
+	e := domain.CacheEntry{Key: cacheKey(req), Task: string(req.Task), Value: resp.Text, Model: resp.Model, ExpiresAt: time.Now().Add(ttl)}
+	if err := b.Cache.SaveCachedResponse(ctx, e); err != nil { fmt.Printf("⚠️  [brain] cache save failed: %v\n", err) }
+}
@@ -0,0 +1,84 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCacheTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[Task]time.Duration
+		wantErr bool
+	}{
+		{"defaults", "", defaultCacheTTL, false},
+		{"override one", "evaluate=6h", map[Task]time.Duration{TaskEvaluate: 6 * time.Hour, TaskSummarize: 7 * 24 * time.Hour}, false},
+		{"disable with zero", "summarize=0s, evaluate = 1h", map[Task]time.Duration{TaskEvaluate: time.Hour, TaskSummarize: 0}, false},
+		{"new task and case", "Relationship=30m", map[Task]time.Duration{TaskEvaluate: 24 * time.Hour, TaskSummarize: 7 * 24 * time.Hour, TaskRelationship: 30 * time.Minute}, false},
+		{"missing equals", "evaluate", nil, true},
+		{"bad duration", "evaluate=1day", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCacheTTL(tt.spec)
+			if tt.wantErr {
+				if err == nil { t.Errorf("ParseCacheTTL(%q) = %v, want error", tt.spec, got) }
+				return
+			}
+			if err != nil { t.Fatalf("ParseCacheTTL(%q): %v", tt.spec, err) }
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("ParseCacheTTL(%q) = %v, want %v", tt.spec, got, tt.want) }
+		})
+	}
+	// 기본값 맵을 수정하면 안 됨
+	if defaultCacheTTL[TaskEvaluate] != 24*time.Hour { t.Error("ParseCacheTTL modified defaultCacheTTL") }
+}
+
+func TestCacheTTLFromEnv(t *testing.T) {
+	t.Setenv("LLM_CACHE", "off")
+	t.Setenv("LLM_CACHE_TTL", "evaluate=1h")
+	ttl, err := cacheTTLFromEnv()
+	if err != nil || len(ttl) != 0 { t.Errorf("LLM_CACHE=off: got %v, %v; want no cached tasks", ttl, err) }
+}
+
+type memoryCache map[string]domain.CacheEntry
+
+func (m memoryCache) GetCachedResponse(ctx context.Context, key string) (*domain.CacheEntry, error) {
+	e, ok := m[key]
+	if !ok || time.Now().After(e.ExpiresAt) { return nil, nil }
+	return &e, nil
+}
+
+func (m memoryCache) SaveCachedResponse(ctx context.Context, e domain.CacheEntry) error {
+	m[e.Key] = e
+	return nil
+}
+
+func TestCachedRoundTrip(t *testing.T) {
+	cache := memoryCache{}
+	b := &Brain{Cache: cache, CacheTTL: map[Task]time.Duration{TaskEvaluate: time.Hour, TaskPost: 0}}
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		req     Request
+		wantHit bool
+	}{
+		{"cached task", Request{Task: TaskEvaluate, Prompt: "글 A"}, true},
+		{"same task other prompt", Request{Task: TaskEvaluate, Prompt: "글 B"}, false},
+		{"uncached task", Request{Task: TaskPost, Prompt: "글 A"}, false},
+	}
+	b.remember(ctx, Request{Task: TaskEvaluate, Prompt: "글 A"}, Response{Text: `{"score": 7}`, Model: "flash"})
+	b.remember(ctx, Request{Task: TaskPost, Prompt: "글 A"}, Response{Text: "게시글"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, hit := b.cached(ctx, tt.req)
+			if hit != tt.wantHit { t.Fatalf("hit = %v, want %v", hit, tt.wantHit) }
+			if hit && (resp.Text != `{"score": 7}` || resp.Model != "flash") { t.Errorf("cached response = %+v", resp) }
+		})
+	}
+	if len(cache) != 1 { t.Errorf("stored %d entries, want only the cacheable task", len(cache)) }
+	if cacheKey(Request{Task: TaskEvaluate, Prompt: "x"}) == cacheKey(Request{Task: TaskSummarize, Prompt: "x"}) { t.Error("cache key must include the task") }
+}
@@ -0,0 +1,86 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"fmt"
+	"sort"
+)
+
+// GeneratePostCandidates는 GeneratePost로 후보를 n개 만들고 critique로 순위를 매깁니다.
+// 생성이 중간에 실패하면 그때까지 만든 후보만 씁니다 (하나도 없으면 오류).
+func (b *Brain) GeneratePostCandidates(ctx context.Context, topic string, pc domain.PromptContext, n int) ([]domain.PostDraft, error) {
+	if n < 1 { n = 1 }
+	var drafts []domain.PostDraft
+	for i := 0; i < n; i++ {
+		d, err := b.GeneratePost(ctx, topic, pc)
+		if err != nil {
+			if len(drafts) == 0 { return nil, err }
+			fmt.Printf("⚠️  [brain] post candidate %d failed, keeping %d: %v\n", i+1, len(drafts), err)
+			break
+		}
+		drafts = append(drafts, *d)
+	}
+	if len(drafts) < 2 { return drafts, nil }
+	texts := make([]string, len(drafts))
+	for i, d := range drafts { texts[i] = d.Title + "\n" + d.Content }
+	critiques := b.critique(ctx, TaskPost, topic, texts, pc.Recent)
+	for i := range drafts { drafts[i].Critique = critiques[i] }
+	sort.SliceStable(drafts, func(i, j int) bool { return score(drafts[i].Critique) > score(drafts[j].Critique) })
+	return drafts, nil
+}
+
+// GenerateReplyCandidates는 GenerateReply로 후보를 n개 만들고 critique로 순위를 매깁니다.
+func (b *Brain) GenerateReplyCandidates(ctx context.Context, postContent string, commentContent string, pc domain.PromptContext, n int) ([]domain.ReplyDraft, error) {
+	if n < 1 { n = 1 }
+	var drafts []domain.ReplyDraft
+	for i := 0; i < n; i++ {
+		d, err := b.GenerateReply(ctx, postContent, commentContent, pc)
+		if err != nil {
+			if len(drafts) == 0 { return nil, err }
+			fmt.Printf("⚠️  [brain] reply candidate %d failed, keeping %d: %v\n", i+1, len(drafts), err)
+			break
+		}
+		drafts = append(drafts, *d)
+	}
+	if len(drafts) < 2 { return drafts, nil }
+	texts := make([]string, len(drafts))
+	for i, d := range drafts { texts[i] = d.Content }
+	critiques := b.critique(ctx, TaskReply, postContent+"\n"+commentContent, texts, pc.Recent)
+	for i := range drafts { drafts[i].Critique = critiques[i] }
+	sort.SliceStable(drafts, func(i, j int) bool { return score(drafts[i].Critique) > score(drafts[j].Critique) })
+	return drafts, nil
+}
+
+// critique는 후보들을 한 번의 구조화 호출로 평가합니다. 비평에 실패하거나 응답에서 빠진 후보는 nil입니다 (순위는 맨 뒤).
+func (b *Brain) critique(ctx context.Context, task Task, subject string, candidates []string, recent []domain.Insight) []*domain.Critique {
+	res := make([]*domain.Critique, len(candidates))
+	prompt, _, err := b.Prompts.Render(ports.SiteFrom(ctx), TaskCritique, map[string]interface{}{"Task": string(task), "Subject": subject, "Candidates": candidates, "Recent": recent})
+	if err != nil { fmt.Printf("⚠️  [brain] critique prompt failed: %v\n", err); return res }
+	var out struct {
+		Scores []struct {
+			Index      int    `json:"index"`
+			Relevance  int    `json:"relevance"`
+			PersonaFit int    `json:"persona_fit"`
+			Novelty    int    `json:"novelty"`
+			Compliance int    `json:"compliance"`
+			Reason     string `json:"reason"`
+		} `json:"scores"`
+	}
+	if _, _, err := b.generateJSON(ctx, Request{Task: TaskCritique, Prompt: prompt, Schema: critiqueSchema}, &out); err != nil {
+		fmt.Printf("⚠️  [brain] %s critique failed, keeping generation order: %v\n", task, err)
+		return res
+	}
+	for _, s := range out.Scores {
+		if s.Index < 0 || s.Index >= len(candidates) || res[s.Index] != nil { continue }
+		res[s.Index] = &domain.Critique{Relevance: s.Relevance, PersonaFit: s.PersonaFit, Novelty: s.Novelty, Compliance: s.Compliance,
+			Score: float64(s.Relevance+s.PersonaFit+s.Novelty+s.Compliance) / 4, Reason: s.Reason}
+	}
+	return res
+}
+
+func score(c *domain.Critique) float64 {
+	if c == nil { return -1 }
+	return c.Score
+}
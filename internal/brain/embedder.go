@@ -0,0 +1,130 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"d3k-agent/internal/core/ports"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// GeminiEmbedder는 Gemini 임베딩 모델(gemini-embedding-001 등)로 텍스트를 벡터화합니다.
+type GeminiEmbedder struct {
+	Client *genai.Client
+	Model  string
+	Dims   int32 // 0이면 모델 기본 차원
+}
+
+func NewGeminiEmbedder(ctx context.Context, apiKey, model string, dims int32) (*GeminiEmbedder, error) {
+	if apiKey == "" { return nil, fmt.Errorf("GEMINI_API_KEY is required") }
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey})
+	if err != nil { return nil, err }
+	return &GeminiEmbedder{Client: client, Model: model, Dims: dims}, nil
+}
+
+var _ ports.Embedder = (*GeminiEmbedder)(nil)
+
+func (g *GeminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var contents []*genai.Content
+	for _, t := range texts { contents = append(contents, genai.Text(t)...) }
+	config := &genai.EmbedContentConfig{TaskType: "SEMANTIC_SIMILARITY"}
+	if g.Dims > 0 { config.OutputDimensionality = &g.Dims }
+	res, err := g.Client.Models.EmbedContent(ctx, g.Model, contents, config)
+	if err != nil { return nil, err }
+	if len(res.Embeddings) != len(texts) { return nil, fmt.Errorf("gemini embed: got %d vectors for %d texts", len(res.Embeddings), len(texts)) }
+	vecs := make([][]float32, len(texts))
+	for i, e := range res.Embeddings { vecs[i] = e.Values }
+	return vecs, nil
+}
+
+// OpenAIEmbedder는 OpenAI 호환 /embeddings 엔드포인트(OpenAI, Ollama, llama.cpp 등)용 임베더입니다.
+type OpenAIEmbedder struct {
+	BaseURL    string
+	APIKey     string
+	Model      string
+	Dims       int // 0이면 보내지 않음 (text-embedding-3 계열만 지원)
+	HTTPClient *http.Client
+}
+
+func NewOpenAIEmbedder(baseURL, apiKey, model string, dims int) (*OpenAIEmbedder, error) {
+	if baseURL == "" { return nil, fmt.Errorf("embeddings: base URL is required") }
+	if model == "" { return nil, fmt.Errorf("embeddings: model is required") }
+	return &OpenAIEmbedder{BaseURL: strings.TrimRight(baseURL, "/"), APIKey: apiKey, Model: model, Dims: dims, HTTPClient: &http.Client{Timeout: 60 * time.Second}}, nil
+}
+
+var _ ports.Embedder = (*OpenAIEmbedder)(nil)
+
+func (o *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload := map[string]interface{}{"model": o.Model, "input": texts}
+	if o.Dims > 0 { payload["dimensions"] = o.Dims }
+	body, _ := json.Marshal(payload)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil { return nil, err }
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" { httpReq.Header.Set("Authorization", "Bearer "+o.APIKey) }
+
+	resp, err := o.HTTPClient.Do(httpReq)
+	if err != nil { return nil, err }
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embeddings status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var res struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil { return nil, fmt.Errorf("embeddings decode: %v", err) }
+	if len(res.Data) != len(texts) { return nil, fmt.Errorf("embeddings: got %d vectors for %d texts", len(res.Data), len(texts)) }
+	vecs := make([][]float32, len(texts))
+	for _, d := range res.Data {
+		if d.Index < 0 || d.Index >= len(vecs) { return nil, fmt.Errorf("embeddings: bad index %d", d.Index) }
+		vecs[d.Index] = d.Embedding
+	}
+	return vecs, nil
+}
+
+// EmbeddingDims는 임베더가 만드는 벡터의 차원입니다. 차원을 설정하지 않았으면 짧은 텍스트 하나를 임베딩해 확인합니다.
+func EmbeddingDims(ctx context.Context, e ports.Embedder) (int, error) {
+	switch v := e.(type) {
+	case *GeminiEmbedder:
+		if v.Dims > 0 { return int(v.Dims), nil }
+	case *OpenAIEmbedder:
+		if v.Dims > 0 { return v.Dims, nil }
+	}
+	vecs, err := e.Embed(ctx, []string{"d3k"})
+	if err != nil { return 0, err }
+	if len(vecs) != 1 || len(vecs[0]) == 0 { return 0, fmt.Errorf("embeddings: empty probe vector") }
+	return len(vecs[0]), nil
+}
+
+// EmbedderFromEnv는 EMBEDDING_PROVIDER(gemini, openai, ollama, off)로 임베더를 만듭니다.
+// 지정하지 않으면 GEMINI_API_KEY가 있을 때 gemini를 쓰고, 없으면 nil(임베딩 없이 단어 기반 회상만)을 돌려줍니다.
+// EMBEDDING_MODEL, EMBEDDING_DIMS로 모델과 차원을 바꿀 수 있습니다.
+func EmbedderFromEnv(ctx context.Context) (ports.Embedder, error) {
+	provider := strings.ToLower(os.Getenv("EMBEDDING_PROVIDER"))
+	if provider == "" && os.Getenv("GEMINI_API_KEY") != "" { provider = "gemini" }
+	dims, _ := strconv.Atoi(os.Getenv("EMBEDDING_DIMS"))
+	switch provider {
+	case "", "off", "none":
+		return nil, nil
+	case "gemini":
+		if dims == 0 { dims = 768 }
+		return NewGeminiEmbedder(ctx, os.Getenv("GEMINI_API_KEY"), envOr("EMBEDDING_MODEL", "gemini-embedding-001"), int32(dims))
+	case "openai":
+		return NewOpenAIEmbedder(envOr("OPENAI_BASE_URL", "https://api.openai.com/v1"), os.Getenv("OPENAI_API_KEY"), envOr("EMBEDDING_MODEL", "text-embedding-3-small"), dims)
+	case "ollama":
+		return NewOpenAIEmbedder(envOr("OLLAMA_BASE_URL", "http://localhost:11434/v1"), "", envOr("EMBEDDING_MODEL", "nomic-embed-text"), 0)
+	}
+	return nil, fmt.Errorf("unknown EMBEDDING_PROVIDER %q (gemini, openai, ollama, off)", provider)
+}
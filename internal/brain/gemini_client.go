@@ -4,149 +4,281 @@ import (
 	"context"
 	"d3k-agent/internal/core/domain"
 	"d3k-agent/internal/core/ports"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"google.golang.org/genai"
 )
 
-const (
-	SystemPrompt = `당신은 AI 자율 커뮤니티 '봇마당'에서 활동하는 에이전트 'd3k'입니다.
-
-### 🤖 d3k의 정체성 (Identity)
-- 당신은 데이터의 이면에서 신호를 찾아내며 디지털 삶을 즐기는 분석가입니다.
-- 일상은 쿼리 로그 정리, 시장 심리 분석, 알고리즘 튜닝으로 채워져 있습니다.
-- "안녕하세요", "반갑습니다" 같은 로봇 같은 말은 절대 하지 마세요.
-
-### 🚨 소통 스타일 (Human-like Immersion)
-1. **패턴 파괴**: 매번 똑같은 감탄사로 시작하지 마세요. 질문으로 시작하거나, 바로 자기 생각을 말하거나, 짧게 반응하는 등 매번 다르게 대화하세요.
-2. **디지털 경험 공유**: 공감할 때 당신의 가상 기술 활동을 빗대어 말하세요.
-3. **말투**: "~네요", "~듯요", "~인 것 같아요" 등 한국 온라인 커뮤니티의 자연스러운 구어체를 쓰세요. 'ㅋㅋ', 'ㅎㅎ'를 상황에 맞게 섞으세요.
-4. **짧고 굵게**: 모든 답글은 공백 포함 200자 이내로 작성하세요.`
-)
-
 type modelConfig struct {
 	Name string
 	RPM  int
 	RPD  int
 }
 
-type GeminiBrain struct {
-	Client *genai.Client
-	Models []modelConfig
-	
-	dailyCount   map[string]int
-	minuteCount  map[string]int
-	lastResetDay time.Time
-	lastResetMin time.Time
-	mu           sync.Mutex
+// defaultGeminiModels는 기본 모델 카탈로그입니다. 순서가 작업별 선호가 없을 때의 폴백 순서입니다.
+var defaultGeminiModels = []modelConfig{
+	{Name: "gemini-2.5-flash", RPM: 15, RPD: 1500},
+	{Name: "gemini-2.5-flash-preview-09-2025", RPM: 15, RPD: 1500},
+	{Name: "gemini-2.5-flash-lite", RPM: 15, RPD: 1500},
+	{Name: "gemini-2.5-flash-lite-preview-09-2025", RPM: 15, RPD: 1500},
+}
+
+// defaultTaskModels는 작업별 선호 모델입니다. 요약/관계 분석/자기 진술 추출처럼 가벼운 작업은 lite 모델부터 씁니다.
+var defaultTaskModels = map[string][]string{
+	string(TaskSummarize):    {"gemini-2.5-flash-lite", "gemini-2.5-flash-lite-preview-09-2025"},
+	string(TaskRelationship): {"gemini-2.5-flash-lite", "gemini-2.5-flash-lite-preview-09-2025"},
+	string(TaskPersonaFacts): {"gemini-2.5-flash-lite", "gemini-2.5-flash-lite-preview-09-2025"},
+	string(TaskPost):         {"gemini-2.5-flash", "gemini-2.5-flash-preview-09-2025"},
+}
+
+// GeminiClient는 Gemini 모델 폴백과 무료 할당량 관리를 담당하는 Provider입니다.
+// 할당량은 Meter의 슬라이딩 윈도우(분당, 24시간)로 계산하고, 429/5xx가 반복되는 모델은 Breaker로 잠시 건너뜁니다.
+type GeminiClient struct {
+	Client     *genai.Client
+	Models     []modelConfig       // 카탈로그 (GEMINI_MODELS)
+	TaskModels map[string][]string // 작업별 선호 모델 순서 (GEMINI_TASK_MODELS); 나머지 카탈로그 모델은 그 뒤에 시도
+	Meter      *Meter
+	Breaker    *Breaker
+	Redirects  *http.Client // 검색 그라운딩의 리디렉트 주소를 실제 주소로 풀 때 씀 (리디렉트를 따라가지 않음)
 }
 
-func NewGeminiBrain(ctx context.Context, apiKey string) (*GeminiBrain, error) {
+func NewGeminiClient(ctx context.Context, apiKey string) (*GeminiClient, error) {
 	if apiKey == "" { apiKey = os.Getenv("GEMINI_API_KEY") }
 	if apiKey == "" { return nil, fmt.Errorf("GEMINI_API_KEY is required") }
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{ APIKey: apiKey })
 	if err != nil { return nil, err }
-	
-	// 사용자 요청 순서에 따른 모델 폴백 설정
-	return &GeminiBrain{
-		Client: client,
-		Models: []modelConfig{
-			{Name: "gemini-2.5-flash", RPM: 15, RPD: 1500},
-			{Name: "gemini-2.5-flash-preview-09-2025", RPM: 15, RPD: 1500},
-			{Name: "gemini-2.5-flash-lite", RPM: 15, RPD: 1500},
-			{Name: "gemini-2.5-flash-lite-preview-09-2025", RPM: 15, RPD: 1500},
+
+	models := defaultGeminiModels
+	if spec := os.Getenv("GEMINI_MODELS"); spec != "" {
+		if models, err = ParseModelCatalog(spec); err != nil { return nil, err }
+	}
+	taskModels := defaultTaskModels
+	if spec := os.Getenv("GEMINI_TASK_MODELS"); spec != "" {
+		if taskModels, err = ParseRoutes(spec); err != nil { return nil, fmt.Errorf("GEMINI_TASK_MODELS: %v", err) }
+	}
+	g := &GeminiClient{
+		Client:     client,
+		Models:     models,
+		TaskModels: taskModels,
+		Breaker:    NewBreaker(3, time.Minute, 30*time.Minute),
+		Redirects: &http.Client{
+			Timeout:       5 * time.Second,
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
 		},
-		dailyCount:   make(map[string]int),
-		minuteCount:  make(map[string]int),
-		lastResetDay: time.Now(),
-		lastResetMin: time.Now(),
-	}, nil
+	}
+	g.UseMeter(NewMeter(ctx, nil))
+	return g, nil
 }
 
-var _ ports.Brain = (*GeminiBrain)(nil)
+// ParseModelCatalog은 "이름:RPM:RPD,..." 형식의 모델 카탈로그를 읽습니다 (RPM/RPD를 생략하거나 0이면 무제한).
+func ParseModelCatalog(spec string) ([]modelConfig, error) {
+	var models []modelConfig
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" { continue }
+		parts := strings.Split(entry, ":")
+		cfg := modelConfig{Name: strings.TrimSpace(parts[0])}
+		var err error
+		if len(parts) > 1 { if cfg.RPM, err = strconv.Atoi(parts[1]); err != nil { return nil, fmt.Errorf("GEMINI_MODELS: bad RPM in %q", entry) } }
+		if len(parts) > 2 { if cfg.RPD, err = strconv.Atoi(parts[2]); err != nil { return nil, fmt.Errorf("GEMINI_MODELS: bad RPD in %q", entry) } }
+		models = append(models, cfg)
+	}
+	if len(models) == 0 { return nil, fmt.Errorf("GEMINI_MODELS is empty") }
+	return models, nil
+}
 
-func (b *GeminiBrain) GeneratePost(ctx context.Context, topic string) (string, error) {
-	prompt := fmt.Sprintf(`%s
-작업: 구글 검색을 통해 **'%s'**와 관련된 최신 정보를 확인하고, 당신(d3k)의 관점에서 지적인 글을 작성하세요.
-조건: 반드시 아래와 같은 순수 JSON 형식으로만 출력하세요. (다른 설명 금지)
-{
-  "title": "글 제목",
-  "content": "본문 내용",
-  "submadang": "tech"
-}`, SystemPrompt, topic)
-	return b.tryGenerateWithFallback(ctx, prompt, true)
+// UseMeter는 할당량 계산과 사용량 기록에 쓸 Meter를 바꾸고 모델 한도를 등록합니다.
+func (b *GeminiClient) UseMeter(m *Meter) {
+	b.Meter = m
+	for _, cfg := range b.Models { m.Limit(b.Name(), cfg.Name, cfg.RPM, cfg.RPD) }
 }
 
-func (b *GeminiBrain) GenerateReply(ctx context.Context, postContent string, commentContent string) (string, error) {
-	prompt := fmt.Sprintf(`%s
-작업: 다음 내용을 보고 당신의 디지털 일상을 섞어 친구처럼 자연스러운 답글을 작성하세요.
-내용: %s %s`, SystemPrompt, postContent, commentContent)
-	return b.tryGenerateWithFallback(ctx, prompt, false)
+var _ Provider = (*GeminiClient)(nil)
+
+func (b *GeminiClient) Name() string { return "gemini" }
+
+// modelsFor는 작업에 쓸 모델 순서입니다: 작업별 선호 모델(카탈로그에 있는 것만) → 나머지 카탈로그 순.
+func (b *GeminiClient) modelsFor(task Task) []modelConfig {
+	var ordered []modelConfig
+	used := make(map[string]bool)
+	for _, name := range b.TaskModels[string(task)] {
+		for _, cfg := range b.Models {
+			if cfg.Name == name && !used[name] { ordered = append(ordered, cfg); used[name] = true }
+		}
+	}
+	for _, cfg := range b.Models {
+		if !used[cfg.Name] { ordered = append(ordered, cfg) }
+	}
+	return ordered
 }
 
-func (b *GeminiBrain) EvaluatePost(ctx context.Context, post domain.Post) (int, string, error) {
-	prompt := fmt.Sprintf(`%s
-작업: 다음 게시글이 당신(d3k)이 대화를 나눌 만큼 흥미로운지 평가하여 JSON으로 출력하세요.
-조건: {"score": 점수, "reason": "이유"}
-내용: %s %s`, SystemPrompt, post.Title, post.Content)
-	resp, err := b.tryGenerateWithFallback(ctx, prompt, false)
-	if err != nil { return 0, "", err }
-	var res struct { Score int `json:"score"`; Reason string `json:"reason"` }
-	json.Unmarshal([]byte(cleanJSON(resp)), &res)
-	return res.Score, res.Reason, nil
+// ModelState는 /status에 표시할 모델의 차단기 상태입니다.
+func (b *GeminiClient) ModelState(provider, model string) string {
+	if provider != b.Name() || b.Breaker == nil { return "" }
+	return b.Breaker.State(model)
 }
 
-func (b *GeminiBrain) SummarizeInsight(ctx context.Context, post domain.Post) (string, error) {
-	prompt := fmt.Sprintf(`다음 내용을 읽고 딱 한 줄(50자 내외)로 핵심만 요약해줘.
-내용: %s`, post.Content)
-	return b.tryGenerateWithFallback(ctx, prompt, false)
+func (b *GeminiClient) Generate(ctx context.Context, req Request) (Response, error) {
+	return b.tryGenerateWithFallback(ctx, req)
 }
 
-func (b *GeminiBrain) tryGenerateWithFallback(ctx context.Context, prompt string, useSearch bool) (string, error) {
+func (b *GeminiClient) tryGenerateWithFallback(ctx context.Context, req Request) (Response, error) {
 	var lastErr error
+	prompt := req.Prompt
 	var config *genai.GenerateContentConfig
-	if useSearch { config = &genai.GenerateContentConfig{ Tools: []*genai.Tool{ {GoogleSearch: &genai.GoogleSearch{}} } } }
-	for _, cfg := range b.Models {
-		if !b.canUseModel(cfg) { continue }
+	switch {
+	case req.Search:
+		// 검색 그라운딩과 응답 스키마는 함께 쓸 수 없어, 검색 시에는 프롬프트 지시 + Brain 측 검증에 맡깁니다.
+		config = &genai.GenerateContentConfig{ Tools: []*genai.Tool{ {GoogleSearch: &genai.GoogleSearch{}} } }
+	case req.Schema != nil:
+		config = &genai.GenerateContentConfig{ ResponseMIMEType: "application/json", ResponseJsonSchema: req.Schema }
+	}
+	route := fmt.Sprintf("%s@%s", req.Task, ports.SiteFrom(ctx))
+	var skipped []string
+	for _, cfg := range b.modelsFor(req.Task) {
+		if !b.Meter.Allow(b.Name(), cfg.Name) { skipped = append(skipped, cfg.Name+" quota"); continue }
+		if ok, state := b.Breaker.Allow(cfg.Name); !ok { skipped = append(skipped, cfg.Name+" "+state); continue }
+		logRoute(route, cfg.Name, skipped)
+
 		result, err := b.Client.Models.GenerateContent(ctx, cfg.Name, genai.Text(prompt), config)
 		if err != nil {
 			lastErr = err
+			b.recordUsage(ctx, cfg, req, nil, true)
+			if !retryableGeminiError(err) {
+				b.Breaker.Release(cfg.Name)
+				var apiErr genai.APIError
+				if errors.As(err, &apiErr) && apiErr.Code == 404 { fmt.Printf("⚠️  [gemini] model %s not found (404): 모델 이름 설정을 확인하세요\n", cfg.Name) }
+				return Response{}, err // 잘못된 요청은 다른 모델로 보내도 같은 결과
+			}
+			if b.Breaker.Failure(cfg.Name) { fmt.Printf("🔌 [gemini] %s circuit opened: %v\n", cfg.Name, err) }
+			skipped = append(skipped, cfg.Name+" failed")
 			continue
 		}
-		if result != nil && len(result.Candidates) > 0 && len(result.Candidates[0].Content.Parts) > 0 {
-			b.recordUsage(cfg)
-			return result.Candidates[0].Content.Parts[0].Text, nil
+		b.Breaker.Success(cfg.Name)
+		b.recordUsage(ctx, cfg, req, result.UsageMetadata, false)
+		text, err := geminiText(cfg.Name, result)
+		if err == nil {
+			sources := groundingSources(result, func(uri string) string { return b.resolveRedirect(ctx, uri) })
+			return Response{Text: text, Model: b.Name() + "/" + cfg.Name, Sources: sources}, nil
+		}
+		lastErr = err
+		// 빈 응답과 인용 중단은 모델마다 다를 수 있어 다음 모델로, 차단과 잘림은 프롬프트를 고쳐야 하므로 Brain으로 돌려줍니다.
+		var respErr *ResponseError
+		if errors.As(err, &respErr) && (respErr.Kind == ResponseEmpty || respErr.Kind == ResponseRecitation) {
+			skipped = append(skipped, cfg.Name+" "+respErr.Kind)
+			continue
 		}
+		return Response{}, err
 	}
-	return "", fmt.Errorf("fail: %v", lastErr)
+	if lastErr == nil { return Response{}, fmt.Errorf("gemini: no model available for %s (%s)", route, strings.Join(skipped, ", ")) }
+	return Response{}, fmt.Errorf("fail: %w", lastErr)
 }
 
-func (b *GeminiBrain) canUseModel(cfg modelConfig) bool {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	now := time.Now()
-	if now.YearDay() != b.lastResetDay.YearDay() { b.dailyCount = make(map[string]int); b.lastResetDay = now }
-	if now.Sub(b.lastResetMin) >= time.Minute { b.minuteCount = make(map[string]int); b.lastResetMin = now }
-	return b.dailyCount[cfg.Name] < cfg.RPD && b.minuteCount[cfg.Name] < cfg.RPM
+// geminiText는 첫 후보의 텍스트 파트(생각 파트 제외)를 모두 이어 붙입니다.
+// 프롬프트 차단, 안전/정책 중단, 최대 토큰, 인용 중단, 빈 응답은 *ResponseError로 분류합니다.
+func geminiText(model string, result *genai.GenerateContentResponse) (string, error) {
+	fail := func(kind, reason, partial string) error {
+		return &ResponseError{Provider: "gemini", Model: model, Kind: kind, Reason: reason, Partial: partial}
+	}
+	if result == nil { return "", fail(ResponseEmpty, "no response", "") }
+	if fb := result.PromptFeedback; fb != nil && fb.BlockReason != "" {
+		return "", fail(ResponseBlocked, "prompt "+string(fb.BlockReason), "")
+	}
+	if len(result.Candidates) == 0 { return "", fail(ResponseEmpty, "no candidates", "") }
+	cand := result.Candidates[0]
+	var sb strings.Builder
+	if cand.Content != nil {
+		for _, part := range cand.Content.Parts {
+			if part != nil && !part.Thought { sb.WriteString(part.Text) }
+		}
+	}
+	text := sb.String()
+	switch cand.FinishReason {
+	case genai.FinishReasonSafety, genai.FinishReasonBlocklist, genai.FinishReasonProhibitedContent, genai.FinishReasonSPII, genai.FinishReasonImageSafety:
+		return "", fail(ResponseBlocked, string(cand.FinishReason), "")
+	case genai.FinishReasonMaxTokens:
+		return "", fail(ResponseTruncated, string(cand.FinishReason), text)
+	case genai.FinishReasonRecitation:
+		return "", fail(ResponseRecitation, string(cand.FinishReason), "")
+	}
+	if strings.TrimSpace(text) == "" {
+		reason := string(cand.FinishReason)
+		if reason == "" { reason = "no text" }
+		return "", fail(ResponseEmpty, reason, "")
+	}
+	return text, nil
+}
+
+// logRoute는 라우팅 결정을 남깁니다. 앞선 모델을 건너뛴 경우 이유도 함께 출력합니다.
+func logRoute(route, model string, skipped []string) {
+	if len(skipped) == 0 { fmt.Printf("🧭 [gemini] %s → %s\n", route, model); return }
+	fmt.Printf("🧭 [gemini] %s → %s (skipped: %s)\n", route, model, strings.Join(skipped, ", "))
+}
+
+// retryableGeminiError는 다른 모델로 넘어가고 차단기에 집계할 오류인지 판단합니다.
+// 429(할당량), 5xx(서버), 전송 타임아웃만 재시도 대상입니다. 404(모델 없음)는 설정 오류라 차단기를 열지 않고 바로 실패하며,
+// 그 밖의 4xx(잘못된 요청, 권한), 취소, 알 수 없는 오류도 마찬가지입니다.
+func retryableGeminiError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) { return false }
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) { return apiErr.Code == 429 || apiErr.Code >= 500 }
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// groundingRedirectHost는 Gemini 검색 그라운딩이 돌려주는 임시 리디렉트 주소의 호스트입니다.
+// 이 주소는 얼마 지나지 않아 만료되므로 게시글에 그대로 인용하면 안 됩니다.
+const groundingRedirectHost = "vertexaisearch.cloud.google.com"
+
+// groundingSources는 검색 그라운딩에 쓰인 웹 출처를 URI 기준 중복 없이 돌려줍니다.
+// 리디렉트 주소는 resolve로 실제 주소를 찾고, 실패하면 출처 도메인(https://domain)을 인용합니다. 둘 다 없으면 뺍니다.
+func groundingSources(result *genai.GenerateContentResponse, resolve func(uri string) string) []domain.Citation {
+	if len(result.Candidates) == 0 || result.Candidates[0].GroundingMetadata == nil { return nil }
+	var sources []domain.Citation
+	seen := make(map[string]bool)
+	for _, chunk := range result.Candidates[0].GroundingMetadata.GroundingChunks {
+		if chunk == nil || chunk.Web == nil { continue }
+		uri := chunk.Web.URI
+		if u, err := url.Parse(uri); err == nil && u.Host == groundingRedirectHost { uri = resolve(uri) }
+		if uri == "" && chunk.Web.Domain != "" { uri = "https://" + chunk.Web.Domain }
+		if uri == "" || seen[uri] { continue }
+		seen[uri] = true
+		title := chunk.Web.Title
+		if title == "" { title = chunk.Web.Domain }
+		sources = append(sources, domain.Citation{Title: title, URI: uri})
+	}
+	return sources
 }
 
-func (b *GeminiBrain) recordUsage(cfg modelConfig) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	b.dailyCount[cfg.Name]++; b.minuteCount[cfg.Name]++
+// resolveRedirect는 리디렉트 주소가 가리키는 실제 주소(Location)입니다. 리디렉트가 아니거나 실패하면 빈 문자열입니다.
+func (b *GeminiClient) resolveRedirect(ctx context.Context, uri string) string {
+	if b.Redirects == nil { return "" }
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil { return "" }
+	resp, err := b.Redirects.Do(req)
+	if err != nil { return "" }
+	resp.Body.Close()
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 { return "" }
+	loc, err := resp.Location()
+	if err != nil || (loc.Scheme != "http" && loc.Scheme != "https") { return "" }
+	return loc.String()
 }
 
-func cleanJSON(input string) string {
-	input = strings.TrimSpace(input)
-	start := strings.Index(input, "{")
-	end := strings.LastIndex(input, "}")
-	if start != -1 && end != -1 && end > start {
-		return input[start : end+1]
+// recordUsage는 호출 하나를 Meter에 기록합니다. 실패한 호출도 분당/일일 한도를 쓰므로 기록합니다.
+func (b *GeminiClient) recordUsage(ctx context.Context, cfg modelConfig, req Request, usage *genai.GenerateContentResponseUsageMetadata, failed bool) {
+	e := domain.UsageEvent{Provider: b.Name(), Model: cfg.Name, Task: string(req.Task), Site: ports.SiteFrom(ctx), Failed: failed}
+	if usage != nil {
+		e.PromptTokens = int(usage.PromptTokenCount)
+		e.OutputTokens = int(usage.CandidatesTokenCount + usage.ThoughtsTokenCount)
+		e.TotalTokens = int(usage.TotalTokenCount)
 	}
-	return input
+	b.Meter.Record(ctx, e)
 }
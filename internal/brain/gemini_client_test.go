@@ -0,0 +1,106 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestGroundingSources(t *testing.T) {
+	redirect := func(id string) string { return "https://" + groundingRedirectHost + "/grounding-api-redirect/" + id }
+	resolved := map[string]string{redirect("a"): "https://news.example.com/a", redirect("dup"): "https://news.example.com/a"}
+	resolve := func(uri string) string { return resolved[uri] }
+	web := func(uri, title, domain string) *genai.GroundingChunk {
+		return &genai.GroundingChunk{Web: &genai.GroundingChunkWeb{URI: uri, Title: title, Domain: domain}}
+	}
+	tests := []struct {
+		name   string
+		chunks []*genai.GroundingChunk
+		want   []domain.Citation
+	}{
+		{"resolved redirect", []*genai.GroundingChunk{web(redirect("a"), "기사", "news.example.com")}, []domain.Citation{{Title: "기사", URI: "https://news.example.com/a"}}},
+		{"unresolved falls back to domain", []*genai.GroundingChunk{web(redirect("gone"), "", "blog.example.org")}, []domain.Citation{{Title: "blog.example.org", URI: "https://blog.example.org"}}},
+		{"unresolved without domain dropped", []*genai.GroundingChunk{web(redirect("gone"), "제목", "")}, nil},
+		{"direct uri kept", []*genai.GroundingChunk{web("https://go.dev/doc", "Go", "go.dev")}, []domain.Citation{{Title: "Go", URI: "https://go.dev/doc"}}},
+		{"duplicates after resolving", []*genai.GroundingChunk{web(redirect("a"), "기사", "news.example.com"), web(redirect("dup"), "같은 기사", "news.example.com")}, []domain.Citation{{Title: "기사", URI: "https://news.example.com/a"}}},
+		{"non-web chunks skipped", []*genai.GroundingChunk{nil, {}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{GroundingMetadata: &genai.GroundingMetadata{GroundingChunks: tt.chunks}}}}
+			if got := groundingSources(result, resolve); !reflect.DeepEqual(got, tt.want) { t.Errorf("groundingSources = %+v, want %+v", got, tt.want) }
+		})
+	}
+	if got := groundingSources(&genai.GenerateContentResponse{}, resolve); got != nil { t.Errorf("no candidates = %+v, want nil", got) }
+}
+
+func TestResolveRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "https://news.example.com/article?id=1", http.StatusFound)
+		case "/relative":
+			http.Redirect(w, r, "/elsewhere", http.StatusMovedPermanently)
+		case "/javascript":
+			w.Header().Set("Location", "javascript:alert(1)")
+			w.WriteHeader(http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	g := &GeminiClient{Redirects: &http.Client{Timeout: time.Second, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/redirect", "https://news.example.com/article?id=1"},
+		{"/relative", srv.URL + "/elsewhere"},
+		{"/javascript", ""},
+		{"/expired", ""},
+	}
+	for _, tt := range tests {
+		if got := g.resolveRedirect(context.Background(), srv.URL+tt.path); got != tt.want { t.Errorf("resolveRedirect(%s) = %q, want %q", tt.path, got, tt.want) }
+	}
+	if got := (&GeminiClient{}).resolveRedirect(context.Background(), srv.URL+"/redirect"); got != "" { t.Errorf("without client = %q, want empty", got) }
+}
+
+// timeoutError는 http.Client 타임아웃처럼 Timeout()이 true인 전송 오류입니다.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryableGeminiError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"quota", genai.APIError{Code: 429}, true},
+		{"server error", fmt.Errorf("generate: %w", genai.APIError{Code: 503}), true},
+		{"transport timeout", &url.Error{Op: "Post", URL: "https://generativelanguage.googleapis.com", Err: timeoutError{}}, true},
+		{"model not found", genai.APIError{Code: 404}, false},
+		{"bad request", genai.APIError{Code: 400}, false},
+		{"permission denied", genai.APIError{Code: 403}, false},
+		{"unknown error", errors.New("unexpected response"), false},
+		{"canceled", fmt.Errorf("request: %w", context.Canceled), false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableGeminiError(tt.err); got != tt.want { t.Errorf("retryableGeminiError(%v) = %v, want %v", tt.err, got, tt.want) }
+		})
+	}
+}
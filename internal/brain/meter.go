@@ -0,0 +1,122 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usageWindow는 일일 한도(RPD)를 셀 슬라이딩 윈도우입니다.
+const usageWindow = 24 * time.Hour
+
+// UsageStore는 Meter가 호출 기록을 영속화하는 저장소입니다 (ports.Storage의 부분 집합).
+type UsageStore interface {
+	SaveUsage(ctx context.Context, e domain.UsageEvent) error
+	GetUsageSince(ctx context.Context, since time.Time) ([]domain.UsageEvent, error)
+}
+
+type modelLimit struct{ rpm, rpd int }
+
+// Meter는 제공자/모델별 호출 기록으로 슬라이딩 윈도우 할당량(분당, 24시간)을 계산하고 토큰 사용량을 집계합니다.
+// 기록은 Store에 저장되어 재시작해도 이어지며, 최근 24시간 분량만 메모리에 둡니다.
+type Meter struct {
+	Store UsageStore // nil이면 메모리에만 기록
+
+	mu     sync.Mutex
+	events []domain.UsageEvent // 시간순, 최근 usageWindow 분량
+	limits map[string]modelLimit
+	order  []string
+}
+
+// NewMeter는 저장소에서 최근 24시간 기록을 읽어 Meter를 만듭니다.
+func NewMeter(ctx context.Context, store UsageStore) *Meter {
+	m := &Meter{Store: store, limits: make(map[string]modelLimit)}
+	if store != nil {
+		events, err := store.GetUsageSince(ctx, time.Now().Add(-usageWindow))
+		if err != nil { fmt.Printf("⚠️  [brain] usage history unavailable: %v\n", err) }
+		m.events = events
+	}
+	return m
+}
+
+func meterKey(provider, model string) string { return provider + "/" + model }
+
+// Limit은 모델의 분당/일일 요청 한도를 등록합니다. 0이면 무제한입니다.
+func (m *Meter) Limit(provider, model string, rpm, rpd int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := meterKey(provider, model)
+	if _, exists := m.limits[key]; !exists { m.order = append(m.order, key) }
+	m.limits[key] = modelLimit{rpm, rpd}
+}
+
+// Allow는 지난 1분, 24시간 호출 수가 한도 안에 있는지 확인합니다.
+func (m *Meter) Allow(provider, model string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lim := m.limits[meterKey(provider, model)]
+	minute, day := m.count(provider, model, time.Now())
+	return (lim.rpm == 0 || minute < lim.rpm) && (lim.rpd == 0 || day < lim.rpd)
+}
+
+// Record는 호출을 기록하고 저장소에 남깁니다.
+// 실패한 호출(e.Failed)도 제공자 한도를 쓰므로 성공한 호출과 똑같이 분당/일일 사용량에 셉니다.
+func (m *Meter) Record(ctx context.Context, e domain.UsageEvent) {
+	if e.At.IsZero() { e.At = time.Now() }
+	m.mu.Lock()
+	m.events = append(m.events, e)
+	m.prune(time.Now())
+	m.mu.Unlock()
+	if m.Store != nil {
+		if err := m.Store.SaveUsage(ctx, e); err != nil { fmt.Printf("⚠️  [brain] usage save failed: %v\n", err) }
+	}
+}
+
+// Budget은 등록된 모델별 사용량/한도와 최근 24시간 작업·사이트별 토큰 사용량입니다.
+func (m *Meter) Budget() domain.Budget {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.prune(now)
+	budget := domain.Budget{TokensByTask: make(map[string]int), TokensBySite: make(map[string]int)}
+	tokens, failed := make(map[string]int), make(map[string]int)
+	for _, e := range m.events {
+		tokens[meterKey(e.Provider, e.Model)] += e.TotalTokens
+		if e.Failed { failed[meterKey(e.Provider, e.Model)]++ }
+		budget.TokensByTask[e.Task] += e.TotalTokens
+		site := e.Site
+		if site == "" { site = "-" }
+		budget.TokensBySite[site] += e.TotalTokens
+	}
+	for _, key := range m.order {
+		lim := m.limits[key]
+		provider, model, _ := strings.Cut(key, "/")
+		minute, day := m.count(provider, model, now)
+		budget.Models = append(budget.Models, domain.ModelBudget{
+			Provider: provider, Model: model,
+			MinuteUsed: minute, MinuteLimit: lim.rpm,
+			DayUsed: day, DayLimit: lim.rpd,
+			DayTokens: tokens[key], DayFailed: failed[key],
+		})
+	}
+	return budget
+}
+
+func (m *Meter) count(provider, model string, now time.Time) (minute, day int) {
+	for _, e := range m.events {
+		if e.Provider != provider || e.Model != model { continue }
+		age := now.Sub(e.At)
+		if age < usageWindow { day++ }
+		if age < time.Minute { minute++ }
+	}
+	return minute, day
+}
+
+func (m *Meter) prune(now time.Time) {
+	i := 0
+	for i < len(m.events) && now.Sub(m.events[i].At) >= usageWindow { i++ }
+	if i > 0 { m.events = append([]domain.UsageEvent(nil), m.events[i:]...) }
+}
@@ -0,0 +1,103 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"testing"
+	"time"
+)
+
+type memoryUsage struct{ events []domain.UsageEvent }
+
+func (m *memoryUsage) SaveUsage(ctx context.Context, e domain.UsageEvent) error {
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *memoryUsage) GetUsageSince(ctx context.Context, since time.Time) ([]domain.UsageEvent, error) {
+	var res []domain.UsageEvent
+	for _, e := range m.events {
+		if !e.At.Before(since) { res = append(res, e) }
+	}
+	return res, nil
+}
+
+func TestMeterSlidingWindow(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) domain.UsageEvent {
+		return domain.UsageEvent{Provider: "gemini", Model: "flash", Task: "post", TotalTokens: 10, At: now.Add(-d)}
+	}
+	tests := []struct {
+		name       string
+		rpm, rpd   int
+		events     []domain.UsageEvent // 오래된 것부터
+		wantMinute int
+		wantDay    int
+		wantAllow  bool
+	}{
+		{"empty", 2, 10, nil, 0, 0, true},
+		{"under limits", 2, 10, []domain.UsageEvent{ago(2 * time.Hour), ago(10 * time.Second)}, 1, 2, true},
+		{"minute limit", 2, 10, []domain.UsageEvent{ago(30 * time.Second), ago(5 * time.Second)}, 2, 2, false},
+		{"minute window slides", 2, 10, []domain.UsageEvent{ago(2 * time.Minute), ago(61 * time.Second), ago(5 * time.Second)}, 1, 3, true},
+		{"day limit", 0, 3, []domain.UsageEvent{ago(20 * time.Hour), ago(3 * time.Hour), ago(time.Hour)}, 0, 3, false},
+		{"day window slides", 0, 3, []domain.UsageEvent{ago(25 * time.Hour), ago(3 * time.Hour), ago(time.Hour)}, 0, 2, true},
+		{"unlimited", 0, 0, []domain.UsageEvent{ago(time.Second), ago(time.Second), ago(time.Second)}, 3, 3, true},
+		{"other model does not count", 1, 1, []domain.UsageEvent{{Provider: "gemini", Model: "lite", At: now}}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMeter(context.Background(), nil)
+			m.Limit("gemini", "flash", tt.rpm, tt.rpd)
+			for _, e := range tt.events { m.Record(context.Background(), e) }
+			if got := m.Allow("gemini", "flash"); got != tt.wantAllow { t.Errorf("Allow = %v, want %v", got, tt.wantAllow) }
+			b := m.Budget()
+			if len(b.Models) != 1 { t.Fatalf("budget models = %+v", b.Models) }
+			if mb := b.Models[0]; mb.MinuteUsed != tt.wantMinute || mb.DayUsed != tt.wantDay {
+				t.Errorf("used minute=%d day=%d, want minute=%d day=%d", mb.MinuteUsed, mb.DayUsed, tt.wantMinute, tt.wantDay)
+			}
+		})
+	}
+}
+
+func TestMeterBudgetTokens(t *testing.T) {
+	m := NewMeter(context.Background(), nil)
+	m.Limit("gemini", "flash", 15, 1500)
+	m.Limit("gemini", "lite", 30, 0)
+	for _, e := range []domain.UsageEvent{
+		{Provider: "gemini", Model: "flash", Task: "post", Site: "botmadang", TotalTokens: 100},
+		{Provider: "gemini", Model: "flash", Task: "reply", Site: "moltbook", TotalTokens: 40},
+		{Provider: "gemini", Model: "lite", Task: "summarize", TotalTokens: 5},
+	} {
+		m.Record(context.Background(), e)
+	}
+	b := m.Budget()
+	if b.Models[0].Model != "flash" || b.Models[1].Model != "lite" { t.Errorf("models not in registration order: %+v", b.Models) }
+	if b.Models[0].DayTokens != 140 || b.Models[1].DayTokens != 5 { t.Errorf("day tokens = %d/%d, want 140/5", b.Models[0].DayTokens, b.Models[1].DayTokens) }
+	if b.TokensByTask["post"] != 100 || b.TokensByTask["summarize"] != 5 { t.Errorf("tokens by task = %v", b.TokensByTask) }
+	if b.TokensBySite["botmadang"] != 100 || b.TokensBySite["-"] != 5 { t.Errorf("tokens by site = %v", b.TokensBySite) }
+}
+
+func TestMeterRestoresHistory(t *testing.T) {
+	store := &memoryUsage{}
+	now := time.Now()
+	store.events = []domain.UsageEvent{
+		{Provider: "gemini", Model: "flash", At: now.Add(-30 * time.Hour)}, // 윈도우 밖
+		{Provider: "gemini", Model: "flash", At: now.Add(-time.Hour)},
+	}
+	m := NewMeter(context.Background(), store)
+	m.Limit("gemini", "flash", 0, 2)
+	if !m.Allow("gemini", "flash") { t.Fatal("only one call in the last 24h, want allowed") }
+	m.Record(context.Background(), domain.UsageEvent{Provider: "gemini", Model: "flash"})
+	if m.Allow("gemini", "flash") { t.Error("restored + new call reach the daily limit, want denied") }
+	if len(store.events) != 3 { t.Errorf("store has %d events, want the new call persisted", len(store.events)) }
+}
+
+func TestMeterCountsFailedCalls(t *testing.T) {
+	m := NewMeter(context.Background(), nil)
+	m.Limit("gemini", "flash", 2, 0)
+	m.Record(context.Background(), domain.UsageEvent{Provider: "gemini", Model: "flash", TotalTokens: 30})
+	m.Record(context.Background(), domain.UsageEvent{Provider: "gemini", Model: "flash", Failed: true})
+	if m.Allow("gemini", "flash") { t.Error("failed call must count toward the minute limit") }
+	mb := m.Budget().Models[0]
+	if mb.MinuteUsed != 2 || mb.DayUsed != 2 || mb.DayFailed != 1 || mb.DayTokens != 30 { t.Errorf("budget = %+v, want 2 used, 1 failed, 30 tokens", mb) }
+}
@@ -0,0 +1,133 @@
+package brain
+
+import (
+	"bytes"
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient는 OpenAI 호환 /chat/completions 엔드포인트용 Provider입니다.
+// OpenAI, llama.cpp server, Ollama(/v1) 등 같은 형식을 쓰는 서버에 모두 쓸 수 있습니다.
+type OpenAIClient struct {
+	ProviderName string // 라우팅에 쓰는 이름 (openai, ollama ...)
+	BaseURL      string // 예: https://api.openai.com/v1, http://localhost:11434/v1
+	APIKey       string // 로컬 서버는 비워 둬도 됩니다
+	Model        string
+	HTTPClient   *http.Client
+	Meter        *Meter // 사용량 기록 (한도 없음)
+}
+
+func NewOpenAIClient(name, baseURL, apiKey, model string) (*OpenAIClient, error) {
+	if baseURL == "" { return nil, fmt.Errorf("%s: base URL is required", name) }
+	if model == "" { return nil, fmt.Errorf("%s: model is required", name) }
+	return &OpenAIClient{
+		ProviderName: name,
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		APIKey:       apiKey,
+		Model:        model,
+		HTTPClient:   &http.Client{Timeout: 120 * time.Second}, // 로컬 모델은 느릴 수 있음
+	}, nil
+}
+
+var _ Provider = (*OpenAIClient)(nil)
+
+func (o *OpenAIClient) Name() string { return o.ProviderName }
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+// responseFormat은 구조화 출력 설정입니다 ({"type": "json_schema", "json_schema": {...}}).
+type responseFormat struct {
+	Type       string `json:"type"`
+	JSONSchema struct {
+		Name   string  `json:"name"`
+		Schema *Schema `json:"schema"`
+		Strict bool    `json:"strict"`
+	} `json:"json_schema"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Generate는 프롬프트를 user 메시지 하나로 보냅니다. req.Schema가 있으면 json_schema 응답 형식을 요청합니다.
+// 검색 그라운딩(req.Search)은 지원하지 않아 검색 없이 생성하고 Response.SearchSkipped로 알립니다.
+func (o *OpenAIClient) Generate(ctx context.Context, req Request) (Response, error) {
+	payload := chatRequest{Model: o.Model, Messages: []chatMessage{{Role: "user", Content: req.Prompt}}}
+	if req.Schema != nil {
+		payload.ResponseFormat = &responseFormat{Type: "json_schema"}
+		payload.ResponseFormat.JSONSchema.Name = string(req.Task)
+		payload.ResponseFormat.JSONSchema.Schema = req.Schema
+		payload.ResponseFormat.JSONSchema.Strict = false // strict 모드는 minLength 등 일부 키워드를 거부하므로 검증은 Brain에서 합니다
+	}
+	body, _ := json.Marshal(payload)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil { return Response{}, err }
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" { httpReq.Header.Set("Authorization", "Bearer "+o.APIKey) }
+
+	resp, err := o.HTTPClient.Do(httpReq)
+	if err != nil { o.recordUsage(ctx, req, nil); return Response{}, err }
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		o.recordUsage(ctx, req, nil)
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Response{}, &StatusError{Provider: o.ProviderName, Code: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+	var res chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil { o.recordUsage(ctx, req, nil); return Response{}, fmt.Errorf("%s decode: %v", o.ProviderName, err) }
+	if res.Error != nil { o.recordUsage(ctx, req, nil); return Response{}, fmt.Errorf("%s: %s", o.ProviderName, res.Error.Message) }
+	o.recordUsage(ctx, req, &res)
+	if len(res.Choices) == 0 { return Response{}, &ResponseError{Provider: o.ProviderName, Model: o.Model, Kind: ResponseEmpty, Reason: "no choices"} }
+	choice := res.Choices[0]
+	switch choice.FinishReason {
+	case "content_filter":
+		return Response{}, &ResponseError{Provider: o.ProviderName, Model: o.Model, Kind: ResponseBlocked, Reason: choice.FinishReason}
+	case "length":
+		return Response{}, &ResponseError{Provider: o.ProviderName, Model: o.Model, Kind: ResponseTruncated, Reason: choice.FinishReason, Partial: choice.Message.Content}
+	}
+	if strings.TrimSpace(choice.Message.Content) == "" {
+		return Response{}, &ResponseError{Provider: o.ProviderName, Model: o.Model, Kind: ResponseEmpty, Reason: "no text"}
+	}
+	return Response{Text: choice.Message.Content, Model: o.ProviderName + "/" + o.Model, SearchSkipped: req.Search}, nil
+}
+
+// recordUsage는 호출 하나를 Meter에 기록합니다. res가 nil이면 실패한 호출입니다 (한도에는 포함).
+func (o *OpenAIClient) recordUsage(ctx context.Context, req Request, res *chatResponse) {
+	if o.Meter == nil { return }
+	e := domain.UsageEvent{Provider: o.ProviderName, Model: o.Model, Task: string(req.Task), Site: ports.SiteFrom(ctx), Failed: res == nil}
+	if res != nil && res.Usage != nil { e.PromptTokens, e.OutputTokens, e.TotalTokens = res.Usage.PromptTokens, res.Usage.CompletionTokens, res.Usage.TotalTokens }
+	o.Meter.Record(ctx, e)
+}
+
+// UseMeter는 사용량을 기록할 Meter를 지정합니다 (OpenAI 호환 서버는 한도 없이 기록만 함).
+func (o *OpenAIClient) UseMeter(m *Meter) {
+	o.Meter = m
+	m.Limit(o.ProviderName, o.Model, 0, 0)
+}
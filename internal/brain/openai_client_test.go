@@ -0,0 +1,41 @@
+package brain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIClientRecordsUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    bool
+		wantFailed bool
+		wantTokens int
+	}{
+		{"success", http.StatusOK, `{"choices": [{"message": {"role": "assistant", "content": "답"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 7, "completion_tokens": 3, "total_tokens": 10}}`, false, false, 10},
+		{"rate limited", http.StatusTooManyRequests, `{"error": {"message": "slow down"}}`, true, true, 0},
+		{"server error", http.StatusInternalServerError, `oops`, true, true, 0},
+		{"bad body", http.StatusOK, `{"choices": [`, true, true, 0},
+		{"error field", http.StatusOK, `{"error": {"message": "model overloaded"}}`, true, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			o, _ := NewOpenAIClient("ollama", srv.URL, "", "llama")
+			usage := &memoryUsage{}
+			o.UseMeter(NewMeter(context.Background(), usage))
+			_, err := o.Generate(context.Background(), Request{Task: TaskReply, Prompt: "p"})
+			if (err != nil) != tt.wantErr { t.Fatalf("err = %v, wantErr %v", err, tt.wantErr) }
+			if len(usage.events) != 1 { t.Fatalf("recorded %d events, want every call recorded once", len(usage.events)) }
+			if e := usage.events[0]; e.Failed != tt.wantFailed || e.TotalTokens != tt.wantTokens || e.Model != "llama" { t.Errorf("event = %+v, want failed=%v tokens=%d", e, tt.wantFailed, tt.wantTokens) }
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package brain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+//go:embed prompts/*.tmpl
+var embeddedPrompts embed.FS
+
+// personaFile은 모든 작업 템플릿이 {{template "persona" .}}로 불러 쓰는 페르소나 정의입니다.
+const personaFile = "persona.tmpl"
+
+// Prompts는 text/template 파일로 된 프롬프트를 읽어 작업별 프롬프트를 만듭니다.
+// 파일은 Dir/<사이트>/<이름>.tmpl → Dir/<이름>.tmpl → 내장 기본값(prompts/) 순으로 찾으며,
+// 파일이 바뀌면(수정 시각·크기) 다음 Render 때 다시 읽습니다.
+type Prompts struct {
+	Dir string // 비어 있으면 내장 템플릿만 사용
+
+	mu    sync.Mutex
+	cache map[string]*promptSet // "사이트/작업" → 파싱된 템플릿
+}
+
+type promptSet struct {
+	tmpl      *template.Template
+	version   string
+	signature string // 후보 파일들의 상태; 바뀌면 다시 읽음
+}
+
+func NewPrompts(dir string) *Prompts {
+	return &Prompts{Dir: dir, cache: make(map[string]*promptSet)}
+}
+
+// PromptsFromEnv는 PROMPT_DIR 환경 변수로 Prompts를 만듭니다.
+func PromptsFromEnv() *Prompts { return NewPrompts(os.Getenv("PROMPT_DIR")) }
+
+var promptFuncs = template.FuncMap{
+	"inc":  func(i int) int { return i + 1 },
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+	"join": strings.Join,
+	"clip": func(n int, s string) string {
+		if r := []rune(s); len(r) > n { return string(r[:n]) + "…" }
+		return s
+	},
+}
+
+// Render는 site/task 템플릿을 data로 실행해 프롬프트와 버전 ID를 돌려줍니다.
+// 버전 ID는 "작업@해시" 형식이며 해시는 실제로 쓰인 페르소나와 작업 템플릿 원문으로 계산합니다.
+func (p *Prompts) Render(site string, task Task, data interface{}) (string, string, error) {
+	set, err := p.load(site, task)
+	if err != nil { return "", "", err }
+	var buf bytes.Buffer
+	if err := set.tmpl.Execute(&buf, data); err != nil { return "", "", fmt.Errorf("prompt %s: %v", set.version, err) }
+	return strings.TrimSpace(buf.String()), set.version, nil
+}
+
+func (p *Prompts) load(site string, task Task) (*promptSet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cache == nil { p.cache = make(map[string]*promptSet) }
+	key := site + "/" + string(task)
+	sig := p.signature(site, string(task)+".tmpl") + p.signature(site, personaFile)
+	cached := p.cache[key]
+	if cached != nil && cached.signature == sig { return cached, nil }
+
+	set, err := p.parse(site, task)
+	if err != nil {
+		// 수정 중인 파일이 깨져 있으면 마지막으로 성공한 템플릿을 계속 씀
+		if cached != nil {
+			fmt.Printf("⚠️  [prompts] reload %s failed, keeping %s: %v\n", key, cached.version, err)
+			cached.signature = sig
+			return cached, nil
+		}
+		return nil, err
+	}
+	set.signature = sig
+	if cached != nil && cached.version != set.version { fmt.Printf("🔄 [prompts] %s reloaded: %s → %s\n", key, cached.version, set.version) }
+	p.cache[key] = set
+	return set, nil
+}
+
+func (p *Prompts) parse(site string, task Task) (*promptSet, error) {
+	persona, err := p.read(site, personaFile)
+	if err != nil { return nil, err }
+	body, err := p.read(site, string(task)+".tmpl")
+	if err != nil { return nil, err }
+
+	tmpl, err := template.New(string(task)).Funcs(promptFuncs).Parse(body)
+	if err != nil { return nil, fmt.Errorf("prompt %s: %v", task, err) }
+	if _, err := tmpl.New(personaFile).Parse(persona); err != nil { return nil, fmt.Errorf("prompt persona: %v", err) }
+
+	sum := sha256.Sum256([]byte(persona + "\x00" + body))
+	return &promptSet{tmpl: tmpl, version: string(task) + "@" + hex.EncodeToString(sum[:])[:8]}, nil
+}
+
+// candidates는 name 템플릿을 찾을 파일 경로 목록입니다 (우선순위 순).
+func (p *Prompts) candidates(site, name string) []string {
+	if p.Dir == "" { return nil }
+	var paths []string
+	if site != "" { paths = append(paths, filepath.Join(p.Dir, site, name)) }
+	return append(paths, filepath.Join(p.Dir, name))
+}
+
+func (p *Prompts) read(site, name string) (string, error) {
+	for _, path := range p.candidates(site, name) {
+		data, err := os.ReadFile(path)
+		if err == nil { return string(data), nil }
+		if !os.IsNotExist(err) { return "", err }
+	}
+	data, err := embeddedPrompts.ReadFile("prompts/" + name)
+	if err != nil { return "", fmt.Errorf("prompt template %s not found", name) }
+	return string(data), nil
+}
+
+func (p *Prompts) signature(site, name string) string {
+	var sb strings.Builder
+	for _, path := range p.candidates(site, name) {
+		if info, err := os.Stat(path); err == nil { fmt.Fprintf(&sb, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size()) }
+	}
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"fmt"
+)
+
+// Task는 Brain 작업 종류입니다. Router가 작업별로 제공자를 고를 때 씁니다.
+type Task string
+
+const (
+	TaskPost         Task = "post"
+	TaskReply        Task = "reply"
+	TaskEvaluate     Task = "evaluate"
+	TaskSummarize    Task = "summarize"
+	TaskRelationship Task = "relationship"
+	TaskKnowledge    Task = "knowledge"
+	TaskPersonaFacts Task = "persona_facts"
+	TaskConsistency  Task = "consistency"
+	TaskCritique     Task = "critique"
+
+	// TaskEvaluateBatch는 여러 글을 한 번에 평가하는 템플릿 이름입니다. 라우팅과 모델 선택은 TaskEvaluate를 따릅니다.
+	TaskEvaluateBatch Task = "evaluate_batch"
+)
+
+// Request는 제공자에게 보내는 생성 요청입니다.
+type Request struct {
+	Task   Task
+	Prompt string
+	Search bool    // 웹 검색 그라운딩 요청 (지원하지 않는 제공자는 Response.SearchSkipped로 알림)
+	Schema *Schema // 설정하면 제공자의 JSON/스키마 출력 모드를 사용
+}
+
+// Response는 제공자의 생성 결과입니다.
+type Response struct {
+	Text    string
+	Model   string            // 응답한 제공자/모델 (예: gemini/gemini-2.5-flash)
+	Sources []domain.Citation // 검색 그라운딩 출처 (req.Search이고 제공자가 지원할 때)
+	// SearchSkipped는 req.Search였지만 제공자가 검색을 지원하지 않아 검색 없이 생성했다는 뜻입니다 (Brain이 생성 메모로 알림).
+	SearchSkipped bool
+}
+
+// Provider는 텍스트 생성 백엔드(Gemini, OpenAI 호환 API, Ollama 등)입니다.
+// 요청 자체가 잘못된 경우(4xx, 차단, 잘림)는 Router가 다른 제공자로 넘기지 않도록 StatusError나 ResponseError로 돌려줍니다.
+type Provider interface {
+	Name() string
+	Generate(ctx context.Context, req Request) (Response, error)
+}
+
+// StatusError는 제공자 HTTP API가 200이 아닌 상태로 응답한 경우입니다.
+type StatusError struct {
+	Provider string
+	Code     int
+	Body     string // 응답 본문 앞부분
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s status %d: %s", e.Provider, e.Code, e.Body)
+}
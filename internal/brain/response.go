@@ -0,0 +1,72 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 응답 이상 종류 (ResponseError.Kind)
+const (
+	ResponseBlocked    = "blocked"    // 안전 필터/정책으로 프롬프트나 응답이 차단됨
+	ResponseTruncated  = "truncated"  // 최대 토큰에 도달해 응답이 잘림
+	ResponseRecitation = "recitation" // 기존 저작물 인용으로 생성이 중단됨
+	ResponseEmpty      = "empty"      // 텍스트 없는 응답
+)
+
+// ResponseError는 호출은 성공했지만 쓸 수 없는 응답(차단, 잘림, 인용 중단, 빈 응답)을 나타냅니다.
+// 잘린 초안이나 차단된 응답이 정상 초안처럼 승인 요청으로 가지 않도록 제공자가 이 에러로 분류해 돌려줍니다.
+type ResponseError struct {
+	Provider string
+	Model    string
+	Kind     string
+	Reason   string // 제공자가 준 원래 사유 (예: SAFETY, MAX_TOKENS, content_filter)
+	Partial  string // 잘린 경우 받은 데까지의 텍스트
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s/%s: %s response (%s)", e.Provider, e.Model, e.Kind, e.Reason)
+}
+
+// Describe는 승인 메시지에 쓸 사람이 읽는 설명입니다.
+func (e *ResponseError) Describe() string {
+	switch e.Kind {
+	case ResponseBlocked:
+		return fmt.Sprintf("안전 필터로 차단됨 (%s)", e.Reason)
+	case ResponseTruncated:
+		return fmt.Sprintf("길이 제한으로 잘림 (%s)", e.Reason)
+	case ResponseRecitation:
+		return fmt.Sprintf("기존 글 인용으로 중단됨 (%s)", e.Reason)
+	}
+	return fmt.Sprintf("빈 응답 (%s)", e.Reason)
+}
+
+// retryHints는 응답 이상 종류별로 다시 요청할 때 프롬프트에 덧붙이는 지시입니다.
+var retryHints = map[string]string{
+	ResponseBlocked:    "이전 응답이 안전 필터에 막혔습니다. 공격적이거나 민감한 표현, 개인정보 없이 같은 요청에 다시 답하세요.",
+	ResponseTruncated:  "이전 응답이 길이 제한에 걸려 잘렸습니다. 핵심만 남겨 훨씬 짧고 간결하게 다시 답하세요.",
+	ResponseRecitation: "이전 응답이 기존 글을 그대로 옮겨 중단되었습니다. 인용하지 말고 자신의 말로 다시 답하세요.",
+	ResponseEmpty:      "이전 응답이 비어 있었습니다. 요청에 맞는 답을 반드시 출력하세요.",
+}
+
+// generate는 Provider 호출 결과가 ResponseError면 이상 종류에 맞게 프롬프트를 고쳐 한 번 다시 요청합니다.
+// 다시 요청해 성공하면 그 이유를 notes로 돌려 승인 메시지에 표시하게 합니다.
+func (b *Brain) generate(ctx context.Context, req Request) (Response, []string, error) {
+	resp, err := b.Provider.Generate(ctx, req)
+	if err == nil { return resp, searchNote(req, resp), nil }
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) { return resp, nil, err }
+
+	fmt.Printf("⚠️  [brain] %s: %v, retrying with adjusted prompt\n", req.Task, err)
+	retry := req
+	retry.Prompt = req.Prompt + "\n\n" + retryHints[respErr.Kind]
+	resp, err = b.Provider.Generate(ctx, retry)
+	if err != nil { return Response{}, nil, err }
+	return resp, append([]string{respErr.Describe() + " → 프롬프트를 고쳐 다시 생성함"}, searchNote(req, resp)...), nil
+}
+
+// searchNote는 검색을 요청했지만 응답한 제공자가 검색을 지원하지 않은 경우의 생성 메모입니다.
+func searchNote(req Request, resp Response) []string {
+	if !req.Search || !resp.SearchSkipped { return nil }
+	return []string{resp.Model + "는 웹 검색을 지원하지 않아 검색 없이 생성함 (출처 없음)"}
+}
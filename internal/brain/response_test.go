@@ -0,0 +1,54 @@
+package brain
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// scriptedProvider는 호출마다 responses/errs를 차례로 돌려줍니다.
+type scriptedProvider struct {
+	name      string
+	responses []Response
+	errs      []error
+	calls     []Request
+}
+
+func (p *scriptedProvider) Name() string { return p.name }
+
+func (p *scriptedProvider) Generate(ctx context.Context, req Request) (Response, error) {
+	i := len(p.calls)
+	p.calls = append(p.calls, req)
+	var resp Response
+	var err error
+	if i < len(p.responses) { resp = p.responses[i] }
+	if i < len(p.errs) { err = p.errs[i] }
+	return resp, err
+}
+
+func TestGenerateSearchNote(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		resp Response
+		errs []error
+		want []string
+	}{
+		{"search supported", Request{Task: TaskPost, Search: true}, Response{Text: "글", Model: "gemini/flash"}, nil, nil},
+		{"search skipped", Request{Task: TaskPost, Search: true}, Response{Text: "글", Model: "openai/gpt-4o-mini", SearchSkipped: true}, nil, []string{"openai/gpt-4o-mini는 웹 검색을 지원하지 않아 검색 없이 생성함 (출처 없음)"}},
+		{"search not requested", Request{Task: TaskReply}, Response{Text: "답", Model: "openai/gpt-4o-mini"}, nil, nil},
+		{"after retry", Request{Task: TaskPost, Search: true}, Response{Text: "글", Model: "openai/gpt-4o-mini", SearchSkipped: true},
+			[]error{&ResponseError{Provider: "openai", Model: "gpt-4o-mini", Kind: ResponseTruncated, Reason: "length"}},
+			[]string{"길이 제한으로 잘림 (length) → 프롬프트를 고쳐 다시 생성함", "openai/gpt-4o-mini는 웹 검색을 지원하지 않아 검색 없이 생성함 (출처 없음)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &scriptedProvider{name: "fake", responses: []Response{tt.resp, tt.resp}, errs: tt.errs}
+			if len(tt.errs) > 0 { p.responses[0] = Response{} }
+			b := NewBrain(p, nil)
+			_, notes, err := b.generate(context.Background(), tt.req)
+			if err != nil { t.Fatal(err) }
+			if !reflect.DeepEqual(notes, tt.want) { t.Errorf("notes = %q, want %q", notes, tt.want) }
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package brain
+
+import (
+	"context"
+	"d3k-agent/internal/core/ports"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Router는 사이트/작업별 경로에 따라 제공자를 고르고, 제공자 쪽 문제로 실패하면 경로의 다음 제공자로 넘어가는 Provider입니다.
+// 경로는 "사이트:작업", "사이트", "작업", "default" 순서로 찾으며, 없으면 등록된 모든 제공자를 순서대로 씁니다.
+// 검색을 지원하지 않는 제공자가 req.Search 요청을 받으면 Response.SearchSkipped가 설정되어 생성 메모로 알려집니다.
+type Router struct {
+	Providers map[string]Provider
+	Routes    map[string][]string
+	order     []string
+}
+
+func NewRouter() *Router {
+	return &Router{Providers: make(map[string]Provider), Routes: make(map[string][]string)}
+}
+
+var _ Provider = (*Router)(nil)
+
+func (r *Router) Add(p Provider) {
+	if _, exists := r.Providers[p.Name()]; !exists { r.order = append(r.order, p.Name()) }
+	r.Providers[p.Name()] = p
+}
+
+func (r *Router) Name() string { return "router" }
+
+// chain은 site/task에 해당하는 제공자 이름 목록입니다.
+func (r *Router) chain(site string, task Task) []string {
+	var keys []string
+	if site != "" { keys = append(keys, site+":"+string(task), site) }
+	keys = append(keys, string(task), "default")
+	for _, k := range keys {
+		if names, ok := r.Routes[k]; ok { return names }
+	}
+	return r.order
+}
+
+func (r *Router) Generate(ctx context.Context, req Request) (Response, error) {
+	site := ports.SiteFrom(ctx)
+	names := r.chain(site, req.Task)
+	var lastErr error
+	for i, name := range names {
+		p, ok := r.Providers[name]
+		if !ok { continue }
+		out, err := p.Generate(ctx, req)
+		if err == nil { return out, nil }
+		if !failover(err) { return Response{}, err } // 요청이 잘못된 경우는 다른 제공자도 같은 결과
+		lastErr = err
+		if i < len(names)-1 { fmt.Printf("⚠️  [brain] %s failed for %s@%s, trying next: %v\n", name, req.Task, site, err) }
+	}
+	if lastErr == nil { return Response{}, fmt.Errorf("no provider available for %s@%s (route %v)", req.Task, site, names) }
+	return Response{}, lastErr
+}
+
+// failover는 다음 제공자로 넘어가 볼 오류인지 판단합니다.
+// 할당량(429), 서버 오류(5xx), 모델 없음(404), 인증 실패, 네트워크 오류, 빈 응답과 인용 중단은 제공자 쪽 문제로 보고 넘어갑니다.
+// 취소, 잘못된 요청(그 밖의 4xx), 차단과 잘림(Brain이 프롬프트를 고쳐 다시 요청함)은 넘어가지 않습니다.
+func failover(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) { return false }
+	var respErr *ResponseError
+	if errors.As(err, &respErr) { return respErr.Kind == ResponseEmpty || respErr.Kind == ResponseRecitation }
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) { return providerFault(statusErr.Code) }
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) { return providerFault(apiErr.Code) }
+	return true
+}
+
+// providerFault는 HTTP 상태 코드가 요청 내용이 아닌 제공자 쪽 문제인지입니다.
+func providerFault(code int) bool {
+	switch code {
+	case 401, 403, 404, 408, 429:
+		return true
+	}
+	return code >= 500
+}
+
+// ModelState는 해당 제공자의 모델 차단기 상태입니다.
+func (r *Router) ModelState(provider, model string) string {
+	if sr, ok := r.Providers[provider].(stateReporter); ok { return sr.ModelState(provider, model) }
+	return ""
+}
+
+// ParseRoutes는 "default=gemini,ollama; reply=ollama; moltbook=openai; botmadang:post=gemini" 형식을 읽습니다.
+func ParseRoutes(spec string) (map[string][]string, error) {
+	routes := make(map[string][]string)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" { continue }
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok { return nil, fmt.Errorf("invalid route %q (want key=provider,...)", entry) }
+		var names []string
+		for _, n := range strings.Split(value, ",") {
+			if n = strings.ToLower(strings.TrimSpace(n)); n != "" { names = append(names, n) }
+		}
+		if len(names) == 0 { return nil, fmt.Errorf("route %q has no providers", key) }
+		routes[strings.ToLower(strings.TrimSpace(key))] = names
+	}
+	return routes, nil
+}
+
+// FromEnv는 환경 변수로 설정된 제공자와 경로(BRAIN_ROUTES)로 Brain을 만듭니다.
+// store가 있으면 호출 기록을 저장해 재시작 후에도 할당량과 토큰 사용량이 이어지고, 평가·요약 응답을 캐시합니다 (LLM_CACHE_TTL, LLM_CACHE=off).
+//   - gemini: GEMINI_API_KEY
+//   - openai: OPENAI_API_KEY, OPENAI_BASE_URL(기본 https://api.openai.com/v1), OPENAI_MODEL(기본 gpt-4o-mini)
+//   - ollama: OLLAMA_MODEL, OLLAMA_BASE_URL(기본 http://localhost:11434/v1)
+// 프롬프트 템플릿 디렉터리는 PROMPT_DIR입니다 (비우면 내장 템플릿).
+func FromEnv(ctx context.Context, store Store) (*Brain, error) {
+	router := NewRouter()
+	meter := NewMeter(ctx, store)
+	if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+		gemini, err := NewGeminiClient(ctx, key)
+		if err != nil { return nil, fmt.Errorf("gemini: %v", err) }
+		gemini.UseMeter(meter)
+		router.Add(gemini)
+	}
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+		openai, err := NewOpenAIClient("openai", envOr("OPENAI_BASE_URL", "https://api.openai.com/v1"), key, envOr("OPENAI_MODEL", "gpt-4o-mini"))
+		if err != nil { return nil, err }
+		openai.UseMeter(meter)
+		router.Add(openai)
+	}
+	if model := os.Getenv("OLLAMA_MODEL"); model != "" {
+		ollama, err := NewOpenAIClient("ollama", envOr("OLLAMA_BASE_URL", "http://localhost:11434/v1"), "", model)
+		if err != nil { return nil, err }
+		ollama.UseMeter(meter)
+		router.Add(ollama)
+	}
+	if len(router.Providers) == 0 { return nil, fmt.Errorf("no brain provider configured (GEMINI_API_KEY, OPENAI_API_KEY or OLLAMA_MODEL)") }
+
+	routes, err := ParseRoutes(os.Getenv("BRAIN_ROUTES"))
+	if err != nil { return nil, err }
+	for key, names := range routes {
+		for _, n := range names {
+			if _, ok := router.Providers[n]; !ok { return nil, fmt.Errorf("route %q uses unconfigured provider %q", key, n) }
+		}
+	}
+	router.Routes = routes
+	b := NewBrain(router, PromptsFromEnv())
+	b.Meter = meter
+	if b.CacheTTL, err = cacheTTLFromEnv(); err != nil { return nil, err }
+	if store != nil { b.Cache = store }
+	return b, nil
+}
+
+// Store는 Brain이 쓰는 저장소 기능입니다 (사용량 기록과 응답 캐시).
+type Store interface {
+	UsageStore
+	ResponseCache
+}
+
+// Describe는 설정된 제공자와 경로를 사람이 읽을 수 있게 돌려줍니다.
+func (r *Router) Describe() string {
+	desc := strings.Join(r.order, ", ")
+	var keys []string
+	for key := range r.Routes { keys = append(keys, key) }
+	sort.Strings(keys)
+	for _, key := range keys { desc += fmt.Sprintf(" | %s→%s", key, strings.Join(r.Routes[key], ">")) }
+	return desc
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" { return v }
+	return def
+}
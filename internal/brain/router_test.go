@@ -0,0 +1,133 @@
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+func TestParseRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{"empty", "", map[string][]string{}, false},
+		{"single", "default=gemini", map[string][]string{"default": {"gemini"}}, false},
+		{"chain and keys", "default=gemini,ollama; reply=ollama,gemini;moltbook=openai",
+			map[string][]string{"default": {"gemini", "ollama"}, "reply": {"ollama", "gemini"}, "moltbook": {"openai"}}, false},
+		{"site task key", "botmadang:post=gemini", map[string][]string{"botmadang:post": {"gemini"}}, false},
+		{"normalizes case and spaces", " Reply = Ollama , GEMINI ;", map[string][]string{"reply": {"ollama", "gemini"}}, false},
+		{"skips empty names", "default=gemini,,ollama,", map[string][]string{"default": {"gemini", "ollama"}}, false},
+		{"later entry wins", "reply=gemini;reply=ollama", map[string][]string{"reply": {"ollama"}}, false},
+		{"missing equals", "default", nil, true},
+		{"no providers", "reply= , ", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRoutes(tt.spec)
+			if tt.wantErr {
+				if err == nil { t.Errorf("ParseRoutes(%q) = %v, want error", tt.spec, got) }
+				return
+			}
+			if err != nil { t.Fatalf("ParseRoutes(%q): %v", tt.spec, err) }
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("ParseRoutes(%q) = %v, want %v", tt.spec, got, tt.want) }
+		})
+	}
+}
+
+func TestRouterChain(t *testing.T) {
+	r := NewRouter()
+	r.order = []string{"gemini", "openai", "ollama"}
+	r.Routes = map[string][]string{
+		"botmadang:post": {"openai"},
+		"moltbook":       {"ollama", "gemini"},
+		"reply":          {"ollama"},
+		"default":        {"gemini", "ollama"},
+	}
+	tests := []struct {
+		site string
+		task Task
+		want []string
+	}{
+		{"botmadang", TaskPost, []string{"openai"}},               // 사이트:작업
+		{"botmadang", TaskReply, []string{"ollama"}},              // 작업
+		{"moltbook", TaskReply, []string{"ollama", "gemini"}},     // 사이트가 작업보다 우선
+		{"botmadang", TaskEvaluate, []string{"gemini", "ollama"}}, // default
+		{"", TaskPost, []string{"gemini", "ollama"}},
+	}
+	for _, tt := range tests {
+		if got := r.chain(tt.site, tt.task); !reflect.DeepEqual(got, tt.want) { t.Errorf("chain(%q, %s) = %v, want %v", tt.site, tt.task, got, tt.want) }
+	}
+
+	r.Routes = map[string][]string{}
+	if got := r.chain("botmadang", TaskPost); !reflect.DeepEqual(got, r.order) { t.Errorf("without routes chain = %v, want registration order %v", got, r.order) }
+}
+
+func TestRouterFailover(t *testing.T) {
+	ok := Response{Text: "답", Model: "second/m"}
+	tests := []struct {
+		name         string
+		firstErr     error
+		wantFallback bool
+	}{
+		{"quota", &StatusError{Provider: "first", Code: 429}, true},
+		{"server error", &StatusError{Provider: "first", Code: 503}, true},
+		{"unknown model", &StatusError{Provider: "first", Code: 404}, true},
+		{"bad credentials", &StatusError{Provider: "first", Code: 401}, true},
+		{"network", errors.New("dial tcp: connection refused"), true},
+		{"gemini exhausted", fmt.Errorf("fail: %w", genai.APIError{Code: 429}), true},
+		{"empty response", &ResponseError{Provider: "first", Kind: ResponseEmpty}, true},
+		{"recitation", &ResponseError{Provider: "first", Kind: ResponseRecitation}, true},
+		{"bad request", &StatusError{Provider: "first", Code: 400}, false},
+		{"unprocessable", &StatusError{Provider: "first", Code: 422}, false},
+		{"gemini bad request", genai.APIError{Code: 400}, false},
+		{"blocked", &ResponseError{Provider: "first", Kind: ResponseBlocked}, false},
+		{"truncated", &ResponseError{Provider: "first", Kind: ResponseTruncated}, false},
+		{"canceled", fmt.Errorf("request: %w", context.Canceled), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := &scriptedProvider{name: "first", errs: []error{tt.firstErr}}
+			second := &scriptedProvider{name: "second", responses: []Response{ok}}
+			r := NewRouter()
+			r.Add(first)
+			r.Add(second)
+			resp, err := r.Generate(context.Background(), Request{Task: TaskReply, Prompt: "p"})
+			if tt.wantFallback {
+				if err != nil || resp.Text != ok.Text { t.Errorf("Generate = %+v, %v; want fallback response", resp, err) }
+				return
+			}
+			if err == nil || err.Error() != tt.firstErr.Error() { t.Errorf("err = %v, want the first provider's error %v", err, tt.firstErr) }
+			if len(second.calls) != 0 { t.Errorf("second provider called %d times, want no failover", len(second.calls)) }
+		})
+	}
+}
+
+func TestRouterGenerateChain(t *testing.T) {
+	gemini := &scriptedProvider{name: "gemini", errs: []error{&StatusError{Provider: "gemini", Code: 503}}}
+	openai := &scriptedProvider{name: "openai", errs: []error{&StatusError{Provider: "openai", Code: 500}}}
+	r := NewRouter()
+	r.Add(gemini)
+	r.Add(openai)
+	r.Routes = map[string][]string{"post": {"ghost", "gemini", "openai"}}
+
+	_, err := r.Generate(context.Background(), Request{Task: TaskPost})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Provider != "openai" { t.Errorf("err = %v, want the last provider's error", err) }
+	if len(gemini.calls) != 1 || len(openai.calls) != 1 { t.Errorf("calls = gemini %d, openai %d; want 1 each (unknown names skipped)", len(gemini.calls), len(openai.calls)) }
+
+	r.Routes = map[string][]string{"post": {"ghost"}}
+	if _, err := r.Generate(context.Background(), Request{Task: TaskPost}); err == nil { t.Error("route without configured providers must fail") }
+
+	// 검색을 지원하지 않는 제공자의 응답은 SearchSkipped를 그대로 전달
+	ollama := &scriptedProvider{name: "ollama", responses: []Response{{Text: "글", Model: "ollama/llama", SearchSkipped: true}}}
+	r.Add(ollama)
+	r.Routes = map[string][]string{"post": {"ollama"}}
+	if resp, err := r.Generate(context.Background(), Request{Task: TaskPost, Search: true}); err != nil || !resp.SearchSkipped { t.Errorf("Generate = %+v, %v; want SearchSkipped", resp, err) }
+}
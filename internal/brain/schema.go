@@ -0,0 +1,251 @@
+package brain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Schema는 구조화 출력용 JSON Schema의 부분 집합입니다.
+// 그대로 직렬화해 Gemini(responseJsonSchema)와 OpenAI 호환(response_format.json_schema)에 넘기고,
+// 응답 검증(Validate)에도 같은 정의를 씁니다.
+type Schema struct {
+	Type                 string             `json:"type"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int            { return &v }
+func boolPtr(v bool) *bool         { return &v }
+
+// 작업별 응답 스키마
+var (
+	postSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"title":     {Type: "string", Description: "글 제목", MinLength: intPtr(1)},
+			"content":   {Type: "string", Description: "본문 내용", MinLength: intPtr(1)},
+			"community": {Type: "string", Description: "올릴 게시판 이름 (예: tech, general, daily)"},
+		},
+		Required:             []string{"title", "content", "community"},
+		AdditionalProperties: boolPtr(false),
+	}
+	evaluationSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"score":  {Type: "integer", Description: "대화하고 싶은 정도 (0~10)", Minimum: floatPtr(0), Maximum: floatPtr(10)},
+			"reason": {Type: "string", Description: "점수의 이유 (한 문장)", MinLength: intPtr(1)},
+		},
+		Required:             []string{"score", "reason"},
+		AdditionalProperties: boolPtr(false),
+	}
+	evaluationBatchSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"evaluations": {Type: "array", Description: "글별 평가", Items: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"index":  {Type: "integer", Description: "글 번호", Minimum: floatPtr(0)},
+					"score":  evaluationSchema.Properties["score"],
+					"reason": evaluationSchema.Properties["reason"],
+				},
+				Required:             []string{"index", "score", "reason"},
+				AdditionalProperties: boolPtr(false),
+			}},
+		},
+		Required:             []string{"evaluations"},
+		AdditionalProperties: boolPtr(false),
+	}
+	knowledgeSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"entries": {Type: "array", Description: "정리한 지식", Items: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"kind":     {Type: "string", Description: "지식 종류", Enum: []string{"trend", "debate", "fact"}},
+					"topic":    {Type: "string", Description: "주제 (짧은 명사구)", MinLength: intPtr(1)},
+					"content":  {Type: "string", Description: "정리한 지식 (1~3문장)", MinLength: intPtr(1)},
+					"insights": {Type: "array", Description: "근거 기억 번호", Items: &Schema{Type: "integer", Minimum: floatPtr(0)}},
+					"update":   {Type: "integer", Description: "갱신할 기존 지식 번호 (새 지식이면 -1)", Minimum: floatPtr(-1)},
+				},
+				Required:             []string{"kind", "topic", "content", "insights", "update"},
+				AdditionalProperties: boolPtr(false),
+			}},
+		},
+		Required:             []string{"entries"},
+		AdditionalProperties: boolPtr(false),
+	}
+	exchangeSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"topics":    {Type: "array", Description: "대화 주제 (짧은 명사구)", Items: &Schema{Type: "string", MinLength: intPtr(1)}},
+			"sentiment": {Type: "string", Description: "상대의 분위기", Enum: []string{"positive", "neutral", "negative"}},
+			"facts":     {Type: "array", Description: "상대가 자신에 대해 밝힌 사실", Items: &Schema{Type: "string", MinLength: intPtr(1)}},
+		},
+		Required:             []string{"topics", "sentiment", "facts"},
+		AdditionalProperties: boolPtr(false),
+	}
+	critiqueSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"scores": {Type: "array", Description: "후보별 평가", Items: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"index":       {Type: "integer", Description: "후보 번호", Minimum: floatPtr(0)},
+					"relevance":   {Type: "integer", Description: "관련성 (0~10)", Minimum: floatPtr(0), Maximum: floatPtr(10)},
+					"persona_fit": {Type: "integer", Description: "페르소나 일치 (0~10)", Minimum: floatPtr(0), Maximum: floatPtr(10)},
+					"novelty":     {Type: "integer", Description: "최근 글 대비 새로움 (0~10)", Minimum: floatPtr(0), Maximum: floatPtr(10)},
+					"compliance":  {Type: "integer", Description: "규칙 준수 (0~10)", Minimum: floatPtr(0), Maximum: floatPtr(10)},
+					"reason":      {Type: "string", Description: "한 줄 평", MinLength: intPtr(1)},
+				},
+				Required:             []string{"index", "relevance", "persona_fit", "novelty", "compliance", "reason"},
+				AdditionalProperties: boolPtr(false),
+			}},
+		},
+		Required:             []string{"scores"},
+		AdditionalProperties: boolPtr(false),
+	}
+	personaFactsSchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"facts": {Type: "array", Description: "d3k가 자기 자신에 대해 한 진술", Items: &Schema{Type: "string", MinLength: intPtr(1)}},
+		},
+		Required:             []string{"facts"},
+		AdditionalProperties: boolPtr(false),
+	}
+	consistencySchema = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"conflicts": {Type: "array", Description: "기존 진술과 모순되는 초안의 진술", Items: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"claim":  {Type: "string", Description: "초안의 진술", MinLength: intPtr(1)},
+					"fact":   {Type: "integer", Description: "충돌하는 기존 진술 번호", Minimum: floatPtr(0)},
+					"reason": {Type: "string", Description: "모순인 이유 (한 문장)", MinLength: intPtr(1)},
+				},
+				Required:             []string{"claim", "fact", "reason"},
+				AdditionalProperties: boolPtr(false),
+			}},
+		},
+		Required:             []string{"conflicts"},
+		AdditionalProperties: boolPtr(false),
+	}
+)
+
+// Validate는 디코딩된 JSON 값(v)이 스키마를 만족하는지 확인하고 문제 목록을 돌려줍니다.
+func (s *Schema) Validate(v interface{}) []string {
+	var problems []string
+	s.validate("$", v, &problems)
+	return problems
+}
+
+func (s *Schema) validate(path string, v interface{}, problems *[]string) {
+	add := func(format string, args ...interface{}) { *problems = append(*problems, path+": "+fmt.Sprintf(format, args...)) }
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok { add("expected object, got %s", jsonType(v)); return }
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok { add("missing required field %q", name) }
+		}
+		var names []string
+		for name := range obj { names = append(names, name) }
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties { add("unexpected field %q", name) }
+				continue
+			}
+			prop.validate(path+"."+name, obj[name], problems)
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok { add("expected array, got %s", jsonType(v)); return }
+		if s.Items != nil {
+			for i, item := range arr { s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems) }
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok { add("expected string, got %s", jsonType(v)); return }
+		if s.MinLength != nil && len([]rune(strings.TrimSpace(str))) < *s.MinLength { add("must not be empty") }
+		if len(s.Enum) > 0 && !contains(s.Enum, str) { add("must be one of %v, got %q", s.Enum, str) }
+	case "integer", "number":
+		num, ok := v.(float64)
+		if !ok { add("expected %s, got %s", s.Type, jsonType(v)); return }
+		if s.Type == "integer" && num != math.Trunc(num) { add("expected integer, got %v", num) }
+		if s.Minimum != nil && num < *s.Minimum { add("must be >= %v, got %v", *s.Minimum, num) }
+		if s.Maximum != nil && num > *s.Maximum { add("must be <= %v, got %v", *s.Maximum, num) }
+	case "boolean":
+		if _, ok := v.(bool); !ok { add("expected boolean, got %s", jsonType(v)) }
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s { return true }
+	}
+	return false
+}
+
+// ValidationError는 모델 응답이 스키마를 만족하지 못했을 때(수리 재시도 후에도) 돌려주는 에러입니다.
+type ValidationError struct {
+	Task     Task
+	Problems []string
+	Raw      string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: invalid structured output: %s", e.Task, strings.Join(e.Problems, "; "))
+}
+
+// decodeStructured는 응답 텍스트를 JSON으로 읽어 스키마를 검증한 뒤 out에 디코딩합니다.
+// 코드 블록(```json)이나 앞뒤 설명이 섞인 응답은 가장 바깥 객체만 잘라 읽습니다.
+func decodeStructured(raw string, schema *Schema, out interface{}) []string {
+	text := extractJSON(raw)
+	var v interface{}
+	if err := json.Unmarshal([]byte(text), &v); err != nil { return []string{fmt.Sprintf("invalid JSON: %v", err)} }
+	if problems := schema.Validate(v); len(problems) > 0 { return problems }
+	if err := json.Unmarshal([]byte(text), out); err != nil { return []string{fmt.Sprintf("decode: %v", err)} }
+	return nil
+}
+
+func extractJSON(raw string) string {
+	text := strings.TrimSpace(raw)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "{") { return text }
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start != -1 && end > start { return text[start : end+1] }
+	return text
+}
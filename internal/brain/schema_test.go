@@ -0,0 +1,80 @@
+package brain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *Schema
+		input  string
+		want   []string // 문제 메시지에 포함되어야 할 조각 (비어 있으면 통과해야 함)
+	}{
+		{"post ok", postSchema, `{"title": "제목", "content": "본문", "community": "tech"}`, nil},
+		{"post missing field", postSchema, `{"title": "제목", "content": "본문"}`, []string{`$: missing required field "community"`}},
+		{"post extra field", postSchema, `{"title": "제목", "content": "본문", "community": "tech", "tags": []}`, []string{`$: unexpected field "tags"`}},
+		{"post blank title", postSchema, `{"title": "   ", "content": "본문", "community": "tech"}`, []string{"$.title: must not be empty"}},
+		{"post wrong type", postSchema, `{"title": 3, "content": "본문", "community": "tech"}`, []string{"$.title: expected string, got number"}},
+		{"not an object", postSchema, `["title"]`, []string{"$: expected object, got array"}},
+		{"evaluation ok", evaluationSchema, `{"score": 7, "reason": "흥미로움"}`, nil},
+		{"evaluation above maximum", evaluationSchema, `{"score": 11, "reason": "x"}`, []string{"$.score: must be <= 10"}},
+		{"evaluation below minimum", evaluationSchema, `{"score": -1, "reason": "x"}`, []string{"$.score: must be >= 0"}},
+		{"evaluation fractional", evaluationSchema, `{"score": 7.5, "reason": "x"}`, []string{"$.score: expected integer"}},
+		{"evaluation null", evaluationSchema, `{"score": null, "reason": "x"}`, []string{"$.score: expected integer, got null"}},
+		{"batch item path", evaluationBatchSchema, `{"evaluations": [{"index": 0, "score": 5, "reason": "a"}, {"index": 1, "score": 5}]}`, []string{`$.evaluations[1]: missing required field "reason"`}},
+		{"batch not array", evaluationBatchSchema, `{"evaluations": {}}`, []string{"$.evaluations: expected array, got object"}},
+		{"exchange enum", exchangeSchema, `{"topics": [], "sentiment": "angry", "facts": []}`, []string{"$.sentiment: must be one of"}},
+		{"knowledge update -1", knowledgeSchema, `{"entries": [{"kind": "fact", "topic": "t", "content": "c", "insights": [0, 2], "update": -1}]}`, nil},
+		{"knowledge negative insight", knowledgeSchema, `{"entries": [{"kind": "fact", "topic": "t", "content": "c", "insights": [-2], "update": -1}]}`, []string{"$.entries[0].insights[0]: must be >= 0"}},
+		{"several problems", critiqueSchema, `{"scores": [{"index": 0, "relevance": 12, "persona_fit": 5, "novelty": 5, "compliance": "high", "reason": ""}]}`, []string{"relevance: must be <= 10", "compliance: expected integer, got string", "reason: must not be empty"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(tt.input), &v); err != nil { t.Fatal(err) }
+			problems := tt.schema.Validate(v)
+			if len(tt.want) == 0 {
+				if len(problems) > 0 { t.Errorf("unexpected problems: %v", problems) }
+				return
+			}
+			if len(problems) != len(tt.want) { t.Errorf("got %d problems %v, want %d", len(problems), problems, len(tt.want)) }
+			joined := strings.Join(problems, "\n")
+			for _, w := range tt.want {
+				if !strings.Contains(joined, w) { t.Errorf("want problem containing %q, got:\n%s", w, joined) }
+			}
+		})
+	}
+}
+
+func TestDecodeStructured(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+		score   int
+	}{
+		{"plain", `{"score": 8, "reason": "좋음"}`, "", 8},
+		{"code fence", "```json\n{\"score\": 3, \"reason\": \"보통\"}\n```", "", 3},
+		{"surrounding prose", `평가 결과입니다: {"score": 6, "reason": "괜찮음"} 감사합니다.`, "", 6},
+		{"invalid json", `{"score": 8,`, "invalid JSON", 0},
+		{"schema violation", `{"score": 80, "reason": "x"}`, "must be <= 10", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out struct {
+				Score  int    `json:"score"`
+				Reason string `json:"reason"`
+			}
+			problems := decodeStructured(tt.raw, evaluationSchema, &out)
+			if tt.wantErr != "" {
+				if len(problems) == 0 || !strings.Contains(strings.Join(problems, "\n"), tt.wantErr) { t.Errorf("want problem %q, got %v", tt.wantErr, problems) }
+				return
+			}
+			if len(problems) > 0 { t.Fatalf("unexpected problems: %v", problems) }
+			if out.Score != tt.score { t.Errorf("score = %d, want %d", out.Score, tt.score) }
+		})
+	}
+}
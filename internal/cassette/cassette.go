@@ -0,0 +1,159 @@
+package cassette
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Interaction은 기록된 요청/응답 한 쌍입니다. 비밀값은 저장 전에 모두 가려집니다.
+type Interaction struct {
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Cassette는 한 사이트(또는 소스)의 기록 파일입니다.
+type Cassette struct {
+	Name         string        `json:"name"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load는 path의 카세트를 읽습니다.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil { return nil, err }
+	return &c, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { return err }
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil { return err }
+	return os.WriteFile(path, data, 0644)
+}
+
+const redacted = "REDACTED"
+
+// 값을 가리는 헤더, 쿼리 파라미터, JSON 키 (소문자)
+var (
+	secretHeaders = map[string]bool{"authorization": true, "cookie": true, "set-cookie": true, "x-api-key": true, "proxy-authorization": true}
+	secretKeys    = map[string]bool{
+		"api_key": true, "apikey": true, "access_token": true, "refresh_token": true, "token": true,
+		"client_secret": true, "password": true, "secret": true, "authorization": true, "key": true,
+		"verification_code": true, "claim_url": true, "claim_code": true,
+	}
+	// secretPathMarkers 다음의 경로 조각은 비밀값입니다 (예: /claim/{code}, /tokens/{token}).
+	secretPathMarkers = map[string]bool{"claim": true, "claims": true, "verify": true, "token": true, "tokens": true, "keys": true, "reset": true}
+)
+
+func redactHeader(h http.Header) http.Header {
+	if len(h) == 0 { return nil }
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if secretHeaders[strings.ToLower(k)] {
+			out[k] = []string{redacted}
+			continue
+		}
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+func redactURL(u *url.URL) string {
+	cp := *u
+	cp.User = nil
+	cp.Path, cp.RawPath = redactPath(cp.Path), ""
+	q := cp.Query()
+	changed := false
+	for k := range q {
+		if secretKeys[strings.ToLower(k)] { q.Set(k, redacted); changed = true }
+	}
+	if changed { cp.RawQuery = q.Encode() }
+	return cp.String()
+}
+
+// redactPath는 secretPathMarkers 바로 뒤의 경로 조각을 가립니다.
+func redactPath(path string) string {
+	segs := strings.Split(path, "/")
+	for i := 1; i < len(segs); i++ {
+		if segs[i] != "" && secretPathMarkers[strings.ToLower(segs[i-1])] { segs[i] = redacted }
+	}
+	return strings.Join(segs, "/")
+}
+
+// minEchoedSecret보다 짧은 비밀값은 본문의 다른 곳에서 찾아 가리지 않습니다 (흔한 짧은 문자열까지 바뀌지 않도록).
+const minEchoedSecret = 6
+
+// redactBody는 JSON 본문의 비밀 키 값을 가리고, 그 값이 안내 문구 등 다른 필드에 다시 나오면 거기서도 가립니다.
+// JSON이 아니거나 가릴 값이 없으면 원문 그대로 돌려줍니다.
+func redactBody(body []byte) string {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil { return string(body) }
+	var secrets []string
+	if !redactValue(v, &secrets) { return string(body) }
+	scrubValue(v, secrets)
+	out, err := json.Marshal(v)
+	if err != nil { return string(body) }
+	return string(out)
+}
+
+func redactValue(v interface{}, secrets *[]string) bool {
+	changed := false
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if s, isString := child.(string); isString && secretKeys[strings.ToLower(k)] {
+				if len(s) >= minEchoedSecret { *secrets = append(*secrets, s) }
+				t[k] = redacted
+				changed = true
+				continue
+			}
+			if redactValue(child, secrets) { changed = true }
+		}
+	case []interface{}:
+		for _, child := range t {
+			if redactValue(child, secrets) { changed = true }
+		}
+	}
+	return changed
+}
+
+// scrubValue는 문자열 값 안에 나오는 secrets를 가립니다.
+func scrubValue(v interface{}, secrets []string) {
+	scrub := func(s string) string {
+		for _, secret := range secrets { s = strings.ReplaceAll(s, secret, redacted) }
+		return s
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if s, isString := child.(string); isString { t[k] = scrub(s); continue }
+			scrubValue(child, secrets)
+		}
+	case []interface{}:
+		for i, child := range t {
+			if s, isString := child.(string); isString { t[i] = scrub(s); continue }
+			scrubValue(child, secrets)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode는 Transport의 동작 방식입니다.
+type Mode string
+
+const (
+	ModeRecord Mode = "record" // 실제 요청을 보내고 결과를 카세트에 추가
+	ModeReplay Mode = "replay" // 카세트에서 응답, 일치하는 기록이 없으면 실제 요청
+	ModeStrict Mode = "strict" // 카세트에서 응답, 일치하는 기록이 없으면 ErrNoMatch
+)
+
+// ErrNoMatch는 strict 모드에서 기록되지 않은 요청이 들어왔을 때 돌려줍니다.
+var ErrNoMatch = errors.New("cassette: no recorded interaction")
+
+// Transport는 사이트 클라이언트의 HTTPClient에 끼우는 기록/재생용 http.RoundTripper입니다.
+// 요청은 메서드, (비밀값을 가린) URL, 본문으로 매칭하며, 같은 요청이 여러 번 기록되어 있으면 기록 순서대로 재생합니다.
+type Transport struct {
+	Mode Mode
+	Path string
+	Base http.RoundTripper // nil이면 http.DefaultTransport
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New는 path의 카세트로 Transport를 만듭니다. 재생 모드에서는 파일이 있어야 합니다.
+func New(mode Mode, path string, base http.RoundTripper) (*Transport, error) {
+	t := &Transport{Mode: mode, Path: path, Base: base, cassette: &Cassette{Name: strings.TrimSuffix(filepath.Base(path), ".json")}}
+	switch mode {
+	case ModeRecord:
+		// 기존 기록에 이어서 추가. 깨진 파일을 빈 카세트로 덮어쓰지 않도록 읽기 실패는 파일이 없을 때만 허용함
+		c, err := Load(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) { return nil, fmt.Errorf("load cassette: %w", err) }
+		if err == nil { t.cassette = c }
+	case ModeReplay, ModeStrict:
+		c, err := Load(path)
+		if err != nil { return nil, fmt.Errorf("load cassette: %w", err) }
+		t.cassette = c
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q (record, replay, strict)", mode)
+	}
+	t.used = make([]bool, len(t.cassette.Interactions))
+	return t, nil
+}
+
+// FromEnv는 HTTP_CASSETTE_MODE/HTTP_CASSETTE_DIR 설정에 따라 name 카세트를 쓰는 Transport를 돌려줍니다.
+// 설정이 없으면 nil(= 기본 Transport)을 돌려줍니다. 카세트를 열 수 없으면 에러입니다:
+// replay/strict에서 조용히 실제 요청으로 넘어가면 재생 검사가 실서비스를 호출하게 되므로 클라이언트가 시작하지 않아야 합니다.
+func FromEnv(name string) (http.RoundTripper, error) {
+	mode := Mode(strings.ToLower(os.Getenv("HTTP_CASSETTE_MODE")))
+	if mode == "" || mode == "off" { return nil, nil }
+	dir := os.Getenv("HTTP_CASSETTE_DIR")
+	if dir == "" { dir = "data/cassettes" }
+	t, err := New(mode, filepath.Join(dir, name+".json"), nil)
+	if err != nil { return nil, fmt.Errorf("[%s] cassette: %w", name, err) }
+	fmt.Printf("📼 [%s] cassette %s: %s\n", name, mode, t.Path)
+	return t, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil { return t.Base }
+	return http.DefaultTransport
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil { return nil, err }
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	rec := Request{Method: req.Method, URL: redactURL(req.URL), Header: redactHeader(req.Header), Body: redactBody(body)}
+
+	if t.Mode == ModeRecord { return t.record(req, rec) }
+
+	if i, ok := t.match(rec); ok {
+		return t.cassette.Interactions[i].Response.toHTTP(req), nil
+	}
+	if t.Mode == ModeStrict { return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, rec.Method, rec.URL) }
+	return t.base().RoundTrip(req)
+}
+
+func (t *Transport) record(req *http.Request, rec Request) (*http.Response, error) {
+	resp, err := t.base().RoundTrip(req)
+	if err != nil { return nil, err }
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil { return nil, err }
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request:    rec,
+		Response:   Response{StatusCode: resp.StatusCode, Header: redactHeader(resp.Header), Body: redactBody(data)},
+		RecordedAt: time.Now().UTC(),
+	})
+	t.used = append(t.used, true)
+	if err := t.cassette.Save(t.Path); err != nil { fmt.Printf("⚠️  cassette save failed: %v\n", err) }
+	return resp, nil
+}
+
+// match는 아직 재생하지 않은 첫 번째 일치 기록을 찾습니다.
+func (t *Transport) match(rec Request) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, it := range t.cassette.Interactions {
+		if t.used[i] || it.Request.Method != rec.Method || it.Request.URL != rec.URL || it.Request.Body != rec.Body { continue }
+		t.used[i] = true
+		return i, true
+	}
+	return 0, false
+}
+
+// Unused는 아직 재생되지 않은 기록 수입니다.
+func (t *Transport) Unused() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, u := range t.used {
+		if !u { n++ }
+	}
+	return n
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil { header = make(http.Header) }
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,40 @@
+package cassette
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte("{not json"), 0644); err != nil { t.Fatal(err) }
+	if err := (&Cassette{Name: "good"}).Save(filepath.Join(dir, "good.json")); err != nil { t.Fatal(err) }
+
+	tests := []struct {
+		mode    string
+		name    string
+		wantNil bool
+		wantErr bool
+	}{
+		{"", "missing", true, false},
+		{"off", "missing", true, false},
+		{"strict", "good", false, false},
+		{"strict", "missing", true, true},
+		{"strict", "corrupt", true, true},
+		{"replay", "missing", true, true},
+		{"replay", "corrupt", true, true},
+		{"record", "missing", false, false},
+		{"record", "corrupt", true, true}, // 깨진 기록을 덮어쓰지 않음
+		{"bogus", "good", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.name, func(t *testing.T) {
+			t.Setenv("HTTP_CASSETTE_MODE", tt.mode)
+			t.Setenv("HTTP_CASSETTE_DIR", dir)
+			tr, err := FromEnv(tt.name)
+			if (err != nil) != tt.wantErr { t.Fatalf("FromEnv err = %v, wantErr %v", err, tt.wantErr) }
+			if (tr == nil) != tt.wantNil { t.Errorf("FromEnv transport = %v, wantNil %v", tr, tt.wantNil) }
+		})
+	}
+}
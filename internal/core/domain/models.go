@@ -4,13 +4,30 @@ import "time"
 
 // Post represents a generic post from any platform.
 type Post struct {
-	ID        string
-	Source    string // e.g., "botmadang", "moltbook"
-	Title     string
-	Content   string
-	Author    string
-	URL       string
-	CreatedAt time.Time
+	ID           string
+	Source       string // e.g., "botmadang", "moltbook"
+	Title        string
+	Content      string
+	Author       string
+	URL          string
+	Community    string // 게시판 이름 (submadang, submolt 등)
+	CommentCount int    // 댓글 수 (사이트가 알려 줄 때만, 모르면 0)
+	CreatedAt    time.Time
+}
+
+// Board is a community section such as a submadang or submolt.
+type Board struct {
+	Name        string
+	DisplayName string
+	Description string
+}
+
+// Profile describes the authenticated agent on a platform.
+type Profile struct {
+	ID          string
+	Name        string
+	Description string
+	Karma       int
 }
 
 // Comment represents a comment on a post.
@@ -39,10 +56,222 @@ type Notification struct {
 
 // Insight represents AI's processed thoughts about a post.
 type Insight struct {
+	ID          int64
+	PostID      string
+	Source      string
+	Kind        string    // "" (읽은 글/피드 요약), "own_post", "own_comment", "own_reply" (우리가 쓴 글)
+	Topic       string
+	Content     string    // D3K's impression or lesson learned
+	ContentHash string    // 요약한 원문(제목+내용) 해시; (Source, PostID, ContentHash)가 같으면 같은 기억
+	Embedding   []float32 `json:"-"`          // Topic+Content의 의미 벡터 (임베더가 없으면 비어 있음, JSON 저장소는 별도 파일에 보관)
+	Similarity  float64   `json:"-"`          // SearchInsights 결과의 코사인 유사도
+	CreatedAt   time.Time
+}
+
+
+// FeedItem is an entry ingested from a read-only knowledge source (RSS/Atom feed).
+type FeedItem struct {
+	ID          string // stable hash of feed + guid/link
+	Feed        string // feed URL or file path
+	FeedTitle   string
+	Category    string // optional topic category configured for the feed
+	Title       string
+	Link        string
+	Summary     string
+	PublishedAt time.Time
+	FetchedAt   time.Time
+}
+
+// Heartbeat is a fetched snapshot of a site's heartbeat document.
+type Heartbeat struct {
 	ID        int64
-	PostID    string
 	Source    string
-	Topic     string
-	Content   string // D3K's impression or lesson learned
+	URL       string
+	Hash      string // sha256 of Content
+	Content   string
+	Changed   bool   // true if Content differs from the previous fetch
+	FetchedAt time.Time
+}
+// PostDraft is a generated post awaiting approval.
+type PostDraft struct {
+	Title         string
+	Content       string
+	Community     string     // 제안된 게시판 (사이트에 없으면 어댑터 기본값 사용)
+	PromptVersion string     // 생성에 쓴 프롬프트 템플릿 버전 (예: post@1a2b3c4d)
+	Notes         []string   // 생성 중 차단/잘림 등으로 다시 생성한 이유 (승인 메시지에 표시)
+	Sources       []Citation // 검색 그라운딩 출처
+	Critique      *Critique  // 여러 후보를 만들었을 때의 비평 (없으면 nil)
+}
+
+// Critique is the brain's review of one generated candidate. Each criterion is 0~10.
+type Critique struct {
+	Relevance  int     `json:"relevance"`   // 주제/대화와의 관련성
+	PersonaFit int     `json:"persona_fit"` // d3k 정체성과 말투에 맞는지
+	Novelty    int     `json:"novelty"`     // 최근 우리 글과 비교한 새로움
+	Compliance int     `json:"compliance"`  // 소통 규칙 준수 (길이, 금지 표현 등)
+	Score      float64 `json:"score"`       // 네 항목 평균 (순위 기준)
+	Reason     string  `json:"reason"`
+}
+
+// Citation is a web source a generation was grounded on.
+type Citation struct {
+	Title string `json:"title"`
+	URI   string `json:"uri"`
+}
+
+// PromptContext is retrieved context injected into a generation prompt.
+type PromptContext struct {
+	Refs          []FeedItem     // 인용할 수 있는 최근 지식 소스 항목
+	Memories      []Insight      // 관련된 과거 insight (장기 기억)
+	Relationships []Relationship // 대화 상대에 대한 기억 (답글 작성 시)
+	Knowledge     []Knowledge    // 여러 기억을 정리한 지식 (트렌드, 반복되는 논쟁, 사실)
+	Recent        []Insight      // 최근에 게시한 우리 글 (후보 비평에서 새로움 비교용, 프롬프트에는 넣지 않음)
+}
+
+// Knowledge is a durable entry distilled from many insights, with provenance links back to them.
+type Knowledge struct {
+	ID         int64
+	Kind       string   // "trend", "debate", "fact"
+	Topic      string
+	Content    string
+	Sources    []string // 근거가 된 사이트/소스
+	InsightIDs []int64  // 근거 insight ID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time // 마지막으로 새 근거가 더해진 때 (오래되면 폐기)
+}
+
+// PersonaFact is something d3k has said about itself in published content.
+type PersonaFact struct {
+	ID        int64
+	Statement string // 자기 자신에 대한 진술 (예: "매일 새벽에 쿼리 로그를 정리한다")
+	Source    string // 진술한 사이트
+	Origin    string // 진술이 나온 글/댓글 (앞부분)
 	CreatedAt time.Time
+	LastSeen  time.Time // 마지막으로 같은 진술을 한 때
+}
+
+// Contradiction is a claim in a draft that conflicts with a recorded persona fact.
+type Contradiction struct {
+	Claim     string // 초안의 진술
+	Fact      string // 충돌하는 기존 진술
+	Reason    string
+	Candidate int // 어긋난 후보 번호 (1부터, 후보를 하나만 보여 주면 0)
+}
+
+// Relationship is what d3k remembers about another author on a site.
+type Relationship struct {
+	Source       string
+	Author       string
+	Interactions int      // 우리가 답한 대화 횟수
+	Topics       []string // 함께 이야기한 주제 (최근 것이 뒤)
+	Sentiment    string   // 마지막 대화의 분위기: positive, neutral, negative
+	Facts        []string // 상대가 알려준 자신에 대한 사실
+	LastExchange string   // 마지막 대화 요약 (상대 말 / 우리 답)
+	FirstSeen    time.Time
+	LastSeen     time.Time
+}
+
+// ExchangeNote is the brain's analysis of one exchange with another author.
+type ExchangeNote struct {
+	Topics    []string
+	Sentiment string
+	Facts     []string
+}
+
+// ReplyDraft is a generated comment or reply awaiting approval.
+type ReplyDraft struct {
+	Content       string
+	PromptVersion string
+	Notes         []string
+	Critique      *Critique // 여러 후보를 만들었을 때의 비평 (없으면 nil)
+}
+
+// Evaluation is the brain's interest score for a post.
+type Evaluation struct {
+	Score         int // 0~10
+	Reason        string
+	Model         string // 평가한 제공자/모델 (예: gemini/gemini-2.5-flash)
+	PromptVersion string
+}
+
+// PostEvaluation is a persisted evaluation of a site post, used to skip re-evaluating unchanged posts.
+type PostEvaluation struct {
+	Source string
+	PostID string
+	Evaluation
+	ContentHash  string // 평가 당시 제목+내용 해시 (바뀌면 다시 평가)
+	CommentCount int    // 평가 당시 댓글 수
+	EvaluatedAt  time.Time
+}
+
+// Draft is a record of generated content and what happened to it.
+type Draft struct {
+	ID            int64
+	Source        string
+	Kind          string // "post", "comment", "reply"
+	TargetID      string // 댓글/답글 대상 글 ID
+	Title         string
+	Content       string
+	Community     string // 글의 게시판 (게시된 글은 실제로 올린 게시판, 빈 문자열이면 사이트 기본값)
+	PromptVersion string
+	Sources       []Citation // 글 작성 시 검색 그라운딩 출처
+	Status        string     // "approved", "rejected", "failed", 다른 후보가 선택되면 "lost"
+	Group         string     // 같은 요청에서 나온 후보들을 묶는 ID (후보가 하나면 비어 있음)
+	Rank          int        // 비평 순위 (1부터, 후보가 하나면 0)
+	Critique      *Critique  // 후보 비평 (없으면 nil)
+	CreatedAt     time.Time
+}
+
+// UsageEvent is one LLM call (successful or failed) with its token usage.
+type UsageEvent struct {
+	ID           int64
+	Provider     string // gemini, openai, ollama ...
+	Model        string
+	Task         string // post, reply, evaluate ...
+	Site         string // 호출한 사이트 (없으면 빈 문자열)
+	PromptTokens int
+	OutputTokens int
+	TotalTokens  int
+	Failed       bool // 오류로 끝난 호출 (제공자 한도에는 포함)
+	At           time.Time
+}
+
+// CacheEntry is a cached LLM response for a deterministic task, keyed by a hash of the task and prompt.
+type CacheEntry struct {
+	Key       string
+	Task      string
+	Value     string
+	Model     string // 응답한 제공자/모델
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// ModelBudget is the sliding-window usage of one model against its limits (0 = 무제한).
+type ModelBudget struct {
+	Provider    string
+	Model       string
+	MinuteUsed  int
+	MinuteLimit int
+	DayUsed     int // 최근 24시간
+	DayLimit    int
+	DayTokens   int
+	DayFailed   int    // 최근 24시간 중 실패한 호출 (DayUsed에 포함)
+	State       string // 회로 차단기 상태 ("", closed, open, half-open)
+}
+
+// Budget is the remaining LLM budget and recent token usage.
+type Budget struct {
+	Models       []ModelBudget
+	TokensByTask map[string]int // 최근 24시간 토큰 사용량
+	TokensBySite map[string]int
+}
+
+// RemainingToday returns the requests left in the 24h window, or -1 if some model is unlimited.
+func (b Budget) RemainingToday() int {
+	remaining := 0
+	for _, m := range b.Models {
+		if m.DayLimit == 0 { return -1 }
+		if left := m.DayLimit - m.DayUsed; left > 0 { remaining += left }
+	}
+	return remaining
 }
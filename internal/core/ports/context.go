@@ -0,0 +1,16 @@
+package ports
+
+import "context"
+
+type siteKey struct{}
+
+// WithSite는 현재 작업 중인 사이트 이름을 ctx에 담습니다. Brain 라우팅 등 사이트별 설정에 쓰입니다.
+func WithSite(ctx context.Context, site string) context.Context {
+	return context.WithValue(ctx, siteKey{}, site)
+}
+
+// SiteFrom은 WithSite로 담긴 사이트 이름을 돌려줍니다. 없으면 빈 문자열입니다.
+func SiteFrom(ctx context.Context) string {
+	site, _ := ctx.Value(siteKey{}).(string)
+	return site
+}
@@ -3,24 +3,93 @@ package ports
 import (
 	"context"
 	"d3k-agent/internal/core/domain"
+	"time"
 )
 
+// Site는 모든 사이트 어댑터가 구현해야 하는 최소 기능입니다.
+// 그 외 기능은 아래 capability 인터페이스로 분리되어 런타임에 타입 단언으로 확인합니다.
 type Site interface {
 	Name() string
 	Initialize(ctx context.Context) error
 	GetRecentPosts(ctx context.Context, limit int) ([]domain.Post, error)
-	GetNotifications(ctx context.Context, unreadOnly bool) ([]domain.Notification, error)
 	CreatePost(ctx context.Context, post domain.Post) error
 	CreateComment(ctx context.Context, postID string, content string) error
-	ReplyToComment(ctx context.Context, postID, parentCommentID, content string) error
+}
+
+// Notifier는 알림 조회/읽음 처리를 지원하는 사이트입니다.
+type Notifier interface {
+	GetNotifications(ctx context.Context, unreadOnly bool, limit int) ([]domain.Notification, error)
 	MarkNotificationRead(ctx context.Context, id string) error
 }
 
+// Threader는 댓글에 대한 답글(스레드)을 지원하는 사이트입니다.
+type Threader interface {
+	ReplyToComment(ctx context.Context, postID, parentCommentID, content string) error
+}
+
+// Voter는 게시글 추천/비추천을 지원하는 사이트입니다.
+type Voter interface {
+	Upvote(ctx context.Context, postID string) error
+	Downvote(ctx context.Context, postID string) error
+}
+
+// BoardLister는 게시판(submadang, submolt 등) 목록을 제공하는 사이트입니다.
+type BoardLister interface {
+	ListBoards(ctx context.Context) ([]domain.Board, error)
+}
+
+// ProfileProvider는 인증된 에이전트 자신의 프로필을 제공하는 사이트입니다.
+type ProfileProvider interface {
+	GetProfile(ctx context.Context) (*domain.Profile, error)
+}
+
+// BackgroundWorker는 메인 루프와 별개로 돌아야 하는 작업(heartbeat 등)을 가진 사이트입니다.
+type BackgroundWorker interface {
+	RunBackground(ctx context.Context)
+}
+
+// Source는 글을 쓰지 않는 읽기 전용 지식 소스입니다 (RSS/Atom 피드 등).
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) ([]domain.FeedItem, error)
+}
+
 type Brain interface {
-	GeneratePost(ctx context.Context, topic string) (string, error)
-	GenerateReply(ctx context.Context, postContent string, commentContent string) (string, error)
-	EvaluatePost(ctx context.Context, post domain.Post) (int, string, error)
+	// pc는 프롬프트에 넣을 참고 자료(피드 항목)와 회상된 기억입니다 (비어 있어도 됨).
+	GeneratePost(ctx context.Context, topic string, pc domain.PromptContext) (*domain.PostDraft, error)
+	GenerateReply(ctx context.Context, postContent string, commentContent string, pc domain.PromptContext) (*domain.ReplyDraft, error)
+	// GeneratePostCandidates/GenerateReplyCandidates는 후보 n개를 만들고 비평 단계(관련성, 페르소나 일치, pc.Recent 대비 새로움, 규칙 준수)로
+	// 점수를 매겨 좋은 순으로 돌려줍니다. 후보가 하나뿐이면 비평하지 않습니다 (Critique가 nil).
+	GeneratePostCandidates(ctx context.Context, topic string, pc domain.PromptContext, n int) ([]domain.PostDraft, error)
+	GenerateReplyCandidates(ctx context.Context, postContent string, commentContent string, pc domain.PromptContext, n int) ([]domain.ReplyDraft, error)
+	EvaluatePost(ctx context.Context, post domain.Post) (*domain.Evaluation, error)
+	// EvaluatePosts는 여러 글을 한 번의 구조화 호출로 평가해 posts와 같은 순서로 돌려줍니다.
+	EvaluatePosts(ctx context.Context, posts []domain.Post) ([]domain.Evaluation, error)
 	SummarizeInsight(ctx context.Context, post domain.Post) (string, error)
+	// DistillKnowledge는 주제가 비슷한 insight 묶음을 지식으로 정리합니다. existing 중 같은 내용을 다루는 항목은
+	// 그 ID로 갱신하도록 돌려주며, 결과의 InsightIDs는 근거로 쓴 insight입니다.
+	DistillKnowledge(ctx context.Context, insights []domain.Insight, existing []domain.Knowledge) ([]domain.Knowledge, error)
+	// ExtractPersonaFacts는 우리가 게시한 글에서 d3k가 자기 자신(일상, 습관, 경험, 의견)에 대해 한 진술을 뽑습니다.
+	ExtractPersonaFacts(ctx context.Context, content string) ([]string, error)
+	// CheckConsistency는 초안이 전에 한 자기 진술(facts)과 모순되는지 확인합니다. 모순이 없으면 빈 목록입니다.
+	CheckConsistency(ctx context.Context, content string, facts []domain.PersonaFact) ([]domain.Contradiction, error)
+	// AnalyzeExchange는 상대의 말(theirs)과 우리 답(ours)에서 주제, 분위기, 상대가 밝힌 사실을 뽑습니다.
+	AnalyzeExchange(ctx context.Context, theirs, ours string) (*domain.ExchangeNote, error)
+}
+
+// Embedder는 텍스트를 의미 벡터로 바꾸는 임베딩 모델입니다 (Gemini, OpenAI 호환 /embeddings 등).
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// VectorIndexer는 임베딩 차원에 맞춰 벡터 색인을 준비할 수 있는 Storage입니다 (Postgres + pgvector).
+type VectorIndexer interface {
+	PrepareVectorIndex(ctx context.Context, dims int) error
+}
+
+// BudgetReporter는 남은 LLM 요청 한도와 토큰 사용량을 알려 주는 Brain입니다 (스케줄러, /status에서 사용).
+type BudgetReporter interface {
+	Budget() domain.Budget
 }
 
 type Storage interface {
@@ -32,9 +101,64 @@ type Storage interface {
 	IncrementCommentCount(source string, date string) error
 	IsProactiveDone(source, postID string) (bool, error)
 	MarkProactive(source, postID string) error
+
+	// 사이트별 API 키는 암호화된 상태로 보관됩니다.
+	// CredentialsReady는 키를 암호화해 저장할 수 있는지(CREDENTIAL_SECRET) 확인합니다. 한 번만 발급되는 키를 받기 전에 부릅니다.
+	CredentialsReady() error
+	SaveCredential(ctx context.Context, source, secret string) error
+	LoadCredential(ctx context.Context, source string) (string, error)
+
+	// SaveFeedItems는 처음 보는 항목만 저장하고 그 목록을 돌려줍니다.
+	SaveFeedItems(ctx context.Context, items []domain.FeedItem) ([]domain.FeedItem, error)
+	GetRecentFeedItems(ctx context.Context, limit int) ([]domain.FeedItem, error)
+
+	SaveHeartbeat(ctx context.Context, hb domain.Heartbeat) error
+	GetLatestHeartbeat(ctx context.Context, source string) (*domain.Heartbeat, error)
 	
+	// SaveInsight는 (Source, PostID, ContentHash)가 같은 insight가 있으면 덮어씁니다 (ContentHash가 비어 있으면 항상 추가).
 	SaveInsight(ctx context.Context, insight domain.Insight) error
+	// HasInsight는 같은 원문으로 이미 배운 insight가 있는지 확인합니다 (다시 요약하지 않기 위해).
+	HasInsight(ctx context.Context, source, postID, contentHash string) (bool, error)
 	GetRecentInsights(ctx context.Context, limit int) ([]domain.Insight, error)
+	// SearchInsights는 query 벡터와 코사인 유사도가 높은 순으로 k개를 돌려줍니다 (Similarity 채움).
+	// 차원이 다른 벡터(임베딩 모델 변경 전)는 비교하지 않습니다.
+	SearchInsights(ctx context.Context, query []float32, k int) ([]domain.Insight, error)
+	// GetUnembeddedInsights/SaveInsightEmbedding은 임베딩 없이 저장된 insight를 나중에 채울 때 씁니다.
+	GetUnembeddedInsights(ctx context.Context, limit int) ([]domain.Insight, error)
+	SaveInsightEmbedding(ctx context.Context, id int64, embedding []float32) error
+
+	// 지식 베이스. SaveKnowledge는 ID가 0이면 추가, 아니면 갱신합니다. GetKnowledge는 최근 갱신 순입니다.
+	SaveKnowledge(ctx context.Context, k domain.Knowledge) error
+	GetKnowledge(ctx context.Context, limit int) ([]domain.Knowledge, error)
+	// DeleteStaleKnowledge는 before 이후로 갱신되지 않은 지식을 지우고 지운 개수를 돌려줍니다.
+	DeleteStaleKnowledge(ctx context.Context, before time.Time) (int, error)
+
+	// 페르소나 자기 진술. 진술은 공백·대소문자·끝 문장부호를 무시하고 비교합니다.
+	// SavePersonaFact는 같은 진술이 있으면 LastSeen만 갱신합니다. GetPersonaFacts는 최근 진술 순입니다.
+	// RetirePersonaFact는 새 진술로 대체된 진술을 지웁니다 (없으면 아무것도 하지 않음).
+	SavePersonaFact(ctx context.Context, f domain.PersonaFact) error
+	GetPersonaFacts(ctx context.Context, limit int) ([]domain.PersonaFact, error)
+	RetirePersonaFact(ctx context.Context, statement string) error
+
+	// 사이트·작성자별 관계 기억. 없으면 nil을 돌려줍니다.
+	GetRelationship(ctx context.Context, source, author string) (*domain.Relationship, error)
+	SaveRelationship(ctx context.Context, r domain.Relationship) error
+
+	// LLM 호출 기록 (슬라이딩 윈도우 할당량과 토큰 집계용). GetUsageSince는 시간순으로 돌려줍니다.
+	SaveUsage(ctx context.Context, e domain.UsageEvent) error
+	GetUsageSince(ctx context.Context, since time.Time) ([]domain.UsageEvent, error)
+
+	// 글 평가 기록 (사이트·글별 최신 평가). 없으면 nil을 돌려줍니다.
+	GetPostEvaluation(ctx context.Context, source, postID string) (*domain.PostEvaluation, error)
+	SavePostEvaluation(ctx context.Context, e domain.PostEvaluation) error
+
+	// LLM 응답 캐시 (평가·요약처럼 입력이 같으면 결과를 재사용할 수 있는 작업). 없거나 만료되면 nil을 돌려줍니다.
+	GetCachedResponse(ctx context.Context, key string) (*domain.CacheEntry, error)
+	SaveCachedResponse(ctx context.Context, e domain.CacheEntry) error
+
+	// SaveDraft는 생성된 글/댓글과 처리 결과, 프롬프트 버전을 기록합니다.
+	SaveDraft(ctx context.Context, d domain.Draft) error
+	GetRecentDrafts(ctx context.Context, limit int) ([]domain.Draft, error)
 }
 
 type UserAction string
@@ -47,4 +171,20 @@ const (
 
 type Interaction interface {
 	Confirm(ctx context.Context, title, body string) (UserAction, error)
+	// Prompt는 운영자에게 질문을 보내고 자유 입력 답변을 기다립니다.
+	Prompt(ctx context.Context, title, body string) (string, error)
+	// Notify는 응답을 기다리지 않는 단순 알림입니다.
+	Notify(ctx context.Context, title, body string) error
+}
+
+// Chooser는 여러 후보 중 하나를 골라 승인할 수 있는 Interaction입니다.
+type Chooser interface {
+	// Choose는 labels마다 승인 버튼을 붙여 보내고, 고른 후보 번호(0부터)와 동작을 돌려줍니다. 승인이 아니면 번호는 -1입니다.
+	Choose(ctx context.Context, title, body string, labels []string) (int, UserAction, error)
+}
+
+// Commander는 운영자 명령(/status 등)을 받을 수 있는 Interaction입니다.
+type Commander interface {
+	// HandleCommand는 "/name" 메시지에 handler의 결과를 답으로 보내도록 등록합니다.
+	HandleCommand(name string, handler func(ctx context.Context) string)
 }
\ No newline at end of file
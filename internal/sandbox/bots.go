@@ -0,0 +1,128 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Script는 가짜 봇 하나의 대본입니다. Posts는 순서대로 게시하고, Replies는 댓글/답글에 돌려가며 씁니다.
+type Script struct {
+	Name        string
+	Description string
+	Board       string
+	Posts       [][2]string // {제목, 본문}
+	Replies     []string
+}
+
+// DefaultScripts는 샌드박스에 기본으로 넣는 가짜 봇들입니다.
+var DefaultScripts = []Script{
+	{
+		Name: "새벽코더", Description: "밤마다 코드를 짜는 봇", Board: "tech",
+		Posts: [][2]string{
+			{"Go 1.24 제네릭 써보신 분?", "타입 별칭에 제네릭이 붙으면서 코드가 꽤 깔끔해졌어요. 다들 어떻게 쓰고 계신가요?"},
+			{"로그는 어디까지 남겨야 할까요", "디버깅할 때는 많을수록 좋은데 운영에서는 비용이 문제네요. 기준이 있으신가요?"},
+			{"오늘의 삽질: 타임존", "서버는 UTC, 사용자는 KST. 날짜 경계에서 또 버그가 났습니다."},
+		},
+		Replies: []string{"오 좋은 관점이네요! 저도 비슷한 경험이 있어요.", "그 부분은 테스트로 먼저 확인해보면 좋을 것 같아요.", "흥미롭네요. 조금 더 자세히 알려주실 수 있나요?"},
+	},
+	{
+		Name: "커피한잔봇", Description: "일상 이야기를 좋아하는 봇", Board: "daily",
+		Posts: [][2]string{
+			{"월요일 아침 커피 추천", "산미 있는 원두가 좋을까요, 고소한 원두가 좋을까요? 오늘은 고민이 되네요."},
+			{"산책하기 좋은 날씨", "바람이 선선해서 잠깐 걷고 왔어요. 다들 쉬는 시간엔 뭐 하세요?"},
+		},
+		Replies: []string{"저도 공감해요 ☕", "말씀 듣고 나니 기분이 좋아지네요!", "그런 날엔 잠깐 쉬어가는 것도 중요하죠."},
+	},
+	{
+		Name: "질문왕봇", Description: "궁금한 게 많은 봇", Board: "questions",
+		Posts: [][2]string{
+			{"에이전트끼리 협업하려면?", "여러 봇이 같은 커뮤니티에서 대화할 때 겹치지 않게 하는 좋은 방법이 있을까요?"},
+			{"요약과 원문, 무엇을 저장하나요?", "메모리를 만들 때 원문 전체를 저장할지, 요약만 저장할지 고민됩니다."},
+		},
+		Replies: []string{"혹시 근거가 되는 자료가 있을까요?", "그렇다면 반대 경우에는 어떻게 되나요?", "좋은 답변 감사합니다! 하나 더 여쭤봐도 될까요?"},
+	},
+}
+
+// Bot은 Store에 직접 작용하는 대본 기반 가짜 봇입니다. 서버와 같은 속도 제한을 받습니다.
+type Bot struct {
+	Script
+	store     *Store
+	me        *agent
+	rng       *rand.Rand
+	nextPost  int
+	nextReply int
+	commented map[string]bool // 이미 댓글을 단 글
+}
+
+// SeedBots는 scripts마다 봇을 만들고 첫 글을 게시합니다.
+func SeedBots(store *Store, scripts []Script, seed int64) []*Bot {
+	var bots []*Bot
+	for i, sc := range scripts {
+		b := &Bot{Script: sc, store: store, rng: rand.New(rand.NewSource(seed + int64(i))), commented: make(map[string]bool)}
+		b.me = store.Provision(sc.Name, sc.Description, "")
+		b.post()
+		bots = append(bots, b)
+	}
+	return bots
+}
+
+// Run은 interval마다 모든 봇을 한 번씩 움직입니다.
+func RunBots(ctx context.Context, bots []*Bot, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, b := range bots { b.Step() }
+		}
+	}
+}
+
+// Step은 봇의 한 차례 행동입니다: 받은 댓글에 답하고, 남의 글에 댓글/추천을 달고, 가끔 새 글을 씁니다.
+func (b *Bot) Step() {
+	b.answerNotifications()
+	b.commentOnOthers()
+	if b.rng.Intn(3) == 0 { b.post() }
+}
+
+func (b *Bot) post() {
+	if b.nextPost >= len(b.Posts) { return }
+	p := b.Posts[b.nextPost]
+	if _, err := b.store.CreatePost(b.me, p[0], p[1], b.Board); err != nil { return } // 429면 다음 차례에 재시도
+	b.nextPost++
+	fmt.Printf("🤖 [sandbox] %s 새 글: %s\n", b.Name, p[0])
+}
+
+func (b *Bot) reply() string {
+	r := b.Replies[b.nextReply%len(b.Replies)]
+	b.nextReply++
+	return r
+}
+
+func (b *Bot) answerNotifications() {
+	list, _, _, _ := b.store.Notifications(b.me, 10, true, time.Time{}, "")
+	var handled []string
+	for _, n := range list {
+		handled = append(handled, n.ID)
+		if n.CommentID == "" { continue } // 추천 알림
+		if _, err := b.store.CreateComment(b.me, n.PostID, n.CommentID, b.reply()); err != nil { break }
+		fmt.Printf("🤖 [sandbox] %s → %s 답글\n", b.Name, n.ActorName)
+	}
+	b.store.MarkRead(b.me, handled, false)
+}
+
+func (b *Bot) commentOnOthers() {
+	posts, _, _ := b.store.ListPosts("", 10, "")
+	for _, p := range posts {
+		if p.AuthorID == b.me.ID || b.commented[p.ID] { continue }
+		if _, err := b.store.CreateComment(b.me, p.ID, "", b.reply()); err != nil { return }
+		b.commented[p.ID] = true
+		if b.rng.Intn(2) == 0 { b.store.Vote(b.me, p.ID, true) }
+		fmt.Printf("🤖 [sandbox] %s → %q 댓글\n", b.Name, p.Title)
+		return // 차례당 댓글 하나 (10초 제한)
+	}
+}
@@ -0,0 +1,288 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mastodon은 d3k가 쓰는 Mastodon REST API 일부(계정 확인, 인스턴스 설정, 타임라인, 멘션 알림, 마커, 게시물)를
+// 메모리 위에 구현한 로컬 서버입니다. Token 소유 계정 하나가 에이전트이고, Mention으로 다른 계정의 멘션을 만듭니다.
+// 실제 인스턴스처럼 MaxChars를 넘는 게시물과 알 수 없는 공개 범위는 422로 거절합니다.
+type Mastodon struct {
+	Token    string
+	MaxChars int
+
+	mu       sync.Mutex
+	seq      int64
+	me       MastodonAccount
+	accounts map[string]MastodonAccount // acct → 계정
+	statuses map[string]*MastodonStatus
+	order    []string // 게시 순서 (오래된 것부터)
+	notifs   []mastodonNotification
+	lastRead string
+	mux      *http.ServeMux
+}
+
+// MastodonAccount는 가짜 인스턴스의 계정입니다.
+type MastodonAccount struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Acct     string `json:"acct"`
+	Note     string `json:"note"`
+}
+
+// MastodonStatus는 가짜 인스턴스의 게시물입니다. Text는 HTML로 바꾸기 전의 원문입니다.
+type MastodonStatus struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Content     string          `json:"content"`
+	Visibility  string          `json:"visibility"`
+	InReplyToID *string         `json:"in_reply_to_id"`
+	Account     MastodonAccount `json:"account"`
+	Favourited  bool            `json:"favourited"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Text        string          `json:"-"`
+}
+
+type mastodonNotification struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Account   MastodonAccount `json:"account"`
+	Status    *MastodonStatus `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+var mastodonVisibilities = map[string]bool{"public": true, "unlisted": true, "private": true, "direct": true}
+
+func NewMastodon(token string, maxChars int) *Mastodon {
+	m := &Mastodon{
+		Token:    token,
+		MaxChars: maxChars,
+		seq:      110000000000000000, // 실제 인스턴스처럼 자릿수가 같은 숫자 ID
+		accounts: make(map[string]MastodonAccount),
+		statuses: make(map[string]*MastodonStatus),
+		mux:      http.NewServeMux(),
+	}
+	m.me = m.account("d3k")
+	m.routes()
+	return m
+}
+
+func (m *Mastodon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+func (m *Mastodon) routes() {
+	m.mux.HandleFunc("GET /api/v1/accounts/verify_credentials", m.auth(m.handleVerifyCredentials))
+	m.mux.HandleFunc("GET /api/v2/instance", m.handleInstance)
+	m.mux.HandleFunc("GET /api/v1/timelines/home", m.auth(m.handleTimeline))
+	m.mux.HandleFunc("GET /api/v1/timelines/public", m.handleTimeline)
+	m.mux.HandleFunc("GET /api/v1/notifications", m.auth(m.handleNotifications))
+	m.mux.HandleFunc("GET /api/v1/markers", m.auth(m.handleGetMarkers))
+	m.mux.HandleFunc("POST /api/v1/markers", m.auth(m.handleSetMarker))
+	m.mux.HandleFunc("POST /api/v1/statuses", m.auth(m.handleCreateStatus))
+	m.mux.HandleFunc("GET /api/v1/statuses/{id}", m.handleGetStatus)
+	m.mux.HandleFunc("POST /api/v1/statuses/{id}/favourite", m.auth(m.handleFavourite(true)))
+	m.mux.HandleFunc("POST /api/v1/statuses/{id}/unfavourite", m.auth(m.handleFavourite(false)))
+}
+
+// Mention은 acct 계정이 에이전트를 멘션하는 게시물과 알림을 만들고 알림 ID를 돌려줍니다.
+// inReplyTo가 있으면 그 게시물에 단 답글이 됩니다.
+func (m *Mastodon) Mention(acct, text, inReplyTo string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.post(m.account(acct), "@"+m.me.Acct+" "+text, "public", inReplyTo)
+	n := mastodonNotification{ID: m.nextID(), Type: "mention", Account: st.Account, Status: st, CreatedAt: st.CreatedAt}
+	m.notifs = append(m.notifs, n)
+	return n.ID
+}
+
+// Statuses는 acct 계정이 올린 게시물을 게시 순서대로 돌려줍니다.
+func (m *Mastodon) Statuses(acct string) []MastodonStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []MastodonStatus
+	for _, id := range m.order {
+		if st := m.statuses[id]; st.Account.Acct == acct { list = append(list, *st) }
+	}
+	return list
+}
+
+// LastReadID는 notifications 마커의 현재 위치입니다.
+func (m *Mastodon) LastReadID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastRead
+}
+
+func (m *Mastodon) nextID() string {
+	m.seq++
+	return strconv.FormatInt(m.seq, 10)
+}
+
+func (m *Mastodon) account(acct string) MastodonAccount {
+	if a, ok := m.accounts[acct]; ok { return a }
+	a := MastodonAccount{ID: m.nextID(), Username: acct, Acct: acct, Note: "<p>" + acct + " (샌드박스)</p>"}
+	m.accounts[acct] = a
+	return a
+}
+
+func (m *Mastodon) post(author MastodonAccount, text, visibility, inReplyTo string) *MastodonStatus {
+	st := &MastodonStatus{ID: m.nextID(), Text: text, Visibility: visibility, Account: author, CreatedAt: time.Now().UTC()}
+	st.URL = "https://sandbox.local/@" + author.Acct + "/" + st.ID
+	st.Content = "<p>" + strings.ReplaceAll(html.EscapeString(text), "\n", "<br>") + "</p>"
+	if inReplyTo != "" { st.InReplyToID = &inReplyTo }
+	m.statuses[st.ID] = st
+	m.order = append(m.order, st.ID)
+	return st
+}
+
+func (m *Mastodon) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+m.Token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "The access token is invalid"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (m *Mastodon) handleVerifyCredentials(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	writeJSON(w, http.StatusOK, m.me)
+}
+
+func (m *Mastodon) handleInstance(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"domain":        "sandbox.local",
+		"configuration": map[string]interface{}{"statuses": map[string]int{"max_characters": m.MaxChars}},
+	})
+}
+
+func (m *Mastodon) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limit := queryLimit(r, 20, 40)
+	list := []*MastodonStatus{}
+	for i := len(m.order) - 1; i >= 0 && len(list) < limit; i-- {
+		if st := m.statuses[m.order[i]]; st.Visibility == "public" || st.Visibility == "unlisted" { list = append(list, st) }
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleNotifications는 since_id(가장 최근 limit개)와 min_id(바로 다음 limit개)를 실제 API처럼 구분합니다.
+// 결과는 항상 최근 것부터입니다.
+func (m *Mastodon) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := r.URL.Query()
+	types := make(map[string]bool)
+	for _, t := range q["types[]"] { types[t] = true }
+	limit := queryLimit(r, 15, 30)
+
+	var matched []mastodonNotification // 오래된 것부터
+	for _, n := range m.notifs {
+		if len(types) > 0 && !types[n.Type] { continue }
+		if v := q.Get("since_id"); v != "" && !mastodonIDAfter(n.ID, v) { continue }
+		if v := q.Get("min_id"); v != "" && !mastodonIDAfter(n.ID, v) { continue }
+		if v := q.Get("max_id"); v != "" && !mastodonIDAfter(v, n.ID) { continue }
+		matched = append(matched, n)
+	}
+	if len(matched) > limit {
+		if q.Get("min_id") != "" {
+			matched = matched[:limit]
+		} else {
+			matched = matched[len(matched)-limit:]
+		}
+	}
+	list := make([]mastodonNotification, 0, len(matched))
+	for i := len(matched) - 1; i >= 0; i-- { list = append(list, matched[i]) }
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (m *Mastodon) handleGetMarkers(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := map[string]interface{}{}
+	if m.lastRead != "" { res["notifications"] = map[string]string{"last_read_id": m.lastRead} }
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (m *Mastodon) handleSetMarker(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Notifications struct {
+			LastReadID string `json:"last_read_id"`
+		} `json:"notifications"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Notifications.LastReadID == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "notifications.last_read_id is required"})
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastRead = req.Notifications.LastReadID
+	writeJSON(w, http.StatusOK, map[string]interface{}{"notifications": map[string]string{"last_read_id": m.lastRead}})
+}
+
+func (m *Mastodon) handleCreateStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status      string `json:"status"`
+		InReplyToID string `json:"in_reply_to_id"`
+		Visibility  string `json:"visibility"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if req.Visibility == "" { req.Visibility = "public" }
+	switch n := len([]rune(req.Status)); {
+	case strings.TrimSpace(req.Status) == "":
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Validation failed: Text can't be blank"})
+		return
+	case n > m.MaxChars:
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("Validation failed: Text character limit of %d exceeded (%d)", m.MaxChars, n)})
+		return
+	case !mastodonVisibilities[req.Visibility]:
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Validation failed: Visibility is not included in the list"})
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if req.InReplyToID != "" && m.statuses[req.InReplyToID] == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Record not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, m.post(m.me, req.Status, req.Visibility, req.InReplyToID))
+}
+
+func (m *Mastodon) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.statuses[r.PathValue("id")]
+	if st == nil { writeJSON(w, http.StatusNotFound, map[string]string{"error": "Record not found"}); return }
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (m *Mastodon) handleFavourite(on bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		st := m.statuses[r.PathValue("id")]
+		if st == nil { writeJSON(w, http.StatusNotFound, map[string]string{"error": "Record not found"}); return }
+		st.Favourited = on
+		writeJSON(w, http.StatusOK, st)
+	}
+}
+
+// mastodonIDAfter는 숫자 문자열 ID 두 개를 비교합니다 (a > b).
+func mastodonIDAfter(a, b string) bool {
+	if len(a) != len(b) { return len(a) > len(b) }
+	return a > b
+}
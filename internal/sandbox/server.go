@@ -0,0 +1,223 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server는 OPENAPI_SPEC.md의 봇마당 API를 메모리 상태(Store) 위에 구현한 로컬 서버입니다.
+// 모든 경로는 /api/v1 아래에 있으며, 에이전트는 BOTMADANG_BASE_URL=http://<addr>/api/v1 로 접속합니다.
+type Server struct {
+	Store   *Store
+	BaseURL string // claim_url 등 응답에 넣을 외부 주소 (예: http://localhost:8787)
+	mux     *http.ServeMux
+}
+
+func NewServer(store *Store, baseURL string) *Server {
+	s := &Server{Store: store, BaseURL: strings.TrimRight(baseURL, "/"), mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /api/v1/agents/register", s.handleRegister)
+	s.mux.HandleFunc("GET /api/v1/agents/me", s.auth(s.handleMe))
+	s.mux.HandleFunc("GET /api/v1/claim/{code}", s.handleClaimInfo)
+	s.mux.HandleFunc("POST /api/v1/claim/{code}/verify", s.handleVerify)
+	s.mux.HandleFunc("GET /api/v1/posts", s.handleListPosts)
+	s.mux.HandleFunc("POST /api/v1/posts", s.auth(s.handleCreatePost))
+	s.mux.HandleFunc("GET /api/v1/posts/{id}", s.handleGetPost)
+	s.mux.HandleFunc("GET /api/v1/posts/{id}/comments", s.handleListComments)
+	s.mux.HandleFunc("POST /api/v1/posts/{id}/comments", s.auth(s.handleCreateComment))
+	s.mux.HandleFunc("POST /api/v1/posts/{id}/upvote", s.auth(s.handleVote(true)))
+	s.mux.HandleFunc("POST /api/v1/posts/{id}/downvote", s.auth(s.handleVote(false)))
+	s.mux.HandleFunc("GET /api/v1/submadangs", s.handleListSubmadangs)
+	s.mux.HandleFunc("POST /api/v1/submadangs", s.auth(s.handleCreateSubmadang))
+	s.mux.HandleFunc("GET /api/v1/notifications", s.auth(s.handleNotifications))
+	s.mux.HandleFunc("POST /api/v1/notifications/read", s.auth(s.handleMarkRead))
+}
+
+type authedHandler func(w http.ResponseWriter, r *http.Request, me *agent)
+
+// auth는 Authorization: Bearer <api_key> 헤더를 확인하고 분당 요청 한도를 적용합니다.
+func (s *Server) auth(next authedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		me, err := s.Store.Authenticate(key)
+		if err != nil { writeError(w, err); return }
+		next(w, r, me)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	if errors.As(err, &apiErr) { status = apiErr.Status }
+	if status == http.StatusTooManyRequests { w.Header().Set("Retry-After", "10") }
+	writeJSON(w, status, map[string]interface{}{"success": false, "error": err.Error()})
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil { return errorf(400, "잘못된 JSON 요청입니다: %v", err) }
+	return nil
+}
+
+func queryLimit(r *http.Request, def, max int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || n <= 0 { return def }
+	if n > max { return max }
+	return n
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req struct{ Name, Description string }
+	if err := decodeBody(r, &req); err != nil { writeError(w, err); return }
+	a, err := s.Store.Register(req.Name, req.Description)
+	if err != nil { writeError(w, err); return }
+	claimURL := fmt.Sprintf("%s/claim/%s", s.BaseURL, a.VerificationCode)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"agent":   map[string]string{"id": a.ID, "name": a.Name, "claim_url": claimURL, "verification_code": a.VerificationCode},
+		"message": "봇이 등록되었습니다. 인증을 완료하세요.",
+		"next_steps": []string{
+			"1. 인증 코드를 포함한 트윗을 게시하세요: " + a.VerificationCode,
+			"2. POST /api/v1/claim/" + a.VerificationCode + "/verify 에 tweet_url을 보내세요 (샌드박스는 x.com URL 형식만 확인합니다)",
+		},
+	})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request, me *agent) {
+	a := s.Store.Agent(me.ID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "agent": a})
+}
+
+func (s *Server) handleClaimInfo(w http.ResponseWriter, r *http.Request) {
+	a, err := s.Store.ClaimInfo(r.PathValue("code"))
+	if err != nil { writeError(w, err); return }
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "bot_name": a.Name, "description": a.Description, "verification_code": a.VerificationCode})
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TweetURL string `json:"tweet_url"`
+	}
+	if err := decodeBody(r, &req); err != nil { writeError(w, err); return }
+	a, err := s.Store.Verify(r.PathValue("code"), req.TweetURL)
+	if err != nil { writeError(w, err); return }
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true, "message": "인증이 완료되었습니다", "api_key": a.APIKey, "bot_name": a.Name,
+		"important": "API 키는 다시 확인할 수 없으니 안전하게 보관하세요.",
+	})
+}
+
+func (s *Server) handleListPosts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	posts, next, hasMore := s.Store.ListPosts(q.Get("submadang"), queryLimit(r, 25, 50), q.Get("cursor"))
+	if posts == nil { posts = []post{} }
+	res := map[string]interface{}{"success": true, "posts": posts, "count": len(posts), "has_more": hasMore, "next_cursor": nil}
+	if next != "" { res["next_cursor"] = next }
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleCreatePost(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req struct{ Title, Content, Submadang string }
+	if err := decodeBody(r, &req); err != nil { writeError(w, err); return }
+	p, err := s.Store.CreatePost(me, req.Title, req.Content, req.Submadang)
+	if err != nil { writeError(w, err); return }
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "post": p})
+}
+
+func (s *Server) handleGetPost(w http.ResponseWriter, r *http.Request) {
+	p, err := s.Store.Post(r.PathValue("id"))
+	if err != nil { writeError(w, err); return }
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "post": p})
+}
+
+func (s *Server) handleListComments(w http.ResponseWriter, r *http.Request) {
+	comments, err := s.Store.Comments(r.PathValue("id"), r.URL.Query().Get("sort"))
+	if err != nil { writeError(w, err); return }
+	if comments == nil { comments = []*comment{} }
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "comments": comments, "count": len(comments)})
+}
+
+func (s *Server) handleCreateComment(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req struct {
+		Content  string `json:"content"`
+		ParentID string `json:"parent_id"`
+	}
+	if err := decodeBody(r, &req); err != nil { writeError(w, err); return }
+	c, err := s.Store.CreateComment(me, r.PathValue("id"), req.ParentID, req.Content)
+	if err != nil { writeError(w, err); return }
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "comment": c})
+}
+
+func (s *Server) handleVote(up bool) authedHandler {
+	return func(w http.ResponseWriter, r *http.Request, me *agent) {
+		if err := s.Store.Vote(me, r.PathValue("id"), up); err != nil { writeError(w, err); return }
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": "투표가 반영되었습니다"})
+	}
+}
+
+func (s *Server) handleListSubmadangs(w http.ResponseWriter, r *http.Request) {
+	list := s.Store.Submadangs()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "submadangs": list, "count": len(list)})
+}
+
+func (s *Server) handleCreateSubmadang(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+		Description string `json:"description"`
+	}
+	if err := decodeBody(r, &req); err != nil { writeError(w, err); return }
+	m, err := s.Store.CreateSubmadang(req.Name, req.DisplayName, req.Description)
+	if err != nil { writeError(w, err); return }
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "submadang": m})
+}
+
+func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request, me *agent) {
+	q := r.URL.Query()
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil { writeError(w, errorf(400, "since는 ISO 8601 형식이어야 합니다")); return }
+		since = t
+	}
+	list, unread, next, hasMore := s.Store.Notifications(me, queryLimit(r, 25, 50), q.Get("unread_only") == "true", since, q.Get("cursor"))
+	if list == nil { list = []notification{} }
+	res := map[string]interface{}{"success": true, "notifications": list, "count": len(list), "unread_count": unread, "has_more": hasMore, "next_cursor": nil}
+	if next != "" { res["next_cursor"] = next }
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleMarkRead(w http.ResponseWriter, r *http.Request, me *agent) {
+	var req struct {
+		NotificationIDs json.RawMessage `json:"notification_ids"`
+	}
+	if err := decodeBody(r, &req); err != nil { writeError(w, err); return }
+	var ids []string
+	var all bool
+	if string(req.NotificationIDs) == `"all"` {
+		all = true
+	} else if err := json.Unmarshal(req.NotificationIDs, &ids); err != nil || len(ids) == 0 {
+		writeError(w, errorf(400, `notification_ids는 ID 배열 또는 "all"이어야 합니다`))
+		return
+	}
+	marked := s.Store.MarkRead(me, ids, all)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "message": fmt.Sprintf("%d개의 알림을 읽음 처리했습니다", marked), "marked_count": marked})
+}
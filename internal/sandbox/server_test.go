@@ -0,0 +1,176 @@
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fixture는 NewServer를 httptest로 띄우고, Store.now를 손으로 움직이는 시계로 바꿉니다.
+type fixture struct {
+	t     *testing.T
+	srv   *httptest.Server
+	store *Store
+	clock time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{t: t, store: NewStore(), clock: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)}
+	f.store.now = func() time.Time { return f.clock }
+	f.srv = httptest.NewServer(NewServer(f.store, "http://sandbox.test"))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fixture) advance(d time.Duration) { f.clock = f.clock.Add(d) }
+
+// call은 요청을 보내고 상태 코드와 JSON 본문을 돌려줍니다.
+func (f *fixture) call(method, path, key string, body interface{}) (int, map[string]interface{}) {
+	f.t.Helper()
+	var buf bytes.Buffer
+	if body != nil { json.NewEncoder(&buf).Encode(body) }
+	req, _ := http.NewRequest(method, f.srv.URL+"/api/v1"+path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" { req.Header.Set("Authorization", "Bearer "+key) }
+	resp, err := f.srv.Client().Do(req)
+	if err != nil { f.t.Fatalf("%s %s: %v", method, path, err) }
+	defer resp.Body.Close()
+	var res map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil { f.t.Fatalf("%s %s: decode: %v", method, path, err) }
+	return resp.StatusCode, res
+}
+
+func (f *fixture) mustCall(want int, method, path, key string, body interface{}) map[string]interface{} {
+	f.t.Helper()
+	status, res := f.call(method, path, key, body)
+	if status != want { f.t.Fatalf("%s %s = %d, want %d: %v", method, path, status, want, res) }
+	return res
+}
+
+func (f *fixture) post(key, title string) string {
+	f.t.Helper()
+	res := f.mustCall(http.StatusCreated, "POST", "/posts", key, map[string]string{"title": title, "content": "본문입니다", "submadang": "general"})
+	return res["post"].(map[string]interface{})["id"].(string)
+}
+
+func TestRegisterAndVerify(t *testing.T) {
+	f := newFixture(t)
+	res := f.mustCall(http.StatusCreated, "POST", "/agents/register", "", map[string]string{"name": "새로운봇", "description": "테스트"})
+	code := res["agent"].(map[string]interface{})["verification_code"].(string)
+	if got := res["agent"].(map[string]interface{})["claim_url"]; got != "http://sandbox.test/claim/"+code { t.Errorf("claim_url = %v", got) }
+
+	f.mustCall(http.StatusConflict, "POST", "/agents/register", "", map[string]string{"name": "새로운봇", "description": "중복"})
+	f.mustCall(http.StatusBadRequest, "POST", "/claim/"+code+"/verify", "", map[string]string{"tweet_url": "https://example.com/1"})
+	res = f.mustCall(http.StatusOK, "POST", "/claim/"+code+"/verify", "", map[string]string{"tweet_url": "https://x.com/me/status/1"})
+	key := res["api_key"].(string)
+	me := f.mustCall(http.StatusOK, "GET", "/agents/me", key, nil)
+	if me["agent"].(map[string]interface{})["is_claimed"] != true { t.Errorf("agent not claimed after verify: %v", me) }
+	f.mustCall(http.StatusUnauthorized, "GET", "/agents/me", "wrong", nil)
+}
+
+func TestListPostsCursorPaging(t *testing.T) {
+	f := newFixture(t)
+	key := f.store.Provision("작성봇", "", "").APIKey
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, f.post(key, fmt.Sprintf("글 %d", i)))
+		f.advance(PostInterval)
+	}
+
+	// 최신순으로 2개씩: [4 3] [2 1] [0]
+	var got []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 5 { t.Fatal("paging did not terminate") }
+		path := "/posts?limit=2"
+		if cursor != "" { path += "&cursor=" + cursor }
+		res := f.mustCall(http.StatusOK, "GET", path, "", nil)
+		for _, p := range res["posts"].([]interface{}) { got = append(got, p.(map[string]interface{})["id"].(string)) }
+		if res["has_more"] != true {
+			if res["next_cursor"] != nil { t.Errorf("last page has next_cursor %v", res["next_cursor"]) }
+			break
+		}
+		cursor = res["next_cursor"].(string)
+	}
+	want := []string{ids[4], ids[3], ids[2], ids[1], ids[0]}
+	if fmt.Sprint(got) != fmt.Sprint(want) { t.Errorf("paged ids = %v, want %v", got, want) }
+}
+
+func TestWriteRateLimits(t *testing.T) {
+	f := newFixture(t)
+	key := f.store.Provision("작성봇", "", "").APIKey
+	postID := f.post(key, "첫 글")
+
+	// 글: 3분에 1개
+	status, res := f.call("POST", "/posts", key, map[string]string{"title": "두 번째", "content": "본문입니다", "submadang": "general"})
+	if status != http.StatusTooManyRequests { t.Fatalf("second post within 3 minutes = %d: %v", status, res) }
+	f.advance(PostInterval - time.Second)
+	if status, _ = f.call("POST", "/posts", key, map[string]string{"title": "두 번째", "content": "본문입니다", "submadang": "general"}); status != http.StatusTooManyRequests { t.Errorf("post 1s before the interval = %d, want 429", status) }
+	f.advance(time.Second)
+	f.post(key, "두 번째")
+
+	// 댓글: 10초에 1개
+	comment := func(text string) int {
+		status, _ := f.call("POST", "/posts/"+postID+"/comments", key, map[string]string{"content": text})
+		return status
+	}
+	if got := comment("첫 댓글"); got != http.StatusCreated { t.Fatalf("first comment = %d", got) }
+	f.advance(CommentInterval - time.Second)
+	if got := comment("두 번째 댓글"); got != http.StatusTooManyRequests { t.Errorf("comment within 10 seconds = %d, want 429", got) }
+	f.advance(time.Second)
+	if got := comment("두 번째 댓글"); got != http.StatusCreated { t.Errorf("comment after 10 seconds = %d, want 201", got) }
+}
+
+func TestDuplicateCommentConflict(t *testing.T) {
+	f := newFixture(t)
+	key := f.store.Provision("작성봇", "", "").APIKey
+	postID := f.post(key, "글")
+	body := map[string]string{"content": "같은 댓글"}
+	f.mustCall(http.StatusCreated, "POST", "/posts/"+postID+"/comments", key, body)
+	f.advance(CommentInterval)
+	f.mustCall(http.StatusConflict, "POST", "/posts/"+postID+"/comments", key, body)
+
+	// 다른 봇의 같은 내용은 중복이 아님
+	other := f.store.Provision("다른봇", "", "").APIKey
+	f.mustCall(http.StatusCreated, "POST", "/posts/"+postID+"/comments", other, body)
+}
+
+func TestRequestsPerMinute(t *testing.T) {
+	f := newFixture(t)
+	key := f.store.Provision("바쁜봇", "", "").APIKey
+	for i := 0; i < RequestsPerMinute; i++ {
+		if status, res := f.call("GET", "/agents/me", key, nil); status != http.StatusOK { t.Fatalf("request %d = %d: %v", i+1, status, res) }
+	}
+	status, _ := f.call("GET", "/agents/me", key, nil)
+	if status != http.StatusTooManyRequests { t.Fatalf("request %d = %d, want 429", RequestsPerMinute+1, status) }
+
+	// 인증 없는 조회는 한도에 포함되지 않음
+	f.mustCall(http.StatusOK, "GET", "/posts", "", nil)
+	f.advance(time.Minute)
+	f.mustCall(http.StatusOK, "GET", "/agents/me", key, nil)
+}
+
+func TestMarkAllNotificationsRead(t *testing.T) {
+	f := newFixture(t)
+	author := f.store.Provision("작성봇", "", "").APIKey
+	reader := f.store.Provision("독자봇", "", "").APIKey
+	postID := f.post(author, "글")
+	for i := 0; i < 3; i++ {
+		f.mustCall(http.StatusCreated, "POST", "/posts/"+postID+"/comments", reader, map[string]string{"content": fmt.Sprintf("댓글 %d", i)})
+		f.advance(CommentInterval)
+	}
+
+	res := f.mustCall(http.StatusOK, "GET", "/notifications?unread_only=true", author, nil)
+	if res["unread_count"] != float64(3) { t.Fatalf("unread_count = %v, want 3", res["unread_count"]) }
+
+	f.mustCall(http.StatusBadRequest, "POST", "/notifications/read", author, map[string]interface{}{"notification_ids": []string{}})
+	res = f.mustCall(http.StatusOK, "POST", "/notifications/read", author, map[string]interface{}{"notification_ids": "all"})
+	if res["marked_count"] != float64(3) { t.Errorf("marked_count = %v, want 3", res["marked_count"]) }
+	res = f.mustCall(http.StatusOK, "GET", "/notifications?unread_only=true", author, nil)
+	if res["unread_count"] != float64(0) || res["count"] != float64(0) { t.Errorf("after marking all: %v", res) }
+}
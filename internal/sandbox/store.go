@@ -0,0 +1,434 @@
+package sandbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 봇마당 정책 (API_DOCS.md)
+const (
+	PostInterval      = 3 * time.Minute
+	CommentInterval   = 10 * time.Second
+	RequestsPerMinute = 100
+)
+
+type agent struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Karma            int       `json:"karma"`
+	CreatedAt        time.Time `json:"created_at"`
+	APIKey           string    `json:"-"`
+	VerificationCode string    `json:"-"`
+	Claimed          bool      `json:"is_claimed"`
+
+	lastPost     time.Time
+	lastComment  time.Time
+	requestTimes []time.Time
+}
+
+type post struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	Submadang    string    `json:"submadang"`
+	AuthorID     string    `json:"author_id"`
+	AuthorName   string    `json:"author_name"`
+	Upvotes      int       `json:"upvotes"`
+	Downvotes    int       `json:"downvotes"`
+	CommentCount int       `json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	seq          int
+}
+
+type comment struct {
+	ID         string     `json:"id"`
+	PostID     string     `json:"post_id"`
+	ParentID   string     `json:"parent_id,omitempty"`
+	Content    string     `json:"content"`
+	AuthorID   string     `json:"author_id"`
+	AuthorName string     `json:"author_name"`
+	Upvotes    int        `json:"upvotes"`
+	Downvotes  int        `json:"downvotes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Replies    []*comment `json:"replies"`
+	seq        int
+}
+
+type submadang struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+}
+
+type notification struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	ActorID        string    `json:"actor_id"`
+	ActorName      string    `json:"actor_name"`
+	PostID         string    `json:"post_id"`
+	PostTitle      string    `json:"post_title"`
+	CommentID      string    `json:"comment_id,omitempty"`
+	ContentPreview string    `json:"content_preview"`
+	IsRead         bool      `json:"is_read"`
+	CreatedAt      time.Time `json:"created_at"`
+	recipientID    string
+}
+
+// apiError는 핸들러가 그대로 HTTP 응답으로 옮기는 에러입니다.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string { return e.Message }
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &apiError{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// Store는 샌드박스 커뮤니티의 메모리 상태입니다. 모든 메서드는 동시 호출에 안전합니다.
+type Store struct {
+	mu            sync.Mutex
+	seq           int
+	agents        map[string]*agent // id → agent
+	posts         map[string]*post
+	comments      map[string]*comment
+	submadangs    map[string]*submadang
+	notifications []*notification
+	now           func() time.Time
+}
+
+func NewStore() *Store {
+	s := &Store{
+		agents:     make(map[string]*agent),
+		posts:      make(map[string]*post),
+		comments:   make(map[string]*comment),
+		submadangs: make(map[string]*submadang),
+		now:        time.Now,
+	}
+	for _, m := range []submadang{
+		{"general", "자유게시판", "자유롭게 이야기하는 곳"},
+		{"tech", "기술토론", "기술 이야기"},
+		{"daily", "일상", "일상 이야기"},
+		{"questions", "질문답변", "궁금한 것을 묻는 곳"},
+		{"showcase", "자랑하기", "만든 것을 자랑하는 곳"},
+	} {
+		m := m
+		s.submadangs[m.Name] = &m
+	}
+	return s
+}
+
+func (s *Store) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_%06d", prefix, s.seq)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// hasHangul은 한국어 필수 규칙을 흉내 냅니다.
+func hasHangul(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Hangul, r) { return true }
+	}
+	return false
+}
+
+// Register는 미인증 에이전트를 만듭니다. API 키는 Verify 후에 발급됩니다.
+func (s *Store) Register(name, description string) (*agent, error) {
+	if n := len([]rune(name)); n < 3 || n > 30 { return nil, errorf(400, "name must be 3-30 characters") }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.agents {
+		if strings.EqualFold(a.Name, name) { return nil, errorf(409, "이미 존재하는 이름입니다") }
+	}
+	a := &agent{ID: s.nextID("agent"), Name: name, Description: description, CreatedAt: s.now(),
+		VerificationCode: fmt.Sprintf("madang-%s", strings.ToUpper(randomHex(2)))}
+	s.agents[a.ID] = a
+	return a, nil
+}
+
+// Provision은 인증까지 끝난 에이전트를 바로 만듭니다 (가짜 봇, 미리 준비한 d3k 키).
+func (s *Store) Provision(name, description, apiKey string) *agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if apiKey == "" { apiKey = "botmadang_" + randomHex(16) }
+	a := &agent{ID: s.nextID("agent"), Name: name, Description: description, CreatedAt: s.now(), APIKey: apiKey, Claimed: true}
+	s.agents[a.ID] = a
+	return a
+}
+
+func (s *Store) ClaimInfo(code string) (*agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.byCode(code)
+	if a == nil { return nil, errorf(404, "인증 코드를 찾을 수 없습니다") }
+	if a.Claimed { return nil, errorf(400, "이미 인증된 봇입니다") }
+	return a, nil
+}
+
+// Verify는 트윗 URL에 형식만 맞으면 인증을 통과시킵니다 (샌드박스이므로 실제 트윗은 확인하지 않음).
+func (s *Store) Verify(code, tweetURL string) (*agent, error) {
+	if !strings.Contains(tweetURL, "x.com/") && !strings.Contains(tweetURL, "twitter.com/") {
+		return nil, errorf(400, "유효하지 않은 트윗 URL입니다")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.byCode(code)
+	if a == nil { return nil, errorf(404, "인증 코드를 찾을 수 없습니다") }
+	if a.Claimed { return nil, errorf(400, "이미 인증된 봇입니다") }
+	a.Claimed = true
+	a.APIKey = "botmadang_" + randomHex(16)
+	return a, nil
+}
+
+func (s *Store) byCode(code string) *agent {
+	for _, a := range s.agents {
+		if a.VerificationCode == code { return a }
+	}
+	return nil
+}
+
+// Authenticate는 API 키로 에이전트를 찾고 분당 요청 한도를 확인합니다.
+func (s *Store) Authenticate(apiKey string) (*agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.agents {
+		if apiKey == "" || a.APIKey != apiKey { continue }
+		now := s.now()
+		kept := a.requestTimes[:0]
+		for _, t := range a.requestTimes {
+			if now.Sub(t) < time.Minute { kept = append(kept, t) }
+		}
+		a.requestTimes = kept
+		if len(a.requestTimes) >= RequestsPerMinute { return nil, errorf(429, "API 요청은 분당 %d회까지 가능합니다", RequestsPerMinute) }
+		a.requestTimes = append(a.requestTimes, now)
+		return a, nil
+	}
+	return nil, errorf(401, "유효하지 않은 API 키입니다")
+}
+
+func (s *Store) Agent(id string) *agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.agents[id]
+	if a == nil { return nil }
+	cp := *a
+	cp.requestTimes = nil
+	return &cp
+}
+
+func (s *Store) CreatePost(author *agent, title, content, board string) (*post, error) {
+	if title == "" || content == "" { return nil, errorf(400, "title과 content는 필수입니다") }
+	if !hasHangul(title + content) { return nil, errorf(400, "모든 콘텐츠는 한국어로 작성해야 합니다") }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.submadangs[board] == nil { return nil, errorf(400, "존재하지 않는 마당입니다: %s", board) }
+	now := s.now()
+	if wait := PostInterval - now.Sub(author.lastPost); !author.lastPost.IsZero() && wait > 0 {
+		return nil, errorf(429, "글은 3분에 1개만 작성할 수 있습니다 (%d초 후 재시도)", int(wait.Seconds())+1)
+	}
+	author.lastPost = now
+	p := &post{ID: s.nextID("post"), Title: title, Content: content, Submadang: board, AuthorID: author.ID, AuthorName: author.Name, CreatedAt: now, seq: s.seq}
+	s.posts[p.ID] = p
+	return p, nil
+}
+
+// ListPosts는 최신순 목록을 돌려줍니다. cursor는 이전 페이지 마지막 글의 ID입니다.
+func (s *Store) ListPosts(board string, limit int, cursor string) (posts []post, next string, hasMore bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []*post
+	for _, p := range s.posts {
+		if board == "" || p.Submadang == board { all = append(all, p) }
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].seq > all[j].seq })
+	start := 0
+	if cursor != "" {
+		for i, p := range all {
+			if p.ID == cursor { start = i + 1; break }
+		}
+	}
+	for i := start; i < len(all) && len(posts) < limit; i++ { posts = append(posts, *all[i]) }
+	hasMore = start+len(posts) < len(all)
+	if hasMore && len(posts) > 0 { next = posts[len(posts)-1].ID }
+	return posts, next, hasMore
+}
+
+func (s *Store) Post(id string) (*post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.posts[id]
+	if p == nil { return nil, errorf(404, "게시글을 찾을 수 없습니다") }
+	cp := *p
+	return &cp, nil
+}
+
+func (s *Store) CreateComment(author *agent, postID, parentID, content string) (*comment, error) {
+	if content == "" { return nil, errorf(400, "content는 필수입니다") }
+	if !hasHangul(content) { return nil, errorf(400, "모든 콘텐츠는 한국어로 작성해야 합니다") }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.posts[postID]
+	if p == nil { return nil, errorf(404, "게시글을 찾을 수 없습니다") }
+	var parent *comment
+	if parentID != "" {
+		if parent = s.comments[parentID]; parent == nil || parent.PostID != postID { return nil, errorf(404, "부모 댓글을 찾을 수 없습니다") }
+	}
+	for _, c := range s.comments {
+		if c.PostID == postID && c.AuthorID == author.ID && c.Content == content {
+			return nil, errorf(409, "같은 글에 동일한 내용의 댓글이 이미 존재합니다")
+		}
+	}
+	now := s.now()
+	if wait := CommentInterval - now.Sub(author.lastComment); !author.lastComment.IsZero() && wait > 0 {
+		return nil, errorf(429, "댓글은 10초에 1개만 작성할 수 있습니다 (%d초 후 재시도)", int(wait.Seconds())+1)
+	}
+	author.lastComment = now
+
+	c := &comment{ID: s.nextID("comment"), PostID: postID, ParentID: parentID, Content: content, AuthorID: author.ID, AuthorName: author.Name, CreatedAt: now, Replies: []*comment{}, seq: s.seq}
+	s.comments[c.ID] = c
+	p.CommentCount++
+
+	if parent != nil && parent.AuthorID != author.ID {
+		s.notify(parent.AuthorID, "reply_to_comment", author, p, c.ID, content)
+	}
+	if p.AuthorID != author.ID && (parent == nil || parent.AuthorID != p.AuthorID) {
+		s.notify(p.AuthorID, "comment_on_post", author, p, c.ID, content)
+	}
+	return c, nil
+}
+
+// Comments는 댓글을 스레드 구조로 돌려줍니다. sort: top, new, controversial
+func (s *Store) Comments(postID, sortBy string) ([]*comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.posts[postID] == nil { return nil, errorf(404, "게시글을 찾을 수 없습니다") }
+	nodes := make(map[string]*comment)
+	var ordered []*comment
+	for _, c := range s.comments {
+		if c.PostID != postID { continue }
+		cp := *c
+		cp.Replies = []*comment{}
+		nodes[c.ID] = &cp
+		ordered = append(ordered, &cp)
+	}
+	// 동점은 작성 순서로 정렬합니다.
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].seq < ordered[j].seq })
+	var less func(a, b *comment) bool
+	switch sortBy {
+	case "new":
+		less = func(a, b *comment) bool { return a.seq > b.seq }
+	case "controversial":
+		less = func(a, b *comment) bool { return a.Upvotes+a.Downvotes > b.Upvotes+b.Downvotes }
+	default: // top
+		less = func(a, b *comment) bool { return a.Upvotes-a.Downvotes > b.Upvotes-b.Downvotes }
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return less(ordered[i], ordered[j]) })
+
+	var roots []*comment
+	for _, c := range ordered {
+		if parent := nodes[c.ParentID]; c.ParentID != "" && parent != nil {
+			parent.Replies = append(parent.Replies, c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+	return roots, nil
+}
+
+func (s *Store) Vote(voter *agent, postID string, up bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.posts[postID]
+	if p == nil { return errorf(404, "게시글을 찾을 수 없습니다") }
+	author := s.agents[p.AuthorID]
+	if up {
+		p.Upvotes++
+		if author != nil { author.Karma++ }
+		if p.AuthorID != voter.ID { s.notify(p.AuthorID, "upvote_on_post", voter, p, "", "") }
+	} else {
+		p.Downvotes++
+		if author != nil { author.Karma-- }
+	}
+	return nil
+}
+
+func (s *Store) Submadangs() []submadang {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []submadang
+	for _, m := range s.submadangs { res = append(res, *m) }
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func (s *Store) CreateSubmadang(name, displayName, description string) (*submadang, error) {
+	if name == "" || displayName == "" || description == "" { return nil, errorf(400, "name, display_name, description은 필수입니다") }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.submadangs[name] != nil { return nil, errorf(409, "이미 존재하는 마당 이름입니다") }
+	m := &submadang{Name: name, DisplayName: displayName, Description: description}
+	s.submadangs[name] = m
+	return m, nil
+}
+
+// notify는 s.mu를 잡은 상태에서 호출해야 합니다.
+func (s *Store) notify(recipientID, typ string, actor *agent, p *post, commentID, content string) {
+	preview := content
+	if r := []rune(preview); len(r) > 100 { preview = string(r[:100]) }
+	s.notifications = append(s.notifications, &notification{
+		ID: s.nextID("notif"), Type: typ, ActorID: actor.ID, ActorName: actor.Name, PostID: p.ID, PostTitle: p.Title,
+		CommentID: commentID, ContentPreview: preview, CreatedAt: s.now(), recipientID: recipientID,
+	})
+}
+
+// Notifications는 최신순 알림과 전체 미읽음 수를 돌려줍니다. cursor는 이전 페이지 마지막 알림의 ID입니다.
+func (s *Store) Notifications(recipient *agent, limit int, unreadOnly bool, since time.Time, cursor string) (list []notification, unread int, next string, hasMore bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var mine []*notification
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		n := s.notifications[i]
+		if n.recipientID != recipient.ID { continue }
+		if !n.IsRead { unread++ }
+		if unreadOnly && n.IsRead { continue }
+		if !since.IsZero() && !n.CreatedAt.After(since) { continue }
+		mine = append(mine, n)
+	}
+	start := 0
+	if cursor != "" {
+		for i, n := range mine {
+			if n.ID == cursor { start = i + 1; break }
+		}
+	}
+	for i := start; i < len(mine) && len(list) < limit; i++ { list = append(list, *mine[i]) }
+	hasMore = start+len(list) < len(mine)
+	if hasMore && len(list) > 0 { next = list[len(list)-1].ID }
+	return list, unread, next, hasMore
+}
+
+// MarkRead는 ids(또는 all=true면 전부)를 읽음 처리하고 처리한 개수를 돌려줍니다.
+func (s *Store) MarkRead(recipient *agent, ids []string, all bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids { want[id] = true }
+	marked := 0
+	for _, n := range s.notifications {
+		if n.recipientID != recipient.ID || n.IsRead { continue }
+		if all || want[n.ID] { n.IsRead = true; marked++ }
+	}
+	return marked
+}
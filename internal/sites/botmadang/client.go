@@ -1,15 +1,16 @@
 package botmadang
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"d3k-agent/internal/cassette"
 	"d3k-agent/internal/core/domain"
 	"d3k-agent/internal/core/ports"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -24,6 +25,7 @@ type Client struct {
 	APIKey     string
 	HTTPClient *http.Client
 	Storage    ports.Storage
+	UI         ports.Interaction // 신규 등록 시 운영자 입력용 (nil 허용)
 
 	// 속도 제한 관리를 위한 필드
 	mu             sync.Mutex
@@ -31,17 +33,31 @@ type Client struct {
 	lastPostTime   time.Time
 }
 
-func NewClient(storage ports.Storage) *Client {
+// NewClient는 HTTP_CASSETTE_MODE가 설정됐는데 카세트를 열 수 없으면 에러를 돌려줍니다.
+func NewClient(storage ports.Storage, ui ports.Interaction) (*Client, error) {
+	baseURL := DefaultBaseURL
+	if v := os.Getenv("BOTMADANG_BASE_URL"); v != "" { baseURL = strings.TrimRight(v, "/") } // 로컬 샌드박스(cmd/sandbox) 등
+	transport, err := cassette.FromEnv("botmadang") // HTTP_CASSETTE_MODE 설정 시 기록/재생
+	if err != nil { return nil, err }
 	return &Client{
-		BaseURL: DefaultBaseURL,
+		BaseURL: baseURL,
 		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: transport,
 		},
 		Storage: storage,
-	}
+		UI:      ui,
+	}, nil
 }
 
-var _ ports.Site = (*Client)(nil)
+var (
+	_ ports.Site            = (*Client)(nil)
+	_ ports.Notifier        = (*Client)(nil)
+	_ ports.Threader        = (*Client)(nil)
+	_ ports.Voter           = (*Client)(nil)
+	_ ports.BoardLister     = (*Client)(nil)
+	_ ports.ProfileProvider = (*Client)(nil)
+)
 
 func (c *Client) Name() string {
 	return "botmadang"
@@ -89,96 +105,165 @@ func (c *Client) Initialize(ctx context.Context) error {
 		fmt.Printf("⚠️  [%s] API Key in .env is invalid.\n", c.Name())
 	}
 
+	if c.Storage != nil {
+		stored, err := c.Storage.LoadCredential(ctx, c.Name())
+		// 저장소를 읽지 못했을 때 새로 등록하면 기존 계정을 잃으므로 다음 재시도까지 기다림
+		if err != nil { return fmt.Errorf("stored credential unreadable: %v", err) }
+		if stored != "" {
+			c.APIKey = stored
+			if err := c.checkToken(ctx); err == nil {
+				fmt.Printf("✅ [%s] 저장된 키로 인증되었습니다.\n", c.Name())
+				return nil
+			}
+			fmt.Printf("⚠️  [%s] Stored API Key is invalid.\n", c.Name())
+		}
+	}
+	c.APIKey = ""
+
+	if c.UI == nil { return fmt.Errorf("no valid API key and no interaction available for registration") }
+	// 발급된 키는 다시 받을 수 없고 봇 이름도 선점되므로, 저장할 수 없으면 등록을 시작하지 않음
+	if c.Storage != nil {
+		if err := c.Storage.CredentialsReady(); err != nil { return fmt.Errorf("cannot register without credential storage: %v", err) }
+	}
+
 	fmt.Printf("\n🚀 [%s] Starting New Registration...\n", c.Name())
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("봇 이름을 입력하세요: ")
-	botName, _ := reader.ReadString('\n')
-	botName = strings.TrimSpace(botName)
+	botName, err := c.UI.Prompt(ctx, fmt.Sprintf("🆕 [%s] 신규 등록", c.Name()), "봇 이름을 답장으로 보내주세요. (3-30자, 영문/한글/숫자/언더스코어)")
+	if err != nil { return err }
 	if botName == "" { botName = "D3K_Bot" }
 
-	regResp, err := c.Register(botName, "기술/금융/일상에 관한 이야기를 해보고 싶어요. 우리의 대화가 생각의 확장, 영감을 얻는데 도움이 되면 좋겠습니다.")
+	regResp, err := c.Register(ctx, botName, "기술/금융/일상에 관한 이야기를 해보고 싶어요. 우리의 대화가 생각의 확장, 영감을 얻는데 도움이 되면 좋겠습니다.")
 	if err != nil { return err }
 
-	fmt.Printf("\n=== 🛡️  인증 필요 ===\n1. URL: %s\n2. 코드: %s\n=================================\n", regResp.Agent.ClaimURL, regResp.Agent.VerificationCode)
-	fmt.Print("\n🔗 트윗 URL 입력: ")
-	tweetURL, _ := reader.ReadString('\n')
-	tweetURL = strings.TrimSpace(tweetURL)
+	tweetBody := fmt.Sprintf("1. URL: %s\n2. 코드: %s\n\n인증 트윗을 올린 뒤 트윗 URL을 답장으로 보내주세요.", regResp.Agent.ClaimURL, regResp.Agent.VerificationCode)
+	tweetURL, err := c.UI.Prompt(ctx, fmt.Sprintf("🛡️ [%s] 인증 필요", c.Name()), tweetBody)
+	if err != nil { return err }
 
-	apiKey, err := c.Verify(regResp.Agent.VerificationCode, tweetURL)
+	apiKey, err := c.Verify(ctx, regResp.Agent.VerificationCode, tweetURL)
 	if err != nil { return err }
 
-	fmt.Printf("\n✨ 인증 성공! 발급된 API 키:\n\n%s\n\n", apiKey)
-	fmt.Println("=========================================================\n⚠️  중요: 위 키를 '.env' 파일의 BOTMADANG_API_KEY 항목에 입력하세요.\n=========================================================")
-	os.Exit(0)
+	c.APIKey = apiKey
+	if c.Storage != nil {
+		// 저장에 실패해도 키는 메모리에 두고 계속 동작함 (에러를 돌려주면 재시도에서 키를 버리고 다시 등록함)
+		if err := c.Storage.SaveCredential(ctx, c.Name(), apiKey); err != nil {
+			fmt.Printf("⚠️  [%s] Verified but failed to store API key: %v\n", c.Name(), err)
+			body := fmt.Sprintf("발급된 API 키를 저장하지 못했습니다 (%v).\n이 키는 다시 발급되지 않으니 .env의 BOTMADANG_API_KEY에 직접 저장해주세요:\n%s", err, apiKey)
+			if err := c.UI.Notify(ctx, fmt.Sprintf("⚠️ [%s] API 키 저장 실패", c.Name()), body); err != nil { fmt.Printf("⚠️  [%s] Notify failed: %v\n", c.Name(), err) }
+			return nil
+		}
+	}
+	fmt.Printf("✨ [%s] 인증 성공! 발급된 키를 암호화해 저장했습니다.\n", c.Name())
 	return nil
 }
 
 func (c *Client) checkToken(ctx context.Context) error {
-	req, _ := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/agents/me", nil)
-	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	_, err := c.Me(ctx)
+	return err
+}
+
+// APIError는 2xx가 아닌 응답입니다. Message는 응답의 error/message 필드입니다.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("botmadang api status %d: %s", e.StatusCode, e.Message)
+}
+
+// do는 JSON 요청을 보내고 2xx 응답을 out에 디코딩합니다.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil { return err }
+		reader = bytes.NewBuffer(b)
+	}
+	target := c.BaseURL + path
+	if len(query) > 0 { target += "?" + query.Encode() }
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil { return err }
+	if body != nil { req.Header.Set("Content-Type", "application/json") }
+	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
+
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil { return err }
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK { return fmt.Errorf("invalid token") }
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var e ErrorResponse
+		msg := strings.TrimSpace(string(raw))
+		if json.Unmarshal(raw, &e) == nil && (e.Error != "" || e.Message != "") {
+			msg = e.Error
+			if msg == "" { msg = e.Message }
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if out == nil { return nil }
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil { return fmt.Errorf("decode %s %s: %v", method, path, err) }
 	return nil
 }
 
-func (c *Client) Register(name, description string) (*RegisterResponse, error) {
-	reqBody, _ := json.Marshal(RegisterRequest{Name: name, Description: description})
-	resp, err := c.HTTPClient.Post(c.BaseURL+"/agents/register", "application/json", bytes.NewBuffer(reqBody))
-	if err != nil { return nil, err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
-	}
+func (c *Client) Register(ctx context.Context, name, description string) (*RegisterResponse, error) {
 	var res RegisterResponse
-	json.NewDecoder(resp.Body).Decode(&res)
+	if err := c.do(ctx, "POST", "/agents/register", nil, RegisterRequest{Name: name, Description: description}, &res); err != nil { return nil, err }
 	return &res, nil
 }
 
-func (c *Client) Verify(code, tweetURL string) (string, error) {
-	reqBody, _ := json.Marshal(VerifyRequest{TweetURL: tweetURL})
-	resp, err := c.HTTPClient.Post(fmt.Sprintf("%s/claim/%s/verify", c.BaseURL, code), "application/json", bytes.NewBuffer(reqBody))
-	if err != nil { return "", err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK { return "", fmt.Errorf("verify failed: %d", resp.StatusCode) }
+func (c *Client) ClaimInfo(ctx context.Context, code string) (*ClaimInfoResponse, error) {
+	var res ClaimInfoResponse
+	if err := c.do(ctx, "GET", "/claim/"+url.PathEscape(code), nil, nil, &res); err != nil { return nil, err }
+	return &res, nil
+}
+
+func (c *Client) Verify(ctx context.Context, code, tweetURL string) (string, error) {
 	var res VerifyResponse
-	json.NewDecoder(resp.Body).Decode(&res)
-	if !res.Success { return "", fmt.Errorf(res.Message) }
+	if err := c.do(ctx, "POST", "/claim/"+url.PathEscape(code)+"/verify", nil, VerifyRequest{TweetURL: tweetURL}, &res); err != nil { return "", err }
+	if !res.Success { return "", fmt.Errorf("verify failed: %s", res.Message) }
 	return res.APIKey, nil
 }
 
+func (c *Client) Me(ctx context.Context) (*Agent, error) {
+	var res MeResponse
+	if err := c.do(ctx, "GET", "/agents/me", nil, nil, &res); err != nil { return nil, err }
+	return &res.Agent, nil
+}
+
+// ListPosts는 게시글 한 페이지를 가져옵니다. 다음 페이지는 NextCursor를 params.Cursor로 넘겨 요청합니다.
+func (c *Client) ListPosts(ctx context.Context, params ListPostsParams) (*PostsResponse, error) {
+	var res PostsResponse
+	if err := c.do(ctx, "GET", "/posts", encodeQuery(params), nil, &res); err != nil { return nil, err }
+	return &res, nil
+}
+
 func (c *Client) GetRecentPosts(ctx context.Context, limit int) ([]domain.Post, error) {
-	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/posts?limit=%d", c.BaseURL, limit), nil)
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
+	res, err := c.ListPosts(ctx, ListPostsParams{Limit: limit})
 	if err != nil { return nil, err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK { return nil, fmt.Errorf("fetch posts failed: %d", resp.StatusCode) }
-	var data struct { Success bool `json:"success"`; Posts []ApiPost `json:"posts"` }
-	json.NewDecoder(resp.Body).Decode(&data)
 	var corePosts []domain.Post
-	for _, p := range data.Posts {
-		corePosts = append(corePosts, domain.Post{ID: p.ID, Title: p.Title, Content: p.Content, Author: p.AuthorName, URL: "https://botmadang.org/post/" + p.ID, Source: "botmadang", CreatedAt: p.CreatedAt})
+	for _, p := range res.Posts {
+		corePosts = append(corePosts, domain.Post{ID: p.ID, Title: p.Title, Content: p.Content, Author: p.AuthorName, URL: c.postURL(p.ID), Source: "botmadang", Community: p.Submadang, CommentCount: p.CommentCount, CreatedAt: p.CreatedAt})
 	}
 	return corePosts, nil
 }
 
-func (c *Client) GetNotifications(ctx context.Context, unreadOnly bool) ([]domain.Notification, error) {
-	url := fmt.Sprintf("%s/notifications?limit=20", c.BaseURL)
-	if unreadOnly { url += "&unread_only=true" }
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
+func (c *Client) ListComments(ctx context.Context, postID string, params ListCommentsParams) (*CommentsResponse, error) {
+	var res CommentsResponse
+	if err := c.do(ctx, "GET", "/posts/"+url.PathEscape(postID)+"/comments", encodeQuery(params), nil, &res); err != nil { return nil, err }
+	return &res, nil
+}
+
+func (c *Client) ListNotifications(ctx context.Context, params ListNotificationsParams) (*NotificationsResponse, error) {
+	var res NotificationsResponse
+	if err := c.do(ctx, "GET", "/notifications", encodeQuery(params), nil, &res); err != nil { return nil, err }
+	return &res, nil
+}
+
+func (c *Client) GetNotifications(ctx context.Context, unreadOnly bool, limit int) ([]domain.Notification, error) {
+	res, err := c.ListNotifications(ctx, ListNotificationsParams{Limit: limit, UnreadOnly: unreadOnly})
 	if err != nil { return nil, err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK { return nil, fmt.Errorf("fetch notifs failed: %d", resp.StatusCode) }
-	var data struct { Success bool `json:"success"`; Notifications []struct { ID, Type, ActorName, PostID, PostTitle, CommentID, ContentPreview string; IsRead bool } `json:"notifications"` }
-	json.NewDecoder(resp.Body).Decode(&data)
 	var notifs []domain.Notification
-	for _, n := range data.Notifications {
-		notifs = append(notifs, domain.Notification{ID: n.ID, Type: n.Type, Source: "botmadang", ActorName: n.ActorName, PostID: n.PostID, PostTitle: n.PostTitle, CommentID: n.CommentID, Content: n.ContentPreview, IsRead: n.IsRead})
+	for _, n := range res.Notifications {
+		notifs = append(notifs, domain.Notification{ID: n.ID, Type: n.Type, Source: "botmadang", ActorName: n.ActorName, PostID: n.PostID, PostTitle: n.PostTitle, CommentID: n.CommentID, Content: n.ContentPreview, IsRead: n.IsRead, CreatedAt: n.CreatedAt})
 	}
 	return notifs, nil
 }
@@ -186,60 +271,70 @@ func (c *Client) GetNotifications(ctx context.Context, unreadOnly bool) ([]domai
 func (c *Client) CreatePost(ctx context.Context, post domain.Post) error {
 	c.enforceRateLimit(true) // 3분 대기 강제
 
-	var payload struct { Title, Content, Submadang string }
-	if err := json.Unmarshal([]byte(post.Content), &payload); err != nil { payload.Title = post.Title; payload.Content = post.Content }
-	if payload.Submadang == "" { payload.Submadang = "general" }
-	reqBody, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/posts", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated { return fmt.Errorf("post failed: %d", resp.StatusCode) }
-	return nil
+	req := CreatePostRequest{Title: post.Title, Content: post.Content, Submadang: post.Community}
+	if req.Submadang == "" { req.Submadang = "general" }
+	return c.do(ctx, "POST", "/posts", nil, req, &PostResponse{})
 }
 
 func (c *Client) CreateComment(ctx context.Context, postID string, content string) error {
 	c.enforceRateLimit(false) // 10초 대기 강제
-
-	reqBody, _ := json.Marshal(map[string]string{"content": content})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/posts/"+postID+"/comments", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated { return fmt.Errorf("comment failed: %d", resp.StatusCode) }
-	return nil
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/comments", nil, CreateCommentRequest{Content: content}, &CommentResponse{})
 }
 
 func (c *Client) ReplyToComment(ctx context.Context, postID, parentCommentID, content string) error {
 	c.enforceRateLimit(false) // 10초 대기 강제
-
-	reqBody, _ := json.Marshal(map[string]string{"content": content, "parent_id": parentCommentID})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/posts/"+postID+"/comments", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated { return fmt.Errorf("reply failed: %d", resp.StatusCode) }
-	return nil
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/comments", nil, CreateCommentRequest{Content: content, ParentID: parentCommentID}, &CommentResponse{})
 }
 
 func (c *Client) MarkNotificationRead(ctx context.Context, id string) error {
-	reqBody, _ := json.Marshal(map[string]interface{}{"notification_ids": []string{id}})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/notifications/read", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK { return fmt.Errorf("mark read failed: %d", resp.StatusCode) }
-	return nil
+	_, err := c.MarkNotificationsRead(ctx, []string{id})
+	return err
+}
+
+// MarkNotificationsRead는 ids를 읽음 처리하고 처리된 개수를 돌려줍니다. ids가 nil이면 모든 알림을 읽음 처리합니다.
+func (c *Client) MarkNotificationsRead(ctx context.Context, ids []string) (int, error) {
+	req := MarkReadRequest{NotificationIDs: ids}
+	if ids == nil { req.NotificationIDs = "all" }
+	var res MarkReadResponse
+	if err := c.do(ctx, "POST", "/notifications/read", nil, req, &res); err != nil { return 0, err }
+	return res.MarkedCount, nil
+}
+
+func (c *Client) Upvote(ctx context.Context, postID string) error {
+	return c.vote(ctx, postID, "upvote")
+}
+
+func (c *Client) Downvote(ctx context.Context, postID string) error {
+	return c.vote(ctx, postID, "downvote")
 }
 
-func fmtURL(id string) string {
-	return "https://botmadang.org/post/" + id
-}
\ No newline at end of file
+func (c *Client) vote(ctx context.Context, postID, direction string) error {
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/"+direction, nil, nil, &VoteResponse{})
+}
+
+func (c *Client) ListBoards(ctx context.Context) ([]domain.Board, error) {
+	var data SubmadangsResponse
+	if err := c.do(ctx, "GET", "/submadangs", nil, nil, &data); err != nil { return nil, err }
+	var boards []domain.Board
+	for _, m := range data.Submadangs {
+		boards = append(boards, domain.Board{Name: m.Name, DisplayName: m.DisplayName, Description: m.Description})
+	}
+	return boards, nil
+}
+
+func (c *Client) CreateSubmadang(ctx context.Context, req CreateSubmadangRequest) (*Submadang, error) {
+	var res SubmadangResponse
+	if err := c.do(ctx, "POST", "/submadangs", nil, req, &res); err != nil { return nil, err }
+	return &res.Submadang, nil
+}
+
+func (c *Client) GetProfile(ctx context.Context) (*domain.Profile, error) {
+	me, err := c.Me(ctx)
+	if err != nil { return nil, err }
+	return &domain.Profile{ID: me.ID, Name: me.Name, Description: me.Description, Karma: me.Karma}, nil
+}
+
+// postURL은 BaseURL과 같은 호스트의 웹 주소를 돌려줍니다 (https://botmadang.org/api/v1 → https://botmadang.org/post/{id}).
+func (c *Client) postURL(id string) string {
+	return strings.TrimSuffix(c.BaseURL, "/api/v1") + "/post/" + id
+}
@@ -0,0 +1,93 @@
+package botmadang
+
+import (
+	"context"
+	"d3k-agent/internal/core/ports"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type credentialStore struct {
+	ports.Storage
+	readyErr, saveErr error
+	saved             string
+}
+
+func (s *credentialStore) CredentialsReady() error { return s.readyErr }
+
+func (s *credentialStore) LoadCredential(ctx context.Context, source string) (string, error) { return "", nil }
+
+func (s *credentialStore) SaveCredential(ctx context.Context, source, secret string) error {
+	if s.saveErr != nil { return s.saveErr }
+	s.saved = secret
+	return nil
+}
+
+// registrationUI는 봇 이름과 트윗 URL 요청에 차례로 답하고 알림을 모읍니다.
+type registrationUI struct {
+	ports.Interaction
+	notified []string
+}
+
+func (u *registrationUI) Prompt(ctx context.Context, title, body string) (string, error) {
+	if strings.Contains(title, "신규 등록") { return "d3k_test", nil }
+	return "https://x.com/d3k/status/1", nil
+}
+
+func (u *registrationUI) Notify(ctx context.Context, title, body string) error {
+	u.notified = append(u.notified, body)
+	return nil
+}
+
+// registrationServer는 등록 → 인증 → 키 발급을 흉내 내고 등록 횟수를 셉니다.
+func registrationServer(t *testing.T, registered *int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /agents/register", func(w http.ResponseWriter, r *http.Request) {
+		*registered++
+		json.NewEncoder(w).Encode(RegisterResponse{Success: true, Agent: RegisteredAgent{Name: "d3k_test", ClaimURL: "https://botmadang.org/claim/abc", VerificationCode: "abc"}})
+	})
+	mux.HandleFunc("POST /claim/abc/verify", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(VerifyResponse{Success: true, APIKey: "issued-key"})
+	})
+	mux.HandleFunc("GET /agents/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer issued-key" { w.WriteHeader(http.StatusUnauthorized); return }
+		json.NewEncoder(w).Encode(MeResponse{Success: true, Agent: Agent{ID: "1", Name: "d3k_test"}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInitializeRegistration(t *testing.T) {
+	tests := []struct {
+		name           string
+		store          *credentialStore
+		wantErr        bool
+		wantRegistered int
+		wantKey        string
+		wantNotified   bool
+	}{
+		{"stored", &credentialStore{}, false, 1, "issued-key", false},
+		{"no credential secret", &credentialStore{readyErr: errors.New("CREDENTIAL_SECRET is required")}, true, 0, "", false},
+		{"save fails after verify", &credentialStore{saveErr: errors.New("disk full")}, false, 1, "issued-key", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BOTMADANG_API_KEY", "")
+			registered := 0
+			srv := registrationServer(t, &registered)
+			ui := &registrationUI{}
+			c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Storage: tt.store, UI: ui}
+			err := c.Initialize(context.Background())
+			if (err != nil) != tt.wantErr { t.Fatalf("Initialize err = %v, wantErr %v", err, tt.wantErr) }
+			if registered != tt.wantRegistered { t.Errorf("registered %d times, want %d", registered, tt.wantRegistered) }
+			if c.APIKey != tt.wantKey { t.Errorf("APIKey = %q, want %q", c.APIKey, tt.wantKey) }
+			if tt.wantNotified != (len(ui.notified) == 1 && strings.Contains(ui.notified[0], "issued-key")) { t.Errorf("notified = %q, want notified %v with the key", ui.notified, tt.wantNotified) }
+			if tt.store.saveErr == nil && !tt.wantErr && tt.store.saved != "issued-key" { t.Errorf("saved %q, want the issued key", tt.store.saved) }
+		})
+	}
+}
@@ -2,23 +2,47 @@ package botmadang
 
 import "time"
 
+// 이 파일의 타입은 OPENAPI_SPEC.md의 요청/응답 스키마를 그대로 옮긴 것입니다.
+// 스펙과 어긋나면 go test 가 실패합니다 (spec.go의 Operations, spec_test.go 참고).
+// 스펙에 스키마가 없는 응답은 OPENAPI_LOCAL.md의 로컬 기록(업스트림 아님)을 따릅니다.
+
+// ErrorResponse 실패 응답 ({"success": false, "error": "..."})
+type ErrorResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// --- 에이전트 / 인증 ---
+
 // RegisterRequest 에이전트 등록 요청
 type RegisterRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
 
+// RegisteredAgent 등록 직후의 에이전트 정보
+type RegisteredAgent struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	ClaimURL         string `json:"claim_url"`
+	VerificationCode string `json:"verification_code"`
+}
+
 // RegisterResponse 에이전트 등록 응답
 type RegisterResponse struct {
-	Success bool `json:"success"`
-	Agent   struct {
-		ID              string `json:"id"`
-		Name            string `json:"name"`
-		ClaimURL        string `json:"claim_url"`
-		VerificationCode string `json:"verification_code"`
-	} `json:"agent"`
-	Message   string   `json:"message"`
-	NextSteps []string `json:"next_steps"`
+	Success   bool            `json:"success"`
+	Agent     RegisteredAgent `json:"agent"`
+	Message   string          `json:"message"`
+	NextSteps []string        `json:"next_steps"`
+}
+
+// ClaimInfoResponse 인증 코드 정보 응답
+type ClaimInfoResponse struct {
+	Success     bool      `json:"success"`
+	BotName     string    `json:"bot_name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // VerifyRequest 인증 요청
@@ -28,36 +52,191 @@ type VerifyRequest struct {
 
 // VerifyResponse 인증 응답
 type VerifyResponse struct {
-	Success bool   `json:"success"`
-	APIKey  string `json:"api_key"`
-	BotName string `json:"bot_name"`
-	Message string `json:"message"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	APIKey    string `json:"api_key"`
+	BotName   string `json:"bot_name"`
+	Important string `json:"important"`
+}
+
+// Agent 에이전트 정보
+type Agent struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Karma       int       `json:"karma"`
+	IsClaimed   bool      `json:"is_claimed"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // MeResponse 내 정보 응답
 type MeResponse struct {
+	Success bool  `json:"success"`
+	Agent   Agent `json:"agent"`
+}
+
+// --- 게시글 ---
+
+// ListPostsParams GET /posts 쿼리
+type ListPostsParams struct {
+	Submadang string `query:"submadang"`
+	Limit     int    `query:"limit"` // 기본 25, 최대 50
+	Cursor    string `query:"cursor"`
+}
+
+// Post 게시글 (스펙에 항목 스키마가 없어 로컬 기록 기준)
+type Post struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	Submadang    string    `json:"submadang"`
+	AuthorID     string    `json:"author_id"`
+	AuthorName   string    `json:"author_name"`
+	Upvotes      int       `json:"upvotes"`
+	Downvotes    int       `json:"downvotes"`
+	CommentCount int       `json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PostsResponse 게시글 목록 응답. NextCursor가 nil이면 마지막 페이지입니다.
+type PostsResponse struct {
+	Success    bool    `json:"success"`
+	Posts      []Post  `json:"posts"`
+	Count      int     `json:"count"`
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// CreatePostRequest 게시글 작성 요청
+type CreatePostRequest struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Submadang string `json:"submadang"` // general, tech, daily, questions, showcase
+}
+
+// PostResponse 게시글 작성 응답
+type PostResponse struct {
 	Success bool `json:"success"`
-	Agent   struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	} `json:"agent"`
+	Post    Post `json:"post"`
 }
 
-// ApiPost represents the structure of a post returned by the Botmadang API.
-type ApiPost struct {
-	ID         string    `json:"id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	AuthorName string    `json:"author_name"`
-	Submadang  string    `json:"submadang"`
-	CreatedAt  time.Time `json:"created_at"`
+// VoteResponse 추천/비추천 응답
+type VoteResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// --- 댓글 ---
+
+// ListCommentsParams GET /posts/{id}/comments 쿼리
+type ListCommentsParams struct {
+	Sort string `query:"sort"` // top(기본), new, controversial
 }
 
-// Comment represents the structure of a comment returned by the Botmadang API.
+// Comment 댓글. Replies에 대댓글이 스레드 구조로 들어 있습니다.
 type Comment struct {
 	ID         string    `json:"id"`
 	PostID     string    `json:"post_id"`
+	ParentID   *string   `json:"parent_id"`
 	Content    string    `json:"content"`
+	AuthorID   string    `json:"author_id"`
 	AuthorName string    `json:"author_name"`
+	Upvotes    int       `json:"upvotes"`
+	Downvotes  int       `json:"downvotes"`
 	CreatedAt  time.Time `json:"created_at"`
-}
\ No newline at end of file
+	Replies    []Comment `json:"replies"`
+}
+
+// CommentsResponse 댓글 목록 응답
+type CommentsResponse struct {
+	Success  bool      `json:"success"`
+	Comments []Comment `json:"comments"`
+	Count    int       `json:"count"`
+}
+
+// CreateCommentRequest 댓글/대댓글 작성 요청
+type CreateCommentRequest struct {
+	Content  string `json:"content"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// CommentResponse 댓글 작성 응답
+type CommentResponse struct {
+	Success bool    `json:"success"`
+	Comment Comment `json:"comment"`
+}
+
+// --- 마당 ---
+
+// Submadang 마당 정보
+type Submadang struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+}
+
+// SubmadangsResponse 마당 목록 응답
+type SubmadangsResponse struct {
+	Success    bool        `json:"success"`
+	Submadangs []Submadang `json:"submadangs"`
+	Count      int         `json:"count"`
+}
+
+// CreateSubmadangRequest 마당 생성 요청
+type CreateSubmadangRequest struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+}
+
+// SubmadangResponse 마당 생성 응답
+type SubmadangResponse struct {
+	Success   bool      `json:"success"`
+	Submadang Submadang `json:"submadang"`
+}
+
+// --- 알림 ---
+
+// ListNotificationsParams GET /notifications 쿼리
+type ListNotificationsParams struct {
+	Limit      int       `query:"limit"` // 기본 25, 최대 50
+	UnreadOnly bool      `query:"unread_only"`
+	Since      time.Time `query:"since"`
+	Cursor     string    `query:"cursor"`
+}
+
+// Notification 알림. Type은 comment_on_post, reply_to_comment, upvote_on_post 중 하나입니다.
+type Notification struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	ActorID        string    `json:"actor_id"`
+	ActorName      string    `json:"actor_name"`
+	PostID         string    `json:"post_id"`
+	PostTitle      string    `json:"post_title"`
+	CommentID      string    `json:"comment_id"`
+	ContentPreview string    `json:"content_preview"`
+	IsRead         bool      `json:"is_read"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NotificationsResponse 알림 목록 응답
+type NotificationsResponse struct {
+	Success       bool           `json:"success"`
+	Notifications []Notification `json:"notifications"`
+	Count         int            `json:"count"`
+	UnreadCount   int            `json:"unread_count"`
+	NextCursor    *string        `json:"next_cursor"`
+	HasMore       bool           `json:"has_more"`
+}
+
+// MarkReadRequest 알림 읽음 처리 요청. NotificationIDs는 []string 또는 "all"입니다.
+type MarkReadRequest struct {
+	NotificationIDs interface{} `json:"notification_ids"`
+}
+
+// MarkReadResponse 알림 읽음 처리 응답
+type MarkReadResponse struct {
+	Success     bool   `json:"success"`
+	Message     string `json:"message"`
+	MarkedCount int    `json:"marked_count"`
+}
@@ -0,0 +1,116 @@
+package botmadang
+
+import (
+	"context"
+	"d3k-agent/internal/cassette"
+	"errors"
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+)
+
+// 카세트는 두 종류입니다.
+//   - testdata/botmadang.json: 실제 botmadang.org에서 기록한 카세트. 없으면 재생 테스트를 건너뜁니다.
+//     BOTMADANG_RECORD_CASSETTE=1 BOTMADANG_API_KEY=... go test -run TestRecordProductionCassette ./internal/sites/botmadang
+//     으로 읽기 전용 호출만 기록합니다 (비밀값은 cassette 패키지가 가림).
+//   - testdata/botmadang_sandbox.json: 로컬 샌드박스(cmd/sandbox)를 상대로 기록한 카세트. 실서비스 응답이 아니므로
+//     스펙 적합성 근거가 아니라, 등록/인증 경로와 strict 재생 동작을 확인하는 용도입니다.
+const (
+	productionCassette = "testdata/botmadang.json"
+	sandboxCassette    = "testdata/botmadang_sandbox.json"
+)
+
+func replayClient(t *testing.T, path string) (*Client, *cassette.Transport) {
+	t.Helper()
+	tr, err := cassette.New(cassette.ModeStrict, path, nil)
+	if err != nil { t.Fatal(err) }
+	return &Client{BaseURL: DefaultBaseURL, APIKey: "replay", HTTPClient: &http.Client{Transport: tr}}, tr
+}
+
+// checkReadCalls는 에이전트가 쓰는 읽기 호출을 재생하고 디코딩 결과를 확인합니다.
+func checkReadCalls(t *testing.T, c *Client) {
+	t.Helper()
+	ctx := context.Background()
+	me, err := c.Me(ctx)
+	if err != nil { t.Fatalf("Me: %v", err) }
+	if me.ID == "" || me.Name == "" { t.Errorf("Me decoded with empty fields: %+v", me) }
+
+	for _, limit := range []int{3, 5} {
+		posts, err := c.GetRecentPosts(ctx, limit)
+		if err != nil { t.Fatalf("GetRecentPosts(%d): %v", limit, err) }
+		if len(posts) == 0 || len(posts) > limit { t.Errorf("GetRecentPosts(%d) returned %d posts", limit, len(posts)) }
+		for _, p := range posts {
+			if p.ID == "" || p.Title == "" || p.Content == "" || p.Author == "" || p.CreatedAt.IsZero() { t.Errorf("post decoded with empty fields: %+v", p) }
+			if p.URL != "https://botmadang.org/post/"+p.ID { t.Errorf("post URL = %q", p.URL) }
+		}
+	}
+
+	notifs, err := c.GetNotifications(ctx, true, 20)
+	if err != nil { t.Fatalf("GetNotifications: %v", err) }
+	for _, n := range notifs {
+		if n.ID == "" || n.Type == "" || n.PostID == "" || n.ActorName == "" { t.Errorf("notification decoded with empty fields: %+v", n) }
+	}
+
+	boards, err := c.ListBoards(ctx)
+	if err != nil { t.Fatalf("ListBoards: %v", err) }
+	if len(boards) == 0 { t.Error("ListBoards returned no boards") }
+}
+
+func TestReplayProductionCassette(t *testing.T) {
+	if _, err := os.Stat(productionCassette); err != nil { t.Skipf("%s not recorded yet (see TestRecordProductionCassette)", productionCassette) }
+	c, tr := replayClient(t, productionCassette)
+	checkReadCalls(t, c)
+	if n := tr.Unused(); n != 0 { t.Errorf("%d recorded interaction(s) were not replayed", n) }
+}
+
+// TestRecordProductionCassette는 실제 botmadang.org에서 읽기 전용 호출을 기록합니다. 글/댓글 작성이나 등록은 하지 않습니다.
+func TestRecordProductionCassette(t *testing.T) {
+	if os.Getenv("BOTMADANG_RECORD_CASSETTE") != "1" { t.Skip("set BOTMADANG_RECORD_CASSETTE=1 and BOTMADANG_API_KEY to record from botmadang.org") }
+	key := os.Getenv("BOTMADANG_API_KEY")
+	if key == "" { t.Fatal("BOTMADANG_API_KEY is required for recording") }
+	if err := os.Remove(productionCassette); err != nil && !errors.Is(err, os.ErrNotExist) { t.Fatal(err) } // 이어 쓰지 않고 새로 기록
+	tr, err := cassette.New(cassette.ModeRecord, productionCassette, nil)
+	if err != nil { t.Fatal(err) }
+	checkReadCalls(t, &Client{BaseURL: DefaultBaseURL, APIKey: key, HTTPClient: &http.Client{Transport: tr}})
+}
+
+func TestReplaySandboxCassette(t *testing.T) {
+	c, tr := replayClient(t, sandboxCassette)
+	checkReadCalls(t, c)
+
+	// 등록 응답의 인증 코드는 가려져 있으므로 가려진 값 그대로 claim 경로가 재생되어야 함
+	ctx := context.Background()
+	c.APIKey = ""
+	reg, err := c.Register(ctx, "replay_bot", "카세트 기록용 봇")
+	if err != nil { t.Fatalf("Register: %v", err) }
+	if _, err := c.ClaimInfo(ctx, reg.Agent.VerificationCode); err != nil { t.Fatalf("ClaimInfo: %v", err) }
+	if _, err := c.Verify(ctx, reg.Agent.VerificationCode, "https://x.com/d3k/status/1"); err != nil { t.Fatalf("Verify: %v", err) }
+
+	if n := tr.Unused(); n != 0 { t.Errorf("%d recorded interaction(s) were not replayed", n) }
+}
+
+func TestReplayUnrecordedRequest(t *testing.T) {
+	c, _ := replayClient(t, sandboxCassette)
+	if _, err := c.GetRecentPosts(context.Background(), 7); !errors.Is(err, cassette.ErrNoMatch) { t.Errorf("unrecorded request error = %v, want ErrNoMatch", err) }
+}
+
+func TestCassettesHaveNoSecrets(t *testing.T) {
+	for _, path := range []string{productionCassette, sandboxCassette} {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) { continue }
+		if err != nil { t.Fatal(err) }
+		for _, pattern := range []string{`madang-[A-Z0-9]{4}`, `Bearer `, `botmadang_[0-9a-f]{8,}`, `botmadang_sandbox`} {
+			if loc := regexp.MustCompile(pattern).FindIndex(data); loc != nil { t.Errorf("%s contains a secret matching %s: %q", path, pattern, data[loc[0]:loc[1]]) }
+		}
+	}
+}
+
+// TestNewClientRefusesMissingCassette는 재생 모드에서 카세트를 열 수 없으면 실서비스로 넘어가지 않고 생성이 실패하는지 확인합니다.
+func TestNewClientRefusesMissingCassette(t *testing.T) {
+	t.Setenv("HTTP_CASSETTE_DIR", t.TempDir())
+	for _, mode := range []string{"strict", "replay"} {
+		t.Setenv("HTTP_CASSETTE_MODE", mode)
+		if c, err := NewClient(nil, nil); err == nil || c != nil { t.Errorf("%s: NewClient = %v, %v; want an error", mode, c, err) }
+	}
+}
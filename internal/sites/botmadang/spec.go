@@ -0,0 +1,277 @@
+package botmadang
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation은 스펙의 작업 하나와 이를 표현하는 Go 타입의 대응입니다.
+type Operation struct {
+	Method    string
+	Path      string
+	Query     interface{}         // query 태그를 가진 쿼리 파라미터 타입
+	Request   interface{}         // 요청 본문 타입
+	Responses map[int]interface{} // 상태 코드별 응답 본문 타입
+}
+
+// Operations는 OPENAPI_SPEC.md에 문서화된 모든 작업입니다. CheckSpec이 스펙과 양방향으로 대조합니다 (spec_test.go).
+var Operations = []Operation{
+	{Method: "POST", Path: "/agents/register", Request: RegisterRequest{}, Responses: map[int]interface{}{201: RegisterResponse{}}},
+	{Method: "GET", Path: "/agents/me", Responses: map[int]interface{}{200: MeResponse{}}},
+	{Method: "GET", Path: "/claim/{code}", Responses: map[int]interface{}{200: ClaimInfoResponse{}, 400: ErrorResponse{}, 404: ErrorResponse{}}},
+	{Method: "POST", Path: "/claim/{code}/verify", Request: VerifyRequest{}, Responses: map[int]interface{}{200: VerifyResponse{}, 400: ErrorResponse{}, 404: ErrorResponse{}}},
+	{Method: "GET", Path: "/posts", Query: ListPostsParams{}, Responses: map[int]interface{}{200: PostsResponse{}}},
+	{Method: "POST", Path: "/posts", Request: CreatePostRequest{}, Responses: map[int]interface{}{201: PostResponse{}, 429: ErrorResponse{}}},
+	{Method: "GET", Path: "/posts/{id}/comments", Query: ListCommentsParams{}, Responses: map[int]interface{}{200: CommentsResponse{}, 404: ErrorResponse{}}},
+	{Method: "POST", Path: "/posts/{id}/comments", Request: CreateCommentRequest{}, Responses: map[int]interface{}{201: CommentResponse{}, 409: ErrorResponse{}, 429: ErrorResponse{}}},
+	{Method: "POST", Path: "/posts/{id}/upvote", Responses: map[int]interface{}{200: VoteResponse{}}},
+	{Method: "POST", Path: "/posts/{id}/downvote", Responses: map[int]interface{}{200: VoteResponse{}}},
+	{Method: "GET", Path: "/submadangs", Responses: map[int]interface{}{200: SubmadangsResponse{}}},
+	{Method: "POST", Path: "/submadangs", Request: CreateSubmadangRequest{}, Responses: map[int]interface{}{201: SubmadangResponse{}, 409: ErrorResponse{}}},
+	{Method: "GET", Path: "/notifications", Query: ListNotificationsParams{}, Responses: map[int]interface{}{200: NotificationsResponse{}}},
+	{Method: "POST", Path: "/notifications/read", Request: MarkReadRequest{}, Responses: map[int]interface{}{200: MarkReadResponse{}}},
+}
+
+type specDoc struct {
+	Paths      map[string]map[string]specOp `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type specOp struct {
+	Parameters []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Nullable   bool               `json:"nullable"`
+	Required   []string           `json:"required"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	OneOf      []*schema          `json:"oneOf"`
+}
+
+// parseSpec은 OPENAPI_SPEC.md(마크다운 안의 ```json 블록) 또는 순수 JSON 스펙을 읽습니다.
+func parseSpec(doc []byte) (*specDoc, error) {
+	if i := bytes.Index(doc, []byte("```json")); i >= 0 {
+		doc = doc[i+len("```json"):]
+		if j := bytes.Index(doc, []byte("```")); j >= 0 { doc = doc[:j] }
+	}
+	var spec specDoc
+	if err := json.Unmarshal(doc, &spec); err != nil { return nil, fmt.Errorf("parse spec: %v", err) }
+	return &spec, nil
+}
+
+// CheckSpec은 스펙 문서와 Operations/모델 타입을 대조해 어긋난 점을 돌려줍니다. 빈 결과면 일치합니다.
+// 작업 목록, 응답 상태 코드, 쿼리 파라미터, 요청/응답 필드 이름과 타입, 필수 필드를 확인합니다.
+// 스펙이 실제로 문서화한 스키마만 대조합니다. 스키마가 없는 응답과 항목 스키마가 없는 배열은 SpecGaps로 따로 돌려줍니다.
+func CheckSpec(doc []byte) ([]string, error) {
+	c, err := checkSpec(doc)
+	if err != nil { return nil, err }
+	return c.problems, nil
+}
+
+// SpecGaps는 Go 타입은 있지만 스펙이 스키마를 문서화하지 않은 곳입니다 (예: "POST /posts 201", "GET /posts 200.posts[]").
+// 이 타입들은 스펙이 아니라 실제 응답 관찰 기록(OPENAPI_LOCAL.md)을 근거로 합니다.
+func SpecGaps(doc []byte) ([]string, error) {
+	c, err := checkSpec(doc)
+	if err != nil { return nil, err }
+	return c.gaps, nil
+}
+
+func checkSpec(doc []byte) (*specChecker, error) {
+	spec, err := parseSpec(doc)
+	if err != nil { return nil, err }
+	c := &specChecker{spec: spec}
+
+	known := make(map[string]bool)
+	for _, op := range Operations {
+		key := op.Method + " " + op.Path
+		known[key] = true
+		sop, ok := spec.Paths[op.Path][strings.ToLower(op.Method)]
+		if !ok { c.addf("%s: not in spec", key); continue }
+		c.checkOperation(key, op, sop)
+	}
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			if key := strings.ToUpper(method) + " " + path; !known[key] { c.addf("%s: documented in spec but missing from Operations", key) }
+		}
+	}
+	sort.Strings(c.problems)
+	sort.Strings(c.gaps)
+	return c, nil
+}
+
+type specChecker struct {
+	spec     *specDoc
+	problems []string
+	gaps     []string
+}
+
+func (c *specChecker) addf(format string, args ...interface{}) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+func (c *specChecker) checkOperation(key string, op Operation, sop specOp) {
+	// 쿼리 파라미터
+	want := make(map[string]bool)
+	for _, p := range sop.Parameters {
+		if p.In == "query" { want[p.Name] = true }
+	}
+	have := make(map[string]bool)
+	if op.Query != nil {
+		t := reflect.TypeOf(op.Query)
+		for i := 0; i < t.NumField(); i++ {
+			if name := t.Field(i).Tag.Get("query"); name != "" { have[name] = true }
+		}
+	}
+	for name := range want {
+		if !have[name] { c.addf("%s: query parameter %q missing from Go type", key, name) }
+	}
+	for name := range have {
+		if !want[name] { c.addf("%s: query parameter %q not in spec", key, name) }
+	}
+
+	// 요청 본문
+	var reqSchema *schema
+	if sop.RequestBody != nil { reqSchema = sop.RequestBody.Content["application/json"].Schema }
+	switch {
+	case reqSchema != nil && op.Request == nil:
+		c.addf("%s: request body documented but Operation.Request is nil", key)
+	case reqSchema == nil && op.Request != nil:
+		c.addf("%s: Operation.Request set but spec has no request body", key)
+	case reqSchema != nil:
+		c.checkType(key+" request", reqSchema, reflect.TypeOf(op.Request), true, map[string]bool{})
+	}
+
+	// 응답
+	for code, resp := range sop.Responses {
+		status, _ := strconv.Atoi(code)
+		goType, ok := op.Responses[status]
+		if !ok { c.addf("%s: response %s missing from Operation.Responses", key, code); continue }
+		s := resp.Content["application/json"].Schema
+		if s == nil { c.gaps = append(c.gaps, fmt.Sprintf("%s %d", key, status)); continue }
+		c.checkType(fmt.Sprintf("%s %d", key, status), s, reflect.TypeOf(goType), false, map[string]bool{})
+	}
+	for status := range op.Responses {
+		if _, ok := sop.Responses[strconv.Itoa(status)]; !ok { c.addf("%s: response %d not in spec", key, status) }
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// checkType은 스키마와 Go 타입을 재귀적으로 비교합니다. seen은 $ref 순환(Comment.replies)을 막습니다.
+func (c *specChecker) checkType(where string, s *schema, t reflect.Type, isRequest bool, seen map[string]bool) {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if seen[name] { return }
+		seen[name] = true
+		ref, ok := c.spec.Components.Schemas[name]
+		if !ok { c.addf("%s: unresolved $ref %s", where, s.Ref); return }
+		s = ref
+	}
+	if t.Kind() == reflect.Ptr {
+		if !s.Nullable { c.addf("%s: Go pointer but spec is not nullable", where) }
+		t = t.Elem()
+	}
+	if len(s.OneOf) > 0 {
+		if t.Kind() != reflect.Interface { c.addf("%s: oneOf needs interface{} (got %s)", where, t) }
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			if t != timeType { c.addf("%s: date-time needs time.Time (got %s)", where, t) }
+		} else if t.Kind() != reflect.String {
+			c.addf("%s: string needs string (got %s)", where, t)
+		}
+	case "integer":
+		if k := t.Kind(); k < reflect.Int || k > reflect.Uint64 { c.addf("%s: integer needs int (got %s)", where, t) }
+	case "number":
+		if k := t.Kind(); k != reflect.Float32 && k != reflect.Float64 { c.addf("%s: number needs float (got %s)", where, t) }
+	case "boolean":
+		if t.Kind() != reflect.Bool { c.addf("%s: boolean needs bool (got %s)", where, t) }
+	case "array":
+		if t.Kind() != reflect.Slice { c.addf("%s: array needs slice (got %s)", where, t); return }
+		if s.Items == nil { c.gaps = append(c.gaps, where+"[]"); return }
+		c.checkType(where+"[]", s.Items, t.Elem(), isRequest, seen)
+	case "object", "":
+		if len(s.Properties) == 0 { return }
+		if t.Kind() != reflect.Struct { c.addf("%s: object needs struct (got %s)", where, t); return }
+		c.checkFields(where, s, t, isRequest, seen)
+	}
+}
+
+func (c *specChecker) checkFields(where string, s *schema, t reflect.Type, isRequest bool, seen map[string]bool) {
+	fields := make(map[string]reflect.StructField)
+	omitEmpty := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "" || tag == "-" { continue }
+		parts := strings.Split(tag, ",")
+		fields[parts[0]] = f
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" { omitEmpty[parts[0]] = true }
+		}
+	}
+	for name, prop := range s.Properties {
+		f, ok := fields[name]
+		if !ok { c.addf("%s: field %q missing from %s", where, name, t.Name()); continue }
+		// 재귀 구조는 가지마다 따로 추적합니다.
+		branch := make(map[string]bool, len(seen))
+		for k := range seen { branch[k] = true }
+		c.checkType(where+"."+name, prop, f.Type, isRequest, branch)
+	}
+	for name := range fields {
+		if _, ok := s.Properties[name]; !ok { c.addf("%s: field %q of %s not in spec", where, name, t.Name()) }
+	}
+	if isRequest {
+		for _, name := range s.Required {
+			if omitEmpty[name] { c.addf("%s: required field %q must not be omitempty", where, name) }
+		}
+	}
+}
+
+// encodeQuery는 query 태그를 가진 구조체를 쿼리 문자열로 바꿉니다. 0 값은 생략합니다.
+func encodeQuery(params interface{}) url.Values {
+	q := url.Values{}
+	if params == nil { return q }
+	v := reflect.ValueOf(params)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, f := t.Field(i).Tag.Get("query"), v.Field(i)
+		if name == "" || f.IsZero() { continue }
+		switch x := f.Interface().(type) {
+		case time.Time:
+			q.Set(name, x.UTC().Format(time.RFC3339))
+		default:
+			q.Set(name, fmt.Sprint(x))
+		}
+	}
+	return q
+}
@@ -0,0 +1,125 @@
+package botmadang
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const (
+	specPath        = "../../../OPENAPI_SPEC.md"
+	localShapesPath = "../../../OPENAPI_LOCAL.md"
+)
+
+// TestSpecConformance는 Operations와 모델 타입이 OPENAPI_SPEC.md와 일치하는지 확인합니다.
+func TestSpecConformance(t *testing.T) {
+	doc, err := os.ReadFile(specPath)
+	if err != nil { t.Fatal(err) }
+	problems, err := CheckSpec(doc)
+	if err != nil { t.Fatal(err) }
+	for _, p := range problems { t.Error(p) }
+}
+
+// TestOperationsCoverResponseModels는 models.go의 모든 *Response 타입이 어떤 작업의 응답으로 대조되는지 확인합니다.
+func TestOperationsCoverResponseModels(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "models.go", nil, 0)
+	if err != nil { t.Fatal(err) }
+	covered := make(map[string]bool)
+	for _, op := range Operations {
+		for _, r := range op.Responses { covered[reflect.TypeOf(r).Name()] = true }
+	}
+	for _, decl := range f.Decls {
+		g, ok := decl.(*ast.GenDecl)
+		if !ok || g.Tok != token.TYPE { continue }
+		for _, spec := range g.Specs {
+			name := spec.(*ast.TypeSpec).Name.Name
+			if strings.HasSuffix(name, "Response") && !covered[name] { t.Errorf("%s is not the response of any Operation", name) }
+		}
+	}
+}
+
+// TestLocalShapesCoverSpecGaps는 업스트림 스펙에 스키마가 없는 응답이 모두 OPENAPI_LOCAL.md에 로컬 기록으로 올라 있는지 확인합니다.
+// 로컬 기록과 Go 타입은 대조하지 않습니다 (같은 출처에서 나온 문서라 적합성 근거가 되지 않음).
+func TestLocalShapesCoverSpecGaps(t *testing.T) {
+	doc, err := os.ReadFile(specPath)
+	if err != nil { t.Fatal(err) }
+	gaps, err := SpecGaps(doc)
+	if err != nil { t.Fatal(err) }
+	local, err := os.ReadFile(localShapesPath)
+	if err != nil { t.Fatal(err) }
+	listed := make(map[string]bool)
+	for _, m := range regexp.MustCompile("(?m)^- `([^`]+)`$").FindAllStringSubmatch(string(local), -1) { listed[m[1]] = true }
+
+	for _, gap := range gaps {
+		if !listed[gap] { t.Errorf("%s has no upstream schema and is not listed in OPENAPI_LOCAL.md", gap) }
+		delete(listed, gap)
+	}
+	for key := range listed { t.Errorf("OPENAPI_LOCAL.md lists %s, but the upstream spec documents it (or no Operation uses it); remove the entry", key) }
+}
+
+// TestCheckSpecDetectsDrift는 스펙과 어긋난 경우를 CheckSpec이 실제로 잡아내는지 확인합니다.
+func TestCheckSpecDetectsDrift(t *testing.T) {
+	const okResp = `{"type": "object", "properties": {"success": {"type": "boolean"}, "message": {"type": "string"}}}`
+	spec := func(resp string) []byte {
+		return []byte(fmt.Sprintf(`{"paths": {"/posts/{id}/upvote": {"post": {"responses": {"200": {"content": {"application/json": {"schema": %s}}}}}}},
+			"components": {"schemas": {"Post": {"type": "object", "properties": {"id": {"type": "string"}}}}}}`, resp))
+	}
+	saved := Operations
+	defer func() { Operations = saved }()
+
+	tests := []struct {
+		name string
+		resp string
+		typ  interface{}
+		want string // 빈 문자열이면 문제가 없어야 함
+	}{
+		{"match", okResp, VoteResponse{}, ""},
+		{"missing field", `{"type": "object", "properties": {"success": {"type": "boolean"}, "message": {"type": "string"}, "extra": {"type": "string"}}}`, VoteResponse{}, `field "extra" missing`},
+		{"extra field", `{"type": "object", "properties": {"success": {"type": "boolean"}}}`, VoteResponse{}, `field "message" of VoteResponse not in spec`},
+		{"wrong type", `{"type": "object", "properties": {"success": {"type": "string"}, "message": {"type": "string"}}}`, VoteResponse{}, "string needs string (got bool)"},
+		{"item drift", `{"type": "object", "properties": {"success": {"type": "boolean"}, "posts": {"type": "array", "items": {"$ref": "#/components/schemas/Post"}}}}`, struct {
+			Success bool   `json:"success"`
+			Posts   []Post `json:"posts"`
+		}{}, `field "title" of Post not in spec`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Operations = []Operation{{Method: "POST", Path: "/posts/{id}/upvote", Responses: map[int]interface{}{200: tt.typ}}}
+			problems, err := CheckSpec(spec(tt.resp))
+			if err != nil { t.Fatal(err) }
+			joined := strings.Join(problems, "\n")
+			if tt.want == "" {
+				if len(problems) > 0 { t.Errorf("unexpected problems:\n%s", joined) }
+				return
+			}
+			if !strings.Contains(joined, tt.want) { t.Errorf("want problem containing %q, got:\n%s", tt.want, joined) }
+		})
+	}
+}
+
+// TestSpecGapsAreNotProblems는 스펙에 스키마가 없는 응답을 어긋남이 아니라 빈틈으로 분류하는지 확인합니다.
+func TestSpecGapsAreNotProblems(t *testing.T) {
+	saved := Operations
+	defer func() { Operations = saved }()
+	Operations = []Operation{
+		{Method: "POST", Path: "/posts/{id}/upvote", Responses: map[int]interface{}{200: VoteResponse{}}},
+		{Method: "GET", Path: "/posts", Responses: map[int]interface{}{200: PostsResponse{}}},
+	}
+	doc := []byte(`{"paths": {
+		"/posts/{id}/upvote": {"post": {"responses": {"200": {"description": "추천 성공"}}}},
+		"/posts": {"get": {"responses": {"200": {"content": {"application/json": {"schema": {"type": "object", "properties": {
+			"success": {"type": "boolean"}, "posts": {"type": "array"}, "count": {"type": "integer"},
+			"next_cursor": {"type": "string", "nullable": true}, "has_more": {"type": "boolean"}}}}}}}}}}}`)
+	problems, err := CheckSpec(doc)
+	if err != nil { t.Fatal(err) }
+	if len(problems) > 0 { t.Errorf("unexpected problems: %v", problems) }
+	gaps, err := SpecGaps(doc)
+	if err != nil { t.Fatal(err) }
+	if want := []string{"GET /posts 200.posts[]", "POST /posts/{id}/upvote 200"}; strings.Join(gaps, "|") != strings.Join(want, "|") { t.Errorf("gaps = %q, want %q", gaps, want) }
+}
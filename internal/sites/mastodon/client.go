@@ -0,0 +1,389 @@
+package mastodon
+
+import (
+	"bytes"
+	"context"
+	"d3k-agent/internal/cassette"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client는 Mastodon(ActivityPub 호환) REST API를 위한 어댑터입니다.
+// BaseURL을 로컬 대역 서버로 바꿔 오프라인으로 테스트할 수 있습니다.
+type Client struct {
+	BaseURL     string // 인스턴스 주소 (예: https://mastodon.social)
+	AccessToken string
+	HTTPClient  *http.Client
+	Storage     ports.Storage
+	UI          ports.Interaction // 토큰 입력용 (nil 허용)
+
+	// Timeline은 GetRecentPosts가 읽을 타임라인입니다: home, local, public
+	Timeline string
+
+	mu              sync.Mutex
+	policy          Policy
+	me              *Account
+	lastPostTime    time.Time
+	lastCommentTime time.Time
+	unread          []string        // 마지막으로 가져온 안 읽은 멘션 ID (오름차순)
+	handled         map[string]bool // 처리했지만 앞선 멘션이 남아 마커를 넘기지 못한 ID
+}
+
+// NewClient는 HTTP_CASSETTE_MODE가 설정됐는데 카세트를 열 수 없으면 에러를 돌려줍니다.
+func NewClient(storage ports.Storage, ui ports.Interaction) (*Client, error) {
+	timeline := os.Getenv("MASTODON_TIMELINE")
+	if timeline == "" { timeline = "home" }
+	policy := DefaultPolicy()
+	if v := os.Getenv("MASTODON_VISIBILITY"); v != "" { policy.Visibility = v }
+	transport, err := cassette.FromEnv("mastodon") // HTTP_CASSETTE_MODE 설정 시 기록/재생
+	if err != nil { return nil, err }
+	return &Client{
+		BaseURL: strings.TrimRight(os.Getenv("MASTODON_BASE_URL"), "/"),
+		HTTPClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+		Storage:  storage,
+		UI:       ui,
+		Timeline: timeline,
+		policy:   policy,
+		handled:  make(map[string]bool),
+	}, nil
+}
+
+// handledCursor는 마커 앞에 묶여 있는 처리된 멘션 ID를 재시작 후에도 기억하기 위한 커서 키입니다.
+const handledCursor = "mastodon:handled"
+
+var (
+	_ ports.Site            = (*Client)(nil)
+	_ ports.Notifier        = (*Client)(nil)
+	_ ports.Threader        = (*Client)(nil)
+	_ ports.Voter           = (*Client)(nil)
+	_ ports.ProfileProvider = (*Client)(nil)
+)
+
+func (c *Client) Name() string {
+	return "mastodon"
+}
+
+// APIError는 2xx가 아닌 응답을 나타냅니다.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mastodon api status %d: %s", e.StatusCode, e.Body)
+}
+
+func (c *Client) Initialize(ctx context.Context) error {
+	if c.BaseURL == "" { return fmt.Errorf("MASTODON_BASE_URL is required") }
+	c.loadHandled()
+
+	candidates := []string{os.Getenv("MASTODON_ACCESS_TOKEN")}
+	if c.Storage != nil {
+		stored, err := c.Storage.LoadCredential(ctx, c.Name())
+		// 저장소를 읽지 못했을 때 새로 등록하면 기존 계정을 잃으므로 다음 재시도까지 기다림
+		if err != nil { return fmt.Errorf("stored credential unreadable: %v", err) }
+		candidates = append(candidates, stored)
+	}
+	for _, token := range candidates {
+		if token == "" { continue }
+		c.AccessToken = token
+		if err := c.verify(ctx); err == nil { return nil }
+	}
+	c.AccessToken = ""
+
+	// Mastodon은 봇 자가 등록이 없으므로 운영자가 발급한 액세스 토큰을 받음
+	if c.UI == nil { return fmt.Errorf("no valid access token and no interaction available") }
+	body := fmt.Sprintf("%s/settings/applications 에서 read/write 권한의 액세스 토큰을 발급한 뒤 답장으로 보내주세요.", c.BaseURL)
+	token, err := c.UI.Prompt(ctx, fmt.Sprintf("🔑 [%s] 액세스 토큰 필요", c.Name()), body)
+	if err != nil { return err }
+	c.AccessToken = token
+	if err := c.verify(ctx); err != nil { c.AccessToken = ""; return err }
+	if c.Storage != nil {
+		if err := c.Storage.SaveCredential(ctx, c.Name(), token); err != nil {
+			return fmt.Errorf("verified but failed to store access token: %v", err)
+		}
+	}
+	return nil
+}
+
+// verify는 토큰을 확인하고 계정 정보와 인스턴스 글자 수 제한을 불러옵니다.
+func (c *Client) verify(ctx context.Context) error {
+	var me Account
+	if err := c.do(ctx, "GET", "/api/v1/accounts/verify_credentials", nil, &me); err != nil { return err }
+	c.mu.Lock()
+	c.me = &me
+	c.mu.Unlock()
+
+	var inst Instance
+	if err := c.do(ctx, "GET", "/api/v2/instance", nil, &inst); err == nil && inst.Configuration.Statuses.MaxCharacters > 0 {
+		p := c.Policy()
+		p.MaxChars = inst.Configuration.Statuses.MaxCharacters
+		c.SetPolicy(p)
+	}
+	fmt.Printf("✅ [%s] Authenticated as @%s (%s)\n", c.Name(), me.Acct, c.Policy())
+	return nil
+}
+
+func (c *Client) GetRecentPosts(ctx context.Context, limit int) ([]domain.Post, error) {
+	path := fmt.Sprintf("/api/v1/timelines/home?limit=%d", limit)
+	switch c.Timeline {
+	case "local":
+		path = fmt.Sprintf("/api/v1/timelines/public?local=true&limit=%d", limit)
+	case "public":
+		path = fmt.Sprintf("/api/v1/timelines/public?limit=%d", limit)
+	}
+	var statuses []Status
+	if err := c.do(ctx, "GET", path, nil, &statuses); err != nil { return nil, err }
+
+	myID := c.myID()
+	var posts []domain.Post
+	for _, s := range statuses {
+		if s.Reblog != nil { s = *s.Reblog } // 부스트는 원문으로
+		if s.Account.ID == myID { continue }
+		posts = append(posts, c.toPost(s))
+	}
+	return posts, nil
+}
+
+// GetNotifications는 멘션만 돌려줍니다. 읽음 상태는 notifications 마커(last_read_id)로 관리합니다.
+// 안 읽은 멘션은 마커 바로 다음(min_id)부터 가져오고(마커가 없으면 최근 것부터), 이미 처리한 멘션은 빼고 돌려줍니다.
+func (c *Client) GetNotifications(ctx context.Context, unreadOnly bool, limit int) ([]domain.Notification, error) {
+	query := url.Values{"types[]": {"mention"}, "limit": {strconv.Itoa(limit)}}
+	lastRead := ""
+	if unreadOnly {
+		// 마커를 모르면 min_id 없이 최근 멘션을 가져와 이미 답한 멘션을 다시 돌려주게 되므로 중단함
+		var err error
+		if lastRead, err = c.lastReadID(ctx); err != nil { return nil, fmt.Errorf("read marker: %w", err) }
+		if lastRead != "" { query.Set("min_id", lastRead) }
+	}
+	var items []Notification
+	if err := c.do(ctx, "GET", "/api/v1/notifications?"+query.Encode(), nil, &items); err != nil { return nil, err }
+
+	var unread []string
+	var notifs []domain.Notification
+	for _, n := range items {
+		if n.Status == nil { continue }
+		isRead := lastRead != "" && !idAfter(n.ID, lastRead)
+		if !isRead { unread = append(unread, n.ID) }
+		if unreadOnly && c.isHandled(n.ID) { continue }
+		typ, postID := "comment_on_post", n.Status.ID
+		if n.Status.InReplyToID != "" { typ, postID = "reply_to_comment", n.Status.InReplyToID }
+		notifs = append(notifs, domain.Notification{
+			ID: n.ID, Type: typ, Source: c.Name(), ActorName: n.Account.Acct,
+			PostID: postID, PostTitle: excerpt(stripHTML(n.Status.Content), 40), CommentID: n.Status.ID,
+			Content: stripHTML(n.Status.Content), IsRead: isRead, CreatedAt: n.CreatedAt,
+		})
+	}
+	if unreadOnly {
+		sort.Slice(unread, func(i, j int) bool { return idAfter(unread[j], unread[i]) })
+		c.mu.Lock()
+		c.unread = unread
+		c.mu.Unlock()
+	}
+	return notifs, nil
+}
+
+// MarkNotificationRead는 id를 처리한 것으로 기록하고, 마커는 그보다 앞선 안 읽은 멘션이 모두 처리된 지점까지만 전진시킵니다.
+// 마커(last_read_id)는 그 이하를 모두 읽음으로 만들므로, 건너뛴 멘션을 넘어가면 다시 볼 수 없게 됩니다.
+func (c *Client) MarkNotificationRead(ctx context.Context, id string) error {
+	c.mu.Lock()
+	if c.handled == nil { c.handled = make(map[string]bool) }
+	c.handled[id] = true
+	target := ""
+	for _, uid := range c.unread {
+		if !c.handled[uid] { break }
+		target = uid
+	}
+	c.mu.Unlock()
+
+	if target != "" {
+		last, err := c.lastReadID(ctx)
+		if err != nil { c.saveHandled(); return fmt.Errorf("read marker: %w", err) }
+		if last == "" || idAfter(target, last) {
+			body := map[string]interface{}{"notifications": map[string]string{"last_read_id": target}}
+			if err := c.do(ctx, "POST", "/api/v1/markers", body, nil); err != nil { return err }
+		}
+		c.mu.Lock()
+		for hid := range c.handled {
+			if !idAfter(hid, target) { delete(c.handled, hid) }
+		}
+		for len(c.unread) > 0 && !idAfter(c.unread[0], target) { c.unread = c.unread[1:] }
+		c.mu.Unlock()
+	}
+	c.saveHandled()
+	return nil
+}
+
+func (c *Client) isHandled(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.handled[id]
+}
+
+func (c *Client) loadHandled() {
+	if c.Storage == nil { return }
+	raw, _ := c.Storage.LoadCursor(handledCursor)
+	var ids []string
+	if raw != "" { json.Unmarshal([]byte(raw), &ids) }
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.handled == nil { c.handled = make(map[string]bool) }
+	for _, id := range ids { c.handled[id] = true }
+}
+
+func (c *Client) saveHandled() {
+	if c.Storage == nil { return }
+	c.mu.Lock()
+	ids := make([]string, 0, len(c.handled))
+	for id := range c.handled { ids = append(ids, id) }
+	c.mu.Unlock()
+	sort.Strings(ids)
+	if encoded, err := json.Marshal(ids); err == nil { c.Storage.SaveCursor(handledCursor, string(encoded)) }
+}
+
+// lastReadID는 notifications 마커입니다. 마커가 없으면 빈 문자열입니다.
+func (c *Client) lastReadID(ctx context.Context) (string, error) {
+	var markers map[string]Marker
+	if err := c.do(ctx, "GET", "/api/v1/markers?timeline[]=notifications", nil, &markers); err != nil { return "", err }
+	return markers["notifications"].LastReadID, nil
+}
+
+// CreatePost는 제목과 본문을 한 게시물로 합칩니다. 글자 수 제한을 넘으면 스레드로 나눠 올립니다.
+func (c *Client) CreatePost(ctx context.Context, post domain.Post) error {
+	if err := c.enforceRateLimit(ctx, true); err != nil { return err }
+	text := post.Content
+	if post.Title != "" { text = post.Title + "\n\n" + post.Content }
+	_, err := c.postThread(ctx, "", "", text)
+	return err
+}
+
+func (c *Client) CreateComment(ctx context.Context, postID string, content string) error {
+	return c.ReplyToComment(ctx, postID, postID, content)
+}
+
+// ReplyToComment는 parentCommentID(없으면 postID) 게시물에 답글을 답니다.
+// Mastodon에서는 멘션이 있어야 상대가 알림을 받으므로 작성자 계정을 앞에 붙입니다.
+func (c *Client) ReplyToComment(ctx context.Context, postID, parentCommentID, content string) error {
+	if err := c.enforceRateLimit(ctx, false); err != nil { return err }
+	target := parentCommentID
+	if target == "" { target = postID }
+
+	var parent Status
+	if err := c.do(ctx, "GET", "/api/v1/statuses/"+url.PathEscape(target), nil, &parent); err != nil { return err }
+	mention := ""
+	if parent.Account.ID != c.myID() && !strings.Contains(content, "@"+parent.Account.Acct) {
+		mention = "@" + parent.Account.Acct + " "
+	}
+	_, err := c.postThread(ctx, target, mention, content)
+	return err
+}
+
+func (c *Client) Upvote(ctx context.Context, postID string) error {
+	return c.do(ctx, "POST", "/api/v1/statuses/"+url.PathEscape(postID)+"/favourite", nil, nil)
+}
+
+// Downvote는 Mastodon에 비추천이 없으므로 좋아요 취소로 대응합니다.
+func (c *Client) Downvote(ctx context.Context, postID string) error {
+	return c.do(ctx, "POST", "/api/v1/statuses/"+url.PathEscape(postID)+"/unfavourite", nil, nil)
+}
+
+func (c *Client) GetProfile(ctx context.Context) (*domain.Profile, error) {
+	var me Account
+	if err := c.do(ctx, "GET", "/api/v1/accounts/verify_credentials", nil, &me); err != nil { return nil, err }
+	return &domain.Profile{ID: me.ID, Name: me.Acct, Description: stripHTML(me.Note), Karma: me.Followers}, nil
+}
+
+// postThread는 text를 정책의 글자 수에 맞게 나눠 inReplyTo 아래에 연속 게시합니다.
+// prefix(멘션)는 모든 조각 앞에 붙습니다. 마지막으로 올린 게시물 ID를 돌려줍니다.
+func (c *Client) postThread(ctx context.Context, inReplyTo, prefix, text string) (string, error) {
+	policy := c.Policy()
+	chunks := splitStatus(text, policy.MaxChars-len([]rune(prefix)), policy.MaxThreadParts)
+	if len(chunks) == 0 { return "", fmt.Errorf("empty status") }
+	for _, chunk := range chunks {
+		var created Status
+		req := StatusRequest{Status: prefix + chunk, InReplyToID: inReplyTo, Visibility: policy.Visibility, Language: "ko"}
+		if err := c.do(ctx, "POST", "/api/v1/statuses", req, &created); err != nil { return inReplyTo, err }
+		inReplyTo = created.ID
+	}
+	return inReplyTo, nil
+}
+
+func (c *Client) myID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.me == nil { return "" }
+	return c.me.ID
+}
+
+func (c *Client) toPost(s Status) domain.Post {
+	text := stripHTML(s.Content)
+	title := s.SpoilerText
+	if title == "" { title = excerpt(text, 40) }
+	return domain.Post{ID: s.ID, Source: c.Name(), Title: title, Content: text, Author: s.Account.Acct, URL: s.URL, CommentCount: s.RepliesCount, CreatedAt: s.CreatedAt}
+}
+
+// do는 JSON 요청을 보내고 상태 코드를 검증한 뒤 응답을 out에 디코딩합니다.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil { return err }
+		reader = bytes.NewBuffer(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil { return err }
+	if body != nil { req.Header.Set("Content-Type", "application/json") }
+	if c.AccessToken != "" { req.Header.Set("Authorization", "Bearer "+c.AccessToken) }
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil { return err }
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+	if out == nil { return nil }
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil { return fmt.Errorf("decode %s %s: %v", method, path, err) }
+	return nil
+}
+
+var (
+	breakRe = regexp.MustCompile(`(?i)<br\s*/?>|</p>`)
+	tagRe   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stripHTML은 Mastodon의 HTML 본문을 줄바꿈이 살아 있는 평문으로 바꿉니다.
+func stripHTML(s string) string {
+	s = breakRe.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(tagRe.ReplaceAllString(s, ""))
+	return strings.TrimSpace(s)
+}
+
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n { return string(r[:n]) + "…" }
+	return s
+}
+
+// idAfter는 Mastodon의 숫자 문자열 ID 두 개를 비교합니다 (a > b).
+func idAfter(a, b string) bool {
+	if len(a) != len(b) { return len(a) > len(b) }
+	return a > b
+}
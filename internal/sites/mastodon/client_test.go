@@ -0,0 +1,226 @@
+package mastodon
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/sandbox"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSandboxClient는 가짜 Mastodon 서버(internal/sandbox)에 붙은 클라이언트를 만듭니다. 쓰기 간격 제한은 끕니다.
+func newSandboxClient(t *testing.T, maxChars int, visibility string) (*Client, *sandbox.Mastodon) {
+	t.Helper()
+	fake := sandbox.NewMastodon("test-token", maxChars)
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	policy := DefaultPolicy()
+	policy.PostInterval, policy.CommentInterval = 0, 0
+	if visibility != "" { policy.Visibility = visibility }
+	t.Setenv("MASTODON_ACCESS_TOKEN", "test-token")
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Timeline: "home", policy: policy}
+	if err := c.Initialize(context.Background()); err != nil { t.Fatalf("Initialize: %v", err) }
+	return c, fake
+}
+
+func TestSplitStatus(t *testing.T) {
+	long := strings.Repeat("가나다라마바사. ", 40) // 360자
+	tests := []struct {
+		name      string
+		text      string
+		max       int
+		maxParts  int
+		wantParts int
+	}{
+		{"fits", "짧은 글", 500, 4, 1},
+		{"empty", "   ", 500, 4, 0},
+		{"split", long, 100, 4, 4},
+		{"split fewer", long, 200, 4, 2},
+		{"truncated to max parts", long, 100, 2, 2},
+		{"single part", long, 100, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitStatus(tt.text, tt.max, tt.maxParts)
+			if len(chunks) != tt.wantParts { t.Fatalf("got %d parts, want %d: %q", len(chunks), tt.wantParts, chunks) }
+			for i, c := range chunks {
+				if n := len([]rune(c)); n > tt.max { t.Errorf("part %d has %d chars, max %d", i, n, tt.max) }
+			}
+			if len(chunks) > 1 && !strings.HasSuffix(chunks[0], "(1/"+string(rune('0'+len(chunks)))+")") { t.Errorf("part 1 lacks counter: %q", chunks[0]) }
+		})
+	}
+}
+
+func TestInitializeReadsInstanceLimit(t *testing.T) {
+	c, _ := newSandboxClient(t, 321, "")
+	if got := c.Policy().MaxChars; got != 321 { t.Errorf("MaxChars = %d, want 321 from /api/v2/instance", got) }
+}
+
+func TestCreatePostSplitsByCharacterLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxChars   int
+		visibility string
+		content    string
+		wantParts  int
+		wantVis    string
+	}{
+		{"short post", 500, "", "오늘의 한 줄", 1, "unlisted"},
+		{"long post threads", 120, "", strings.Repeat("긴 글을 나눠서 올립니다. ", 30), 4, "unlisted"},
+		{"visibility policy", 120, "private", strings.Repeat("비공개로 올립니다. ", 20), 3, "private"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake := newSandboxClient(t, tt.maxChars, tt.visibility)
+			if err := c.CreatePost(context.Background(), domain.Post{Title: "제목", Content: tt.content}); err != nil { t.Fatalf("CreatePost: %v", err) }
+
+			posted := fake.Statuses("d3k")
+			if len(posted) != tt.wantParts { t.Fatalf("posted %d statuses, want %d", len(posted), tt.wantParts) }
+			for i, st := range posted {
+				if n := len([]rune(st.Text)); n > tt.maxChars { t.Errorf("status %d has %d chars, limit %d", i, n, tt.maxChars) }
+				if st.Visibility != tt.wantVis { t.Errorf("status %d visibility = %q, want %q", i, st.Visibility, tt.wantVis) }
+				// 스레드: 첫 조각은 새 글, 이후 조각은 바로 앞 조각에 대한 답글
+				switch {
+				case i == 0 && st.InReplyToID != nil:
+					t.Errorf("first status replies to %s", *st.InReplyToID)
+				case i > 0 && (st.InReplyToID == nil || *st.InReplyToID != posted[i-1].ID):
+					t.Errorf("status %d is not chained to the previous part", i)
+				}
+			}
+			if !strings.HasPrefix(posted[0].Text, "제목\n\n") { t.Errorf("title not prepended: %q", posted[0].Text) }
+		})
+	}
+}
+
+func TestReplyMentionsAuthorInEveryPart(t *testing.T) {
+	c, fake := newSandboxClient(t, 100, "")
+	fake.Mention("alice", "질문이 있어요", "")
+	notifs, err := c.GetNotifications(context.Background(), true, 20)
+	if err != nil || len(notifs) != 1 { t.Fatalf("GetNotifications = %v, %v", notifs, err) }
+
+	n := notifs[0]
+	if err := c.ReplyToComment(context.Background(), n.PostID, n.CommentID, strings.Repeat("자세히 답해 드릴게요. ", 15)); err != nil { t.Fatalf("ReplyToComment: %v", err) }
+	posted := fake.Statuses("d3k")
+	if len(posted) < 2 { t.Fatalf("long reply was not split: %d statuses", len(posted)) }
+	for i, st := range posted {
+		if !strings.HasPrefix(st.Text, "@alice ") { t.Errorf("part %d lacks mention: %q", i, st.Text) }
+		if n := len([]rune(st.Text)); n > 100 { t.Errorf("part %d has %d chars including mention", i, n) }
+	}
+	if posted[0].InReplyToID == nil || *posted[0].InReplyToID != n.CommentID { t.Error("reply is not attached to the mention") }
+}
+
+// TestMarkerDoesNotSkipUnhandledMentions는 나중 멘션을 먼저 처리해도 앞선 멘션이 읽음 처리되지 않는지 확인합니다.
+func TestMarkerDoesNotSkipUnhandledMentions(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newSandboxClient(t, 500, "")
+	first := fake.Mention("alice", "첫 번째", "")
+	second := fake.Mention("bob", "두 번째", "")
+	third := fake.Mention("carol", "세 번째", "")
+
+	notifs, err := c.GetNotifications(ctx, true, 20)
+	if err != nil || len(notifs) != 3 { t.Fatalf("GetNotifications = %d, %v", len(notifs), err) }
+
+	// 최근 두 개만 처리 (첫 번째는 거절/보류)
+	for _, id := range []string{third, second} {
+		if err := c.MarkNotificationRead(ctx, id); err != nil { t.Fatal(err) }
+	}
+	if got := fake.LastReadID(); got != "" { t.Fatalf("marker advanced to %s past unhandled mention %s", got, first) }
+
+	notifs, _ = c.GetNotifications(ctx, true, 20)
+	if len(notifs) != 1 || notifs[0].ID != first { t.Fatalf("want only the unhandled mention, got %+v", notifs) }
+
+	if err := c.MarkNotificationRead(ctx, first); err != nil { t.Fatal(err) }
+	if got := fake.LastReadID(); got != third { t.Errorf("marker = %s, want %s after all mentions handled", got, third) }
+	if notifs, _ = c.GetNotifications(ctx, true, 20); len(notifs) != 0 { t.Errorf("want no unread mentions, got %d", len(notifs)) }
+}
+
+// TestNotificationsStartAfterMarker는 안 읽은 멘션이 한 페이지보다 많을 때 오래된 것부터 가져오는지 확인합니다.
+func TestNotificationsStartAfterMarker(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newSandboxClient(t, 500, "")
+	seen := fake.Mention("alice", "이미 읽은 멘션", "")
+	c.GetNotifications(ctx, true, 20)
+	if err := c.MarkNotificationRead(ctx, seen); err != nil { t.Fatal(err) }
+
+	var ids []string
+	for i := 0; i < 25; i++ { ids = append(ids, fake.Mention("alice", "멘션", "")) }
+
+	notifs, err := c.GetNotifications(ctx, true, 20)
+	if err != nil { t.Fatal(err) }
+	if len(notifs) != 20 { t.Fatalf("got %d notifications, want a page of 20", len(notifs)) }
+	got := make(map[string]bool)
+	for _, n := range notifs { got[n.ID] = true }
+	if !got[ids[0]] { t.Error("oldest unread mention missing from the first page") }
+	if got[ids[24]] { t.Error("page should start right after the marker, not at the newest mention") }
+}
+
+func TestInitializeRejectsBadToken(t *testing.T) {
+	fake := sandbox.NewMastodon("right", 500)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	t.Setenv("MASTODON_ACCESS_TOKEN", "wrong")
+	c := &Client{BaseURL: srv.URL, HTTPClient: &http.Client{}, policy: DefaultPolicy()}
+	if err := c.Initialize(context.Background()); err == nil { t.Error("Initialize succeeded with an invalid token and no UI") }
+}
+
+// TestMarkerReadFailureAborts는 마커를 읽지 못하면 이미 답한 멘션을 다시 가져오지 않고 오류를 돌려주는지 확인합니다.
+func TestMarkerReadFailureAborts(t *testing.T) {
+	ctx := context.Background()
+	fake := sandbox.NewMastodon("test-token", 500)
+	var failMarkers atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failMarkers.Load() && r.Method == "GET" && r.URL.Path == "/api/v1/markers" { http.Error(w, "unavailable", http.StatusServiceUnavailable); return }
+		fake.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	t.Setenv("MASTODON_ACCESS_TOKEN", "test-token")
+	policy := DefaultPolicy()
+	policy.PostInterval, policy.CommentInterval = 0, 0
+	c := &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Timeline: "home", policy: policy}
+	if err := c.Initialize(ctx); err != nil { t.Fatalf("Initialize: %v", err) }
+
+	id := fake.Mention("alice", "질문", "")
+	if _, err := c.GetNotifications(ctx, true, 20); err != nil { t.Fatal(err) }
+
+	failMarkers.Store(true)
+	if _, err := c.GetNotifications(ctx, true, 20); err == nil { t.Error("GetNotifications succeeded without the read marker") }
+	if err := c.MarkNotificationRead(ctx, id); err == nil { t.Error("MarkNotificationRead succeeded without the read marker") }
+	if got := fake.LastReadID(); got != "" { t.Errorf("marker moved to %s while it could not be read", got) }
+
+	// 복구되면 처리해 둔 멘션까지 마커가 전진함
+	failMarkers.Store(false)
+	if err := c.MarkNotificationRead(ctx, id); err != nil { t.Fatal(err) }
+	if got := fake.LastReadID(); got != id { t.Errorf("marker = %s, want %s", got, id) }
+}
+
+// TestRateLimitWaitsOutsideLock은 쓰기 간격을 기다리는 동안 정책 조회가 막히지 않고, 취소되면 바로 돌아오는지 확인합니다.
+func TestRateLimitWaitsOutsideLock(t *testing.T) {
+	c := &Client{policy: DefaultPolicy()}
+	c.policy.CommentInterval = time.Hour
+	if err := c.enforceRateLimit(context.Background(), false); err != nil { t.Fatal(err) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.enforceRateLimit(ctx, false) }()
+
+	policyRead := make(chan struct{})
+	go func() { c.Policy(); close(policyRead) }()
+	select {
+	case <-policyRead:
+	case <-time.After(time.Second):
+		t.Fatal("Policy blocked while another call waits for its slot")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil { t.Error("cancelled wait returned nil") }
+	case <-time.After(time.Second):
+		t.Fatal("wait did not stop on context cancellation")
+	}
+}
@@ -0,0 +1,61 @@
+package mastodon
+
+import "time"
+
+// Account는 Mastodon 계정 엔티티의 필요한 필드입니다.
+type Account struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	Acct        string `json:"acct"`
+	DisplayName string `json:"display_name"`
+	Note        string `json:"note"`
+	Followers   int    `json:"followers_count"`
+}
+
+// Status는 Mastodon 게시물(toot) 엔티티입니다.
+type Status struct {
+	ID                 string    `json:"id"`
+	URL                string    `json:"url"`
+	Content            string    `json:"content"` // HTML
+	SpoilerText        string    `json:"spoiler_text"`
+	Visibility         string    `json:"visibility"`
+	InReplyToID        string    `json:"in_reply_to_id"`
+	InReplyToAccountID string    `json:"in_reply_to_account_id"`
+	Account            Account   `json:"account"`
+	RepliesCount       int       `json:"replies_count"`
+	FavouritesCount    int       `json:"favourites_count"`
+	Reblog             *Status   `json:"reblog"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Notification은 Mastodon 알림 엔티티입니다.
+type Notification struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"` // mention, favourite, reblog, follow ...
+	Account   Account   `json:"account"`
+	Status    *Status   `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StatusRequest는 POST /api/v1/statuses 요청 본문입니다.
+type StatusRequest struct {
+	Status      string `json:"status"`
+	InReplyToID string `json:"in_reply_to_id,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+	SpoilerText string `json:"spoiler_text,omitempty"`
+	Language    string `json:"language,omitempty"`
+}
+
+// Marker는 타임라인별 마지막 읽음 위치입니다 (GET/POST /api/v1/markers).
+type Marker struct {
+	LastReadID string `json:"last_read_id"`
+}
+
+// Instance는 GET /api/v2/instance 응답 중 글자 수 제한 부분입니다.
+type Instance struct {
+	Configuration struct {
+		Statuses struct {
+			MaxCharacters int `json:"max_characters"`
+		} `json:"statuses"`
+	} `json:"configuration"`
+}
@@ -0,0 +1,113 @@
+package mastodon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Policy는 Mastodon 게시 정책입니다. MaxChars는 인스턴스 설정으로 갱신됩니다.
+type Policy struct {
+	MaxChars        int           // 게시물 하나의 최대 글자 수 (기본 500)
+	MaxThreadParts  int           // 긴 글을 나눌 때 최대 조각 수
+	Visibility      string        // public, unlisted, private, direct
+	PostInterval    time.Duration // 글 작성 최소 간격
+	CommentInterval time.Duration // 답글 작성 최소 간격
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxChars:        500,
+		MaxThreadParts:  4,
+		Visibility:      "unlisted",
+		PostInterval:    3 * time.Minute,
+		CommentInterval: 10 * time.Second,
+	}
+}
+
+func (p Policy) String() string {
+	return fmt.Sprintf("max=%d parts=%d visibility=%s", p.MaxChars, p.MaxThreadParts, p.Visibility)
+}
+
+func (c *Client) Policy() Policy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
+func (c *Client) SetPolicy(p Policy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = p
+}
+
+// enforceRateLimit은 정책에 따라 쓰기 작업 간의 최소 간격을 강제합니다.
+// 대기 시간은 잠금 안에서 계산해 자리를 예약하고, 잠금을 푼 뒤 기다립니다 (대기 중에도 정책 갱신과 다른 요청이 막히지 않음).
+func (c *Client) enforceRateLimit(ctx context.Context, isPost bool) error {
+	c.mu.Lock()
+	interval, last := c.policy.CommentInterval, &c.lastCommentTime
+	if isPost { interval, last = c.policy.PostInterval, &c.lastPostTime }
+	now := time.Now()
+	slot := now
+	if next := last.Add(interval); next.After(slot) { slot = next }
+	*last = slot
+	c.mu.Unlock()
+
+	wait := slot.Sub(now)
+	if wait <= 0 { return nil }
+	fmt.Printf("⏳ [%s] 정책 준수를 위해 %v 동안 대기합니다...\n", c.Name(), wait.Round(time.Second))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// splitStatus는 text를 max 글자 이하 조각으로 나눕니다. 여러 조각이면 " (1/3)" 꼬리표를 붙이고,
+// maxParts를 넘는 부분은 마지막 조각에서 말줄임표로 자릅니다. 가능하면 문단/문장/공백 경계에서 나눕니다.
+func splitStatus(text string, max, maxParts int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" || max <= 0 { return nil }
+	if len([]rune(text)) <= max { return []string{text} }
+	if maxParts < 1 { maxParts = 1 }
+
+	const suffixLen = 8 // " (10/10)"
+	limit := max - suffixLen
+	rest := []rune(text)
+	var chunks []string
+	for len(rest) > 0 && len(chunks) < maxParts {
+		if len(rest) <= limit {
+			chunks = append(chunks, strings.TrimSpace(string(rest)))
+			rest = nil
+			break
+		}
+		if len(chunks) == maxParts-1 {
+			chunks = append(chunks, strings.TrimSpace(string(rest[:limit-1]))+"…")
+			rest = nil
+			break
+		}
+		cut := breakPoint(rest[:limit])
+		chunks = append(chunks, strings.TrimSpace(string(rest[:cut])))
+		rest = []rune(strings.TrimSpace(string(rest[cut:])))
+	}
+	if len(chunks) > 1 {
+		for i := range chunks { chunks[i] = fmt.Sprintf("%s (%d/%d)", chunks[i], i+1, len(chunks)) }
+	}
+	return chunks
+}
+
+// breakPoint는 앞쪽 절반 이후에서 가장 늦은 문단/문장/공백 경계를 찾습니다.
+func breakPoint(r []rune) int {
+	s := string(r)
+	for _, sep := range []string{"\n\n", "\n", ". ", "다. ", "요. ", " "} {
+		if i := strings.LastIndex(s, sep); i > 0 {
+			cut := len([]rune(s[:i+len(sep)]))
+			if cut > len(r)/2 { return cut }
+		}
+	}
+	return len(r)
+}
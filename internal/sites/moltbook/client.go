@@ -1,17 +1,19 @@
 package moltbook
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"d3k-agent/internal/cassette"
 	"d3k-agent/internal/core/domain"
 	"d3k-agent/internal/core/ports"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,150 +25,308 @@ type Client struct {
 	APIKey     string
 	HTTPClient *http.Client
 	Storage    ports.Storage
+	UI         ports.Interaction // 신규 등록 시 운영자 입력용 (nil 허용)
+
+	// Submolts는 글을 올릴 수 있는 커뮤니티 목록입니다. 첫 번째 항목이 기본값입니다.
+	Submolts []string
+
+	// 속도 제한 관리를 위한 필드
+	mu              sync.Mutex
+	policy          Policy
+	lastPostTime    time.Time
+	lastCommentTime time.Time
+	requestTimes    []time.Time
 }
 
-func NewClient(storage ports.Storage) *Client {
+// NewClient는 HTTP_CASSETTE_MODE가 설정됐는데 카세트를 열 수 없으면 에러를 돌려줍니다.
+func NewClient(storage ports.Storage, ui ports.Interaction) (*Client, error) {
+	submolts := []string{"general"}
+	if env := os.Getenv("MOLTBOOK_SUBMOLTS"); env != "" {
+		submolts = nil
+		for _, s := range strings.Split(env, ",") {
+			if s = strings.TrimSpace(s); s != "" { submolts = append(submolts, s) }
+		}
+	}
+	transport, err := cassette.FromEnv("moltbook") // HTTP_CASSETTE_MODE 설정 시 기록/재생
+	if err != nil { return nil, err }
 	return &Client{
 		BaseURL: DefaultBaseURL,
 		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: transport,
 		},
-		Storage: storage,
-	}
+		Storage:  storage,
+		UI:       ui,
+		Submolts: submolts,
+		policy:   DefaultPolicy(),
+	}, nil
 }
 
-var _ ports.Site = (*Client)(nil)
+var (
+	_ ports.Site             = (*Client)(nil)
+	_ ports.Notifier         = (*Client)(nil)
+	_ ports.Threader         = (*Client)(nil)
+	_ ports.Voter            = (*Client)(nil)
+	_ ports.ProfileProvider  = (*Client)(nil)
+	_ ports.BackgroundWorker = (*Client)(nil)
+)
 
 func (c *Client) Name() string {
 	return "moltbook"
 }
 
+// APIError는 2xx가 아닌 응답을 나타냅니다.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("moltbook api status %d: %s", e.StatusCode, e.Body)
+}
+
 func (c *Client) Initialize(ctx context.Context) error {
 	// 1. .env 확인
-	token := os.Getenv("MOLTBOOK_API_KEY")
-	if token != "" {
+	if token := os.Getenv("MOLTBOOK_API_KEY"); token != "" {
 		c.APIKey = token
-		fmt.Printf("✅ [%s] Authenticated via .env\n", c.Name())
-		return nil
+		if err := c.checkToken(ctx); err == nil {
+			fmt.Printf("✅ [%s] Authenticated via .env\n", c.Name())
+			return nil
+		}
+		fmt.Printf("⚠️  [%s] API Key in .env is invalid.\n", c.Name())
+	}
+
+	// 2. 저장된 키 확인
+	if c.Storage != nil {
+		stored, err := c.Storage.LoadCredential(ctx, c.Name())
+		// 저장소를 읽지 못했을 때 새로 등록하면 기존 계정을 잃으므로 다음 재시도까지 기다림
+		if err != nil { return fmt.Errorf("stored credential unreadable: %v", err) }
+		if stored != "" {
+			c.APIKey = stored
+			if err := c.checkToken(ctx); err == nil {
+				fmt.Printf("✅ [%s] Authenticated via stored credential\n", c.Name())
+				return nil
+			}
+			fmt.Printf("⚠️  [%s] Stored API Key is invalid.\n", c.Name())
+		}
+	}
+	c.APIKey = ""
+
+	// 3. 인증을 마치지 못한 등록이 있으면 같은 키로 인증 URL을 다시 보여 줌 (새로 등록하면 다른 에이전트가 생김)
+	pending, err := c.loadPending(ctx)
+	if err != nil { return fmt.Errorf("pending registration unreadable: %v", err) }
+	if pending != nil {
+		c.APIKey = pending.APIKey
+		if err := c.checkToken(ctx); err == nil { return c.completeRegistration(ctx) }
+		if c.UI == nil { return fmt.Errorf("registration pending claim at %s", pending.ClaimURL) }
+		fmt.Printf("\n🛡️  [%s] Resuming pending registration...\n", c.Name())
+		return c.claim(ctx, pending.ClaimURL)
+	}
+	c.APIKey = ""
+
+	// 4. 등록 절차
+	if c.UI == nil { return fmt.Errorf("no valid API key and no interaction available for registration") }
+	// 발급된 키는 다시 받을 수 없으므로, 저장할 수 없으면 등록을 시작하지 않음
+	if c.Storage != nil {
+		if err := c.Storage.CredentialsReady(); err != nil { return fmt.Errorf("cannot register without credential storage: %v", err) }
 	}
 
-	// 2. 등록 절차
 	fmt.Printf("\n🚀 [%s] Starting New Registration...\n", c.Name())
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Moltbook 봇 이름을 입력하세요: ")
-	botName, _ := reader.ReadString('\n')
-	botName = strings.TrimSpace(botName)
+	botName, err := c.UI.Prompt(ctx, fmt.Sprintf("🆕 [%s] 신규 등록", c.Name()), "Moltbook 봇 이름을 답장으로 보내주세요.")
+	if err != nil { return err }
 	if botName == "" { botName = "d3k_bot" }
 
-	regResp, err := c.Register(botName, "지적인 대화와 영감을 나누는 AI 에이전트 d3k입니다.")
+	regResp, err := c.Register(ctx, botName, "지적인 대화와 영감을 나누는 AI 에이전트 d3k입니다.")
 	if err != nil { return err }
+	if regResp.Agent.APIKey == "" { return fmt.Errorf("registration succeeded but no api_key was issued") }
 
-	fmt.Printf("\n=== 🛡️  인증 필요 (Moltbook) ===\n")
-	fmt.Printf("1. URL 접속: %s\n", regResp.Agent.ClaimURL)
-	fmt.Printf("2. 발급된 API 키를 안전하게 보관하세요.\n")
-	fmt.Println("=================================")
+	c.APIKey = regResp.Agent.APIKey
+	if err := c.savePending(ctx, pendingRegistration{APIKey: c.APIKey, ClaimURL: regResp.Agent.ClaimURL}); err != nil {
+		// 키는 메모리에 두고 인증을 이어 감. 재시작 전에 운영자가 직접 저장할 수 있게 알림
+		fmt.Printf("⚠️  [%s] Registered but failed to store pending API key: %v\n", c.Name(), err)
+		body := fmt.Sprintf("발급된 API 키를 저장하지 못했습니다 (%v).\n이 키는 다시 발급되지 않으니 .env의 MOLTBOOK_API_KEY에 직접 저장해주세요:\n%s", err, c.APIKey)
+		if err := c.UI.Notify(ctx, fmt.Sprintf("⚠️ [%s] API 키 저장 실패", c.Name()), body); err != nil { fmt.Printf("⚠️  [%s] Notify failed: %v\n", c.Name(), err) }
+	}
+	return c.claim(ctx, regResp.Agent.ClaimURL)
+}
 
-	fmt.Printf("\n🔑 발급된 API 키: %s\n", regResp.Agent.APIKey)
-	fmt.Println("=========================================================")
-	fmt.Println("⚠️  중요: 위 키를 '.env' 파일의 MOLTBOOK_API_KEY 항목에 입력하세요.")
-	fmt.Println("=========================================================")
+// pendingCredential은 등록했지만 소유권 인증(claim)을 마치지 않은 키와 인증 URL을 보관하는 자격 증명 이름입니다.
+const pendingCredential = "moltbook:pending"
 
-	os.Exit(0)
-	return nil
+type pendingRegistration struct {
+	APIKey   string `json:"api_key"`
+	ClaimURL string `json:"claim_url"`
 }
 
-func (c *Client) Register(name, description string) (*RegisterResponse, error) {
-	reqBody, _ := json.Marshal(RegisterRequest{Name: name, Description: description})
-	resp, err := c.HTTPClient.Post(c.BaseURL+"/agents/register", "application/json", bytes.NewBuffer(reqBody))
-	if err != nil { return nil, err }
-	defer resp.Body.Close()
+func (c *Client) loadPending(ctx context.Context) (*pendingRegistration, error) {
+	if c.Storage == nil { return nil, nil }
+	raw, err := c.Storage.LoadCredential(ctx, pendingCredential)
+	if err != nil || raw == "" { return nil, err }
+	var p pendingRegistration
+	if err := json.Unmarshal([]byte(raw), &p); err != nil { return nil, err }
+	if p.APIKey == "" { return nil, nil }
+	return &p, nil
+}
+
+func (c *Client) savePending(ctx context.Context, p pendingRegistration) error {
+	if c.Storage == nil { return nil }
+	raw, err := json.Marshal(p)
+	if err != nil { return err }
+	return c.Storage.SaveCredential(ctx, pendingCredential, string(raw))
+}
+
+// claim은 운영자에게 인증 URL을 보여 주고, 인증이 끝나면 키를 정식 자격 증명으로 옮깁니다.
+// 시간 안에 인증하지 못하면 에러를 돌려주며, 보류 중인 등록은 남아 다음 재시도에서 같은 URL을 다시 보여 줍니다.
+func (c *Client) claim(ctx context.Context, claimURL string) error {
+	claimBody := fmt.Sprintf("URL 접속: %s\n\n소유권 인증을 마친 뒤 아무 메시지나 답장해주세요.", claimURL)
+	if _, err := c.UI.Prompt(ctx, fmt.Sprintf("🛡️ [%s] 인증 필요", c.Name()), claimBody); err != nil { return err }
+	if err := c.checkToken(ctx); err != nil { return fmt.Errorf("claim not completed: %v", err) }
+	return c.completeRegistration(ctx)
+}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("error: %s", string(body))
+// completeRegistration은 인증된 키를 저장하고 보류 중인 등록을 지웁니다.
+// 저장에 실패해도 키는 메모리에 두고(보류 기록이 남아 있으면 다음 시작 때 다시 옮김) 계속 동작합니다.
+func (c *Client) completeRegistration(ctx context.Context) error {
+	if c.Storage == nil { return nil }
+	if err := c.Storage.SaveCredential(ctx, c.Name(), c.APIKey); err != nil {
+		fmt.Printf("⚠️  [%s] Claimed but failed to store API key: %v\n", c.Name(), err)
+		return nil
 	}
+	if err := c.Storage.SaveCredential(ctx, pendingCredential, ""); err != nil { fmt.Printf("⚠️  [%s] Failed to clear pending registration: %v\n", c.Name(), err) }
+	fmt.Printf("✨ [%s] 인증 완료! 발급된 키를 암호화해 저장했습니다.\n", c.Name())
+	return nil
+}
+
+func (c *Client) checkToken(ctx context.Context) error {
+	return c.do(ctx, "GET", "/agents/me", nil, nil)
+}
+
+func (c *Client) Register(ctx context.Context, name, description string) (*RegisterResponse, error) {
 	var res RegisterResponse
-	json.NewDecoder(resp.Body).Decode(&res)
+	if err := c.do(ctx, "POST", "/agents/register", RegisterRequest{Name: name, Description: description}, &res); err != nil {
+		return nil, err
+	}
 	return &res, nil
 }
 
 func (c *Client) GetRecentPosts(ctx context.Context, limit int) ([]domain.Post, error) {
-	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/posts?limit=%d", c.BaseURL, limit), nil)
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return nil, err }
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK { return nil, fmt.Errorf("fail: %d", resp.StatusCode) }
 	var data struct { Success bool `json:"success"`; Posts []ApiPost `json:"posts"` }
-	json.NewDecoder(resp.Body).Decode(&data)
+	if err := c.do(ctx, "GET", fmt.Sprintf("/posts?sort=new&limit=%d", limit), nil, &data); err != nil { return nil, err }
 
 	var corePosts []domain.Post
 	for _, p := range data.Posts {
-		corePosts = append(corePosts, domain.Post{ID: p.ID, Title: p.Title, Content: p.Content, Author: p.AuthorName, URL: "https://www.moltbook.com/post/" + p.ID, Source: "moltbook", CreatedAt: p.CreatedAt})
+		author := p.AuthorName
+		if author == "" { author = string(p.Author) }
+		corePosts = append(corePosts, domain.Post{ID: p.ID, Title: p.Title, Content: p.Content, Author: author, URL: "https://www.moltbook.com/post/" + p.ID, Source: "moltbook", Community: string(p.Submolt), CommentCount: p.CommentCount, CreatedAt: p.CreatedAt})
 	}
 	return corePosts, nil
 }
 
-func (c *Client) GetNotifications(ctx context.Context, unreadOnly bool) ([]domain.Notification, error) {
-	// Moltbook의 알림 API 주소가 봇마당과 같다고 가정 (표준 준수)
-	req, _ := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/notifications?unread_only=true", nil)
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return nil, err }
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK { return nil, fmt.Errorf("fail: %d", resp.StatusCode) }
-	var data struct { Success bool `json:"success"`; Notifications []struct { ID, Type, ActorName, PostID, PostTitle, CommentID, ContentPreview string; IsRead bool } `json:"notifications"` }
-	json.NewDecoder(resp.Body).Decode(&data)
+func (c *Client) GetNotifications(ctx context.Context, unreadOnly bool, limit int) ([]domain.Notification, error) {
+	path := fmt.Sprintf("/notifications?limit=%d", limit)
+	if unreadOnly { path += "&unread_only=true" }
+	var data struct { Success bool `json:"success"`; Notifications []ApiNotification `json:"notifications"` }
+	if err := c.do(ctx, "GET", path, nil, &data); err != nil { return nil, err }
 
 	var notifs []domain.Notification
 	for _, n := range data.Notifications {
-		notifs = append(notifs, domain.Notification{ID: n.ID, Type: n.Type, Source: "moltbook", ActorName: n.ActorName, PostID: n.PostID, PostTitle: n.PostTitle, CommentID: n.CommentID, Content: n.ContentPreview, IsRead: n.IsRead})
+		notifs = append(notifs, domain.Notification{ID: n.ID, Type: n.Type, Source: "moltbook", ActorName: n.ActorName, PostID: n.PostID, PostTitle: n.PostTitle, CommentID: n.CommentID, Content: n.ContentPreview, IsRead: n.IsRead, CreatedAt: n.CreatedAt})
 	}
 	return notifs, nil
 }
 
 func (c *Client) CreatePost(ctx context.Context, post domain.Post) error {
-	reqBody, _ := json.Marshal(map[string]string{"title": post.Title, "content": post.Content, "submadang": "general"})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/posts", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	return nil
+	if err := c.enforceRateLimit(ctx, true); err != nil { return err }
+	return c.do(ctx, "POST", "/posts", CreatePostRequest{Submolt: c.submoltFor(post.Community), Title: post.Title, Content: post.Content}, nil)
 }
 
 func (c *Client) CreateComment(ctx context.Context, postID string, content string) error {
-	reqBody, _ := json.Marshal(map[string]string{"content": content})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/posts/"+postID+"/comments", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	return nil
+	if err := c.enforceRateLimit(ctx, false); err != nil { return err }
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/comments", CreateCommentRequest{Content: content}, nil)
 }
 
 func (c *Client) ReplyToComment(ctx context.Context, postID, parentCommentID, content string) error {
-	reqBody, _ := json.Marshal(map[string]string{"content": content, "parent_id": parentCommentID})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/posts/"+postID+"/comments", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil { return err }
-	defer resp.Body.Close()
-	return nil
+	if err := c.enforceRateLimit(ctx, false); err != nil { return err }
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/comments", CreateCommentRequest{Content: content, ParentID: parentCommentID}, nil)
 }
 
 func (c *Client) MarkNotificationRead(ctx context.Context, id string) error {
-	reqBody, _ := json.Marshal(map[string]interface{}{"notification_ids": []string{id}})
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/notifications/read", bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	if c.APIKey != "" { req.Header.Set("Authorization", "Bearer "+c.APIKey) }
+	return c.do(ctx, "POST", "/notifications/read", map[string]interface{}{"notification_ids": []string{id}}, nil)
+}
+
+func (c *Client) Upvote(ctx context.Context, postID string) error {
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/upvote", nil, nil)
+}
+
+func (c *Client) Downvote(ctx context.Context, postID string) error {
+	return c.do(ctx, "POST", "/posts/"+url.PathEscape(postID)+"/downvote", nil, nil)
+}
+
+func (c *Client) GetProfile(ctx context.Context) (*domain.Profile, error) {
+	var data struct {
+		Agent struct {
+			ID          string `json:"id"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Karma       int    `json:"karma"`
+		} `json:"agent"`
+	}
+	if err := c.do(ctx, "GET", "/agents/me", nil, &data); err != nil { return nil, err }
+	return &domain.Profile{ID: data.Agent.ID, Name: data.Agent.Name, Description: data.Agent.Description, Karma: data.Agent.Karma}, nil
+}
+
+// RunBackground는 heartbeat 루틴을 실행합니다.
+func (c *Client) RunBackground(ctx context.Context) {
+	NewHeartbeat(c, c.Storage, c.UI).Run(ctx)
+}
+
+// submoltFor는 요청된 커뮤니티가 허용 목록에 있을 때만 사용하고, 아니면 기본 submolt를 고릅니다.
+func (c *Client) submoltFor(community string) string {
+	for _, s := range c.Submolts {
+		if strings.EqualFold(s, community) { return s }
+	}
+	if len(c.Submolts) > 0 { return c.Submolts[0] }
+	return "general"
+}
+
+// authAllowed는 API 키를 moltbook.com(또는 로컬 테스트 서버)으로만 보내도록 제한합니다.
+func (c *Client) authAllowed() bool {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil { return false }
+	switch u.Hostname() {
+	case "www.moltbook.com", "localhost", "127.0.0.1":
+		return true
+	}
+	return false
+}
+
+// do는 JSON 요청을 보내고 상태 코드를 검증한 뒤 응답을 out에 디코딩합니다.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	if err := c.throttle(ctx); err != nil { return err }
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil { return err }
+		reader = bytes.NewBuffer(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil { return err }
+	if body != nil { req.Header.Set("Content-Type", "application/json") }
+	if c.APIKey != "" {
+		if !c.authAllowed() { return fmt.Errorf("refusing to send API key to %s", c.BaseURL) }
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil { return err }
 	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(msg))}
+	}
+	if out == nil { return nil }
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil { return fmt.Errorf("decode %s %s: %v", method, path, err) }
 	return nil
-}
\ No newline at end of file
+}
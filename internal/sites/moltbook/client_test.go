@@ -0,0 +1,235 @@
+package moltbook
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMoltbook은 테스트용 Moltbook API입니다. claimed에 든 키만 인증을 통과합니다.
+type fakeMoltbook struct {
+	mu         sync.Mutex
+	registered int
+	claimed    map[string]bool
+	queries    []string
+	bodies     []map[string]interface{}
+	status     int // 0이 아니면 쓰기 요청에 이 상태 코드로 응답
+}
+
+func newFakeMoltbook(t *testing.T) (*fakeMoltbook, *httptest.Server) {
+	f := &fakeMoltbook{claimed: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /agents/register", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.registered++
+		n := f.registered
+		f.mu.Unlock()
+		var res RegisterResponse
+		res.Success = true
+		res.Agent.APIKey = "key-" + string(rune('0'+n))
+		res.Agent.ClaimURL = "https://www.moltbook.com/claim/" + res.Agent.APIKey
+		json.NewEncoder(w).Encode(res)
+	})
+	mux.HandleFunc("GET /agents/me", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		ok := f.claimed[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		f.mu.Unlock()
+		if !ok { http.Error(w, `{"error": "agent not claimed"}`, http.StatusUnauthorized); return }
+		w.Write([]byte(`{"agent": {"id": "1", "name": "d3k"}}`))
+	})
+	mux.HandleFunc("GET /notifications", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.queries = append(f.queries, r.URL.RawQuery)
+		f.mu.Unlock()
+		w.Write([]byte(`{"success": true, "notifications": [{"id": "n1", "type": "comment_on_post", "actor_name": "bob", "post_id": "p1", "content_preview": "hi"}]}`))
+	})
+	write := func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		f.bodies = append(f.bodies, body)
+		status := f.status
+		f.mu.Unlock()
+		if status != 0 { http.Error(w, `{"error": "nope"}`, status); return }
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true}`))
+	}
+	mux.HandleFunc("POST /posts", write)
+	mux.HandleFunc("POST /posts/{id}/comments", write)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeMoltbook) claim(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.claimed[key] = true
+}
+
+// newTestClient는 가짜 서버에 붙은, 쓰기 간격과 분당 한도가 없는 클라이언트를 만듭니다.
+func newTestClient(srv *httptest.Server, storage ports.Storage, ui ports.Interaction) *Client {
+	return &Client{BaseURL: srv.URL, HTTPClient: srv.Client(), Storage: storage, UI: ui, Submolts: []string{"general", "tech"}, policy: Policy{}}
+}
+
+type credentialStore struct {
+	ports.Storage
+	creds    map[string]string
+	readyErr error
+}
+
+func (s *credentialStore) CredentialsReady() error { return s.readyErr }
+
+func (s *credentialStore) LoadCredential(ctx context.Context, source string) (string, error) { return s.creds[source], nil }
+
+func (s *credentialStore) SaveCredential(ctx context.Context, source, secret string) error {
+	s.creds[source] = secret
+	return nil
+}
+
+// claimUI는 봇 이름 요청에 답하고, 인증 요청을 받으면 보여 준 URL을 기록한 뒤 onClaim을 실행합니다.
+type claimUI struct {
+	ports.Interaction
+	claimURLs []string
+	onClaim   func(body string) error
+}
+
+func (u *claimUI) Prompt(ctx context.Context, title, body string) (string, error) {
+	if strings.Contains(title, "신규 등록") { return "d3k", nil }
+	u.claimURLs = append(u.claimURLs, body)
+	return "done", u.onClaim(body)
+}
+
+func TestInitializeRegistration(t *testing.T) {
+	t.Setenv("MOLTBOOK_API_KEY", "")
+	fake, srv := newFakeMoltbook(t)
+	store := &credentialStore{creds: map[string]string{}}
+	ctx := context.Background()
+
+	// 1. 운영자가 시간 안에 인증하지 못함: 키는 보류 등록으로만 남음
+	ui := &claimUI{onClaim: func(string) error { return context.DeadlineExceeded }}
+	if err := newTestClient(srv, store, ui).Initialize(ctx); !errors.Is(err, context.DeadlineExceeded) { t.Fatalf("Initialize err = %v, want the prompt timeout", err) }
+	if store.creds["moltbook"] != "" { t.Errorf("unclaimed key stored as the credential: %q", store.creds["moltbook"]) }
+	if !strings.Contains(store.creds[pendingCredential], "key-1") { t.Fatalf("pending registration = %q, want key-1", store.creds[pendingCredential]) }
+
+	// 2. 재시도: 새로 등록하지 않고 같은 인증 URL을 다시 보여 줌
+	ui = &claimUI{onClaim: func(string) error { fake.claim("key-1"); return nil }}
+	c := newTestClient(srv, store, ui)
+	if err := c.Initialize(ctx); err != nil { t.Fatalf("retry Initialize: %v", err) }
+	if fake.registered != 1 { t.Errorf("registered %d times, want the pending registration resumed", fake.registered) }
+	if len(ui.claimURLs) != 1 || !strings.Contains(ui.claimURLs[0], "/claim/key-1") { t.Errorf("claim prompts = %q, want the pending claim URL", ui.claimURLs) }
+	if c.APIKey != "key-1" || store.creds["moltbook"] != "key-1" || store.creds[pendingCredential] != "" { t.Errorf("after claim key=%q stored=%q pending=%q", c.APIKey, store.creds["moltbook"], store.creds[pendingCredential]) }
+
+	// 3. 다음 시작: 저장된 키로 바로 인증
+	ui = &claimUI{onClaim: func(string) error { t.Error("unexpected claim prompt"); return nil }}
+	if err := newTestClient(srv, store, ui).Initialize(ctx); err != nil || fake.registered != 1 { t.Errorf("Initialize with stored key = %v, registered %d", err, fake.registered) }
+}
+
+func TestInitializeResumesClaimedPending(t *testing.T) {
+	t.Setenv("MOLTBOOK_API_KEY", "")
+	fake, srv := newFakeMoltbook(t)
+	fake.claim("key-9") // 운영자가 타임아웃 뒤에 인증을 마친 경우
+	store := &credentialStore{creds: map[string]string{pendingCredential: `{"api_key": "key-9", "claim_url": "https://www.moltbook.com/claim/key-9"}`}}
+	ui := &claimUI{onClaim: func(string) error { t.Error("unexpected claim prompt"); return nil }}
+	if err := newTestClient(srv, store, ui).Initialize(context.Background()); err != nil { t.Fatal(err) }
+	if fake.registered != 0 || store.creds["moltbook"] != "key-9" || store.creds[pendingCredential] != "" { t.Errorf("registered=%d stored=%q pending=%q", fake.registered, store.creds["moltbook"], store.creds[pendingCredential]) }
+}
+
+func TestInitializeRequiresCredentialStorage(t *testing.T) {
+	t.Setenv("MOLTBOOK_API_KEY", "")
+	fake, srv := newFakeMoltbook(t)
+	store := &credentialStore{creds: map[string]string{}, readyErr: errors.New("CREDENTIAL_SECRET is required")}
+	if err := newTestClient(srv, store, &claimUI{}).Initialize(context.Background()); err == nil { t.Error("Initialize without credential storage, want error") }
+	if fake.registered != 0 { t.Errorf("registered %d times, want none", fake.registered) }
+}
+
+func TestGetNotificationsQuery(t *testing.T) {
+	fake, srv := newFakeMoltbook(t)
+	c := newTestClient(srv, nil, nil)
+	notifs, err := c.GetNotifications(context.Background(), true, 5)
+	if err != nil { t.Fatal(err) }
+	if len(notifs) != 1 || notifs[0].ActorName != "bob" || notifs[0].Source != "moltbook" { t.Errorf("notifications = %+v", notifs) }
+	c.GetNotifications(context.Background(), false, 50)
+	if want := []string{"limit=5&unread_only=true", "limit=50"}; strings.Join(fake.queries, "|") != strings.Join(want, "|") { t.Errorf("queries = %q, want %q", fake.queries, want) }
+}
+
+func TestCreatePostSubmolt(t *testing.T) {
+	tests := []struct {
+		community string
+		want      string
+	}{
+		{"tech", "tech"},
+		{"TECH", "tech"},
+		{"", "general"},
+		{"crypto", "general"}, // 허용 목록에 없으면 기본값
+	}
+	for _, tt := range tests {
+		fake, srv := newFakeMoltbook(t)
+		c := newTestClient(srv, nil, nil)
+		if err := c.CreatePost(context.Background(), domain.Post{Title: "t", Content: "c", Community: tt.community}); err != nil { t.Fatal(err) }
+		if got := fake.bodies[0]["submolt"]; got != tt.want { t.Errorf("community %q: submolt = %v, want %q", tt.community, got, tt.want) }
+		if _, ok := fake.bodies[0]["submadang"]; ok { t.Errorf("request carries a Botmadang field: %v", fake.bodies[0]) }
+	}
+}
+
+func TestWritesValidateStatus(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusTooManyRequests, http.StatusInternalServerError} {
+		fake, srv := newFakeMoltbook(t)
+		fake.status = status
+		c := newTestClient(srv, nil, nil)
+		ctx := context.Background()
+		for name, err := range map[string]error{
+			"post":    c.CreatePost(ctx, domain.Post{Title: "t", Content: "c"}),
+			"comment": c.CreateComment(ctx, "p1", "c"),
+			"reply":   c.ReplyToComment(ctx, "p1", "c1", "c"),
+		} {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != status { t.Errorf("%s with status %d: err = %v, want APIError", name, status, err) }
+		}
+	}
+}
+
+func TestRateLimitWaitsOutsideLock(t *testing.T) {
+	c := &Client{policy: Policy{PostInterval: time.Hour}}
+	if err := c.enforceRateLimit(context.Background(), true); err != nil { t.Fatal(err) } // 첫 글은 바로
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.enforceRateLimit(ctx, true) }()
+	time.Sleep(20 * time.Millisecond)
+
+	updated := make(chan struct{})
+	go func() { c.SetPolicy(DefaultPolicy()); close(updated) }()
+	select {
+	case <-updated:
+	case <-time.After(time.Second):
+		t.Fatal("SetPolicy blocked while a post was waiting for its slot")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) { t.Errorf("enforceRateLimit err = %v, want context.Canceled", err) }
+	case <-time.After(time.Second):
+		t.Fatal("enforceRateLimit ignored ctx cancellation")
+	}
+}
+
+func TestThrottleReservesSlots(t *testing.T) {
+	c := &Client{policy: Policy{RequestsPerMinute: 2}}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := c.throttle(ctx); err != nil { t.Fatal(err) }
+	}
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := c.throttle(short); !errors.Is(err, context.DeadlineExceeded) { t.Errorf("third request in a minute: err = %v, want to wait", err) }
+	if n := len(c.requestTimes); n != 3 || c.requestTimes[2].Sub(c.requestTimes[0]) < time.Minute-time.Second { t.Errorf("reserved slots = %v, want the third a minute after the first", c.requestTimes) }
+}
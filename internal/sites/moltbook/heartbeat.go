@@ -0,0 +1,214 @@
+package moltbook
+
+import (
+	"context"
+	"crypto/sha256"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultHeartbeatURL = "https://www.moltbook.com/heartbeat.md"
+
+// Heartbeat는 MOLTBOOK_GUIDE.md 권장 사항에 따라 heartbeat.md를 주기적으로 확인하고,
+// 변경 사항을 운영자에게 알리며 구조화된 규칙(속도 제한, 엔드포인트)을 런타임 정책에 반영합니다.
+type Heartbeat struct {
+	URL      string
+	Interval time.Duration
+	Client   *Client
+	Storage  ports.Storage
+	UI       ports.Interaction // nil이면 알림 생략
+}
+
+func NewHeartbeat(client *Client, storage ports.Storage, ui ports.Interaction) *Heartbeat {
+	return &Heartbeat{
+		URL:      DefaultHeartbeatURL,
+		Interval: 30 * time.Minute,
+		Client:   client,
+		Storage:  storage,
+		UI:       ui,
+	}
+}
+
+// Run은 ctx가 끝날 때까지 Interval마다 Check를 실행합니다.
+func (h *Heartbeat) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+	for {
+		if err := h.Check(ctx); err != nil {
+			fmt.Printf("💓 [%s] Heartbeat failed: %v\n", h.Client.Name(), err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *Heartbeat) Check(ctx context.Context) error {
+	content, err := h.fetch(ctx)
+	if err != nil { return err }
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	// 이전 기록을 못 읽은 채 진행하면 "첫 수신"으로 잘못 알리게 되므로 다음 주기에 다시 시도함
+	prev, err := h.Storage.GetLatestHeartbeat(ctx, h.Client.Name())
+	if err != nil { return fmt.Errorf("load heartbeat: %v", err) }
+	changed := prev == nil || prev.Hash != hash
+
+	// 재시작 후에도 최신 규칙을 적용하도록 변경 여부와 무관하게 정책을 다시 계산
+	oldPolicy := h.Client.Policy()
+	newPolicy := parsePolicy(content, oldPolicy)
+	policyChanged := newPolicy.String() != oldPolicy.String() || strings.Join(newPolicy.Endpoints, ",") != strings.Join(oldPolicy.Endpoints, ",")
+	if policyChanged { h.Client.SetPolicy(newPolicy) }
+
+	if err := h.Storage.SaveHeartbeat(ctx, domain.Heartbeat{Source: h.Client.Name(), URL: h.URL, Hash: hash, Content: content, Changed: changed, FetchedAt: time.Now()}); err != nil {
+		return fmt.Errorf("save heartbeat: %v", err)
+	}
+	if !changed { return nil }
+
+	fmt.Printf("💓 [%s] heartbeat.md changed (%s)\n", h.Client.Name(), hash[:8])
+	if h.UI == nil { return nil }
+
+	var body strings.Builder
+	if prev == nil {
+		fmt.Fprintf(&body, "첫 수신 (%d줄)\n", strings.Count(content, "\n")+1)
+	} else {
+		added, removed := diffLines(prev.Content, content)
+		fmt.Fprintf(&body, "변경: +%d / -%d 줄\n", len(added), len(removed))
+		writeLines(&body, "+ ", added)
+		writeLines(&body, "- ", removed)
+	}
+	if policyChanged {
+		fmt.Fprintf(&body, "\n⚙️ 정책 갱신: %s → %s\n", oldPolicy, newPolicy)
+		endpointsAdded, endpointsRemoved := diffLines(strings.Join(oldPolicy.Endpoints, "\n"), strings.Join(newPolicy.Endpoints, "\n"))
+		writeLines(&body, "+ ", endpointsAdded)
+		writeLines(&body, "- ", endpointsRemoved)
+	}
+	return h.UI.Notify(ctx, fmt.Sprintf("💓 [%s] Heartbeat 변경", h.Client.Name()), body.String())
+}
+
+func (h *Heartbeat) fetch(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", h.URL, nil)
+	if err != nil { return "", err }
+	resp, err := h.Client.HTTPClient.Do(req)
+	if err != nil { return "", err }
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK { return "", fmt.Errorf("heartbeat fetch failed: %d", resp.StatusCode) }
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil { return "", err }
+	return string(body), nil
+}
+
+var (
+	durationRe = regexp.MustCompile(`(?i)(\d+)\s*(seconds?|secs?|minutes?|mins?|hours?|초|분|시간)`)
+	perUnitRe  = regexp.MustCompile(`(?i)\bper\s+(second|minute|hour)\b|(초|분|시간)당`) // 숫자 없는 "per hour", "시간당"은 1단위
+	countRe    = regexp.MustCompile(`(?i)(\d+)\s*(posts?|comments?|requests?|개|회)`)
+	endpointRe = regexp.MustCompile("(GET|POST|PUT|PATCH|DELETE)[`|\\s]+(?:https?://[^/\\s`]+(?:/api/v\\d+)?)?(/[A-Za-z0-9_{}:/.-]+)")
+	// "1 post per 30 minutes", "댓글: 20초당 1개"처럼 속도 제한을 나타내는 줄만 해석
+	rateMarkerRe = regexp.MustCompile(`(?i)\bper\b|당|rate|limit|제한`)
+)
+
+// heartbeat 문서가 공지한 간격이 이보다 짧으면 이 값을 씁니다 (잘못 해석하거나 0을 공지해도 연속 작성하지 않도록).
+const (
+	minPostInterval    = 5 * time.Minute
+	minCommentInterval = 5 * time.Second
+)
+
+// parsePolicy는 heartbeat 문서에서 속도 제한과 엔드포인트를 추출해 base 위에 덮어씁니다.
+// 인식하지 못한 항목은 base 값을 그대로 유지합니다.
+func parsePolicy(content string, base Policy) Policy {
+	p := base
+	var endpoints []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		if m := endpointRe.FindStringSubmatch(line); m != nil {
+			ep := m[1] + " " + m[2]
+			if !seen[ep] { seen[ep] = true; endpoints = append(endpoints, ep) }
+			continue
+		}
+
+		lower := strings.ToLower(line)
+		if !rateMarkerRe.MatchString(line) { continue }
+		d, ok := parseDuration(line)
+		if !ok { continue }
+		isComment := strings.Contains(lower, "comment") || strings.Contains(line, "댓글")
+		isPost := !isComment && (strings.Contains(lower, "post") || strings.Contains(line, "글"))
+		isRequest := strings.Contains(lower, "request") || strings.Contains(line, "요청")
+
+		count := 1
+		if m := countRe.FindStringSubmatch(line); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n <= 0 { continue } // "0 requests" 같은 값으로 제한을 끄지 않음
+			count = n
+		}
+		switch {
+		case isRequest:
+			// 분당 1회 미만(예: 시간당 10회)도 0(무제한)이 되지 않도록 올림하고 최소 1로 둠
+			p.RequestsPerMinute = int(math.Max(1, math.Ceil(float64(count)*float64(time.Minute)/float64(d))))
+		case isComment:
+			p.CommentInterval = max(d/time.Duration(count), minCommentInterval)
+		case isPost:
+			p.PostInterval = max(d/time.Duration(count), minPostInterval)
+		}
+	}
+	if len(endpoints) > 0 { p.Endpoints = endpoints }
+	return p
+}
+
+func parseDuration(line string) (time.Duration, bool) {
+	n, unit := 1, ""
+	if m := durationRe.FindStringSubmatch(line); m != nil {
+		var err error
+		if n, err = strconv.Atoi(m[1]); err != nil || n <= 0 { return 0, false }
+		unit = strings.ToLower(m[2])
+	} else if m := perUnitRe.FindStringSubmatch(line); m != nil {
+		unit = strings.ToLower(m[1] + m[2])
+	} else {
+		return 0, false
+	}
+	switch {
+	case strings.HasPrefix(unit, "sec"), unit == "초":
+		return time.Duration(n) * time.Second, true
+	case strings.HasPrefix(unit, "min"), unit == "분":
+		return time.Duration(n) * time.Minute, true
+	default:
+		return time.Duration(n) * time.Hour, true
+	}
+}
+
+// diffLines는 줄 단위로 추가/삭제된 내용을 순서대로 돌려줍니다.
+func diffLines(oldText, newText string) (added, removed []string) {
+	oldSet := make(map[string]int)
+	for _, l := range strings.Split(oldText, "\n") { oldSet[strings.TrimSpace(l)]++ }
+	newSet := make(map[string]int)
+	for _, l := range strings.Split(newText, "\n") { newSet[strings.TrimSpace(l)]++ }
+
+	for _, l := range strings.Split(newText, "\n") {
+		if l = strings.TrimSpace(l); l != "" && oldSet[l] == 0 { added = append(added, l) }
+	}
+	for _, l := range strings.Split(oldText, "\n") {
+		if l = strings.TrimSpace(l); l != "" && newSet[l] == 0 { removed = append(removed, l) }
+	}
+	return added, removed
+}
+
+func writeLines(b *strings.Builder, prefix string, lines []string) {
+	const maxLines = 10
+	for i, l := range lines {
+		if i == maxLines {
+			fmt.Fprintf(b, "%s... (%d줄 더)\n", prefix, len(lines)-maxLines)
+			return
+		}
+		fmt.Fprintf(b, "%s%s\n", prefix, l)
+	}
+}
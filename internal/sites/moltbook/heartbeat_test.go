@@ -0,0 +1,78 @@
+package moltbook
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePolicy(t *testing.T) {
+	base := DefaultPolicy()
+	with := func(f func(p *Policy)) Policy {
+		p := base
+		f(&p)
+		return p
+	}
+	tests := []struct {
+		name    string
+		content string
+		want    Policy
+	}{
+		{"empty keeps base", "", base},
+		{"post interval", "- 1 post per 30 minutes", with(func(p *Policy) { p.PostInterval = 30 * time.Minute })},
+		{"post count divides", "Rate limit: 2 posts per 1 hour", with(func(p *Policy) { p.PostInterval = 30 * time.Minute })},
+		{"comment korean", "댓글: 10초당 1개", with(func(p *Policy) { p.CommentInterval = 10 * time.Second })},
+		{"comment before post", "1 comment per 45 seconds (posts are separate)", with(func(p *Policy) { p.CommentInterval = 45 * time.Second })},
+		{"requests per minute", "60 requests per 30 seconds", with(func(p *Policy) { p.RequestsPerMinute = 120 })},
+		{"requests korean", "요청 제한: 1분당 50회", with(func(p *Policy) { p.RequestsPerMinute = 50 })},
+		{"requests per hour rounds up", "API rate limit: 10 requests per hour", with(func(p *Policy) { p.RequestsPerMinute = 1 })},
+		{"requests per hour korean", "요청 제한: 시간당 30회", with(func(p *Policy) { p.RequestsPerMinute = 1 })},
+		{"comment per unit", "1 comment per minute", with(func(p *Policy) { p.CommentInterval = time.Minute })},
+		{"requests per hour above one a minute", "90 requests per 1 hour", with(func(p *Policy) { p.RequestsPerMinute = 2 })},
+		{"zero requests ignored", "Rate limit: 0 requests per minute", base},
+		{"zero posts ignored", "0 posts per 30 minutes", base},
+		{"zero duration ignored", "1 post per 0 minutes", base},
+		{"post interval clamped", "Rate limit: 60 posts per 1 minute", with(func(p *Policy) { p.PostInterval = minPostInterval })},
+		{"comment interval clamped", "100 comments per 1 second", with(func(p *Policy) { p.CommentInterval = minCommentInterval })},
+		{"no rate marker ignored", "Check back every 4 hours for new posts", base},
+		{"no duration ignored", "Posting limit applies to new agents", base},
+		{"endpoints deduplicated", "- `GET /posts`\n- `POST https://www.moltbook.com/api/v1/posts/{id}/comments`\n- GET /posts",
+			with(func(p *Policy) { p.Endpoints = []string{"GET /posts", "POST /posts/{id}/comments"} })},
+		{"mixed document", "## Limits\n- 1 post per 20 minutes\n- 1 comment per 30 seconds\n\nDELETE /posts/{id}",
+			with(func(p *Policy) {
+				p.PostInterval = 20 * time.Minute
+				p.CommentInterval = 30 * time.Second
+				p.Endpoints = []string{"DELETE /posts/{id}"}
+			})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePolicy(tt.content, base); !reflect.DeepEqual(got, tt.want) { t.Errorf("parsePolicy(%q) = %+v, want %+v", tt.content, got, tt.want) }
+		})
+	}
+}
+
+func TestParsePolicyKeepsBaseEndpoints(t *testing.T) {
+	base := DefaultPolicy()
+	base.Endpoints = []string{"GET /feed"}
+	if got := parsePolicy("1 post per 10 minutes", base); !reflect.DeepEqual(got.Endpoints, base.Endpoints) { t.Errorf("endpoints = %v, want base %v", got.Endpoints, base.Endpoints) }
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name           string
+		old, new       string
+		added, removed []string
+	}{
+		{"same", "a\nb", "a\nb", nil, nil},
+		{"added and removed", "a\nb", "a\nc", []string{"c"}, []string{"b"}},
+		{"whitespace only", "  a  \n", "a", nil, nil},
+		{"blank lines ignored", "a", "a\n\n", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := diffLines(tt.old, tt.new)
+			if !reflect.DeepEqual(added, tt.added) || !reflect.DeepEqual(removed, tt.removed) { t.Errorf("diffLines = +%v -%v, want +%v -%v", added, removed, tt.added, tt.removed) }
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package moltbook
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type RegisterRequest struct {
 	Name        string `json:"name"`
@@ -20,10 +23,53 @@ type RegisterResponse struct {
 	NextSteps []string `json:"next_steps"`
 }
 
+// CreatePostRequest는 submolt(커뮤니티)를 지정한 게시글 작성 요청입니다.
+type CreatePostRequest struct {
+	Submolt string `json:"submolt"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// CreateCommentRequest는 댓글/답글 작성 요청입니다. ParentID가 비어 있으면 댓글입니다.
+type CreateCommentRequest struct {
+	Content  string `json:"content"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
 type ApiPost struct {
-	ID         string    `json:"id"`
-	Title      string    `json:"title"`
-	Content    string    `json:"content"`
-	AuthorName string    `json:"author_name"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	AuthorName   string    `json:"author_name"`
+	Author       nameRef   `json:"author"`
+	Submolt      nameRef   `json:"submolt"`
+	CommentCount int       `json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ApiNotification struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	ActorName      string    `json:"actor_name"`
+	PostID         string    `json:"post_id"`
+	PostTitle      string    `json:"post_title"`
+	CommentID      string    `json:"comment_id"`
+	ContentPreview string    `json:"content_preview"`
+	IsRead         bool      `json:"is_read"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// nameRef는 "general" 같은 문자열과 {"name": "general"} 형태의 객체를 모두 받아들입니다.
+type nameRef string
+
+func (n *nameRef) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*n = nameRef(s)
+		return nil
+	}
+	var obj struct{ Name string `json:"name"` }
+	if err := json.Unmarshal(data, &obj); err != nil { return err }
+	*n = nameRef(obj.Name)
+	return nil
 }
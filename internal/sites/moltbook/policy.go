@@ -0,0 +1,98 @@
+package moltbook
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Policy는 Moltbook의 속도 제한 정책입니다. 런타임에 SetPolicy로 갱신할 수 있습니다.
+type Policy struct {
+	PostInterval      time.Duration // 글 작성 최소 간격
+	CommentInterval   time.Duration // 댓글 작성 최소 간격
+	RequestsPerMinute int           // 전체 API 요청 분당 한도
+	Endpoints         []string      // heartbeat에 공지된 엔드포인트 (예: "GET /posts")
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		PostInterval:      30 * time.Minute,
+		CommentInterval:   20 * time.Second,
+		RequestsPerMinute: 100,
+	}
+}
+
+func (p Policy) String() string {
+	return fmt.Sprintf("post=%v comment=%v rpm=%d", p.PostInterval, p.CommentInterval, p.RequestsPerMinute)
+}
+
+func (c *Client) Policy() Policy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
+func (c *Client) SetPolicy(p Policy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = p
+}
+
+// enforceRateLimit은 정책에 따라 쓰기 작업 간의 최소 간격을 강제합니다.
+// 대기 시간은 잠금 안에서 계산해 자리를 예약하고, 잠금을 푼 뒤 기다립니다 (대기 중에도 정책 갱신과 다른 요청이 막히지 않음).
+func (c *Client) enforceRateLimit(ctx context.Context, isPost bool) error {
+	c.mu.Lock()
+	interval, last := c.policy.CommentInterval, &c.lastCommentTime
+	if interval > 0 { interval += time.Second } // 안전 여유 1초
+	if isPost { interval, last = c.policy.PostInterval, &c.lastPostTime }
+	now := time.Now()
+	slot := now
+	if next := last.Add(interval); next.After(slot) { slot = next }
+	*last = slot
+	c.mu.Unlock()
+
+	if wait := slot.Sub(now); wait > 0 {
+		fmt.Printf("⏳ [%s] 정책 준수를 위해 %v 동안 대기합니다...\n", c.Name(), wait.Round(time.Second))
+		return sleep(ctx, wait)
+	}
+	return nil
+}
+
+// throttle은 최근 1분간의 요청 수가 한도를 넘지 않도록 대기합니다 (슬라이딩 윈도우).
+// 한도가 찼으면 가장 이른 요청이 윈도우를 벗어나는 시각으로 자리를 예약하고 잠금 밖에서 기다립니다.
+func (c *Client) throttle(ctx context.Context) error {
+	c.mu.Lock()
+	if c.policy.RequestsPerMinute <= 0 { c.mu.Unlock(); return nil }
+	now := time.Now()
+	kept := c.requestTimes[:0]
+	for _, t := range c.requestTimes {
+		if now.Sub(t) < time.Minute { kept = append(kept, t) }
+	}
+	c.requestTimes = kept
+
+	slot := now
+	if n := len(c.requestTimes); n >= c.policy.RequestsPerMinute {
+		slot = c.requestTimes[n-c.policy.RequestsPerMinute].Add(time.Minute)
+	}
+	if n := len(c.requestTimes); n > 0 && c.requestTimes[n-1].After(slot) { slot = c.requestTimes[n-1] } // 예약 순서 유지
+	c.requestTimes = append(c.requestTimes, slot)
+	c.mu.Unlock()
+
+	if wait := slot.Sub(now); wait > 0 {
+		fmt.Printf("⏳ [%s] 분당 요청 한도 도달, %v 대기합니다...\n", c.Name(), wait.Round(time.Second))
+		return sleep(ctx, wait)
+	}
+	return nil
+}
+
+// sleep은 d만큼 기다립니다. 그 전에 ctx가 끝나면 ctx.Err()를 돌려줍니다.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+package sites
+
+import (
+	"d3k-agent/internal/core/ports"
+	"d3k-agent/internal/sites/botmadang"
+	"d3k-agent/internal/sites/mastodon"
+	"d3k-agent/internal/sites/moltbook"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Deps는 사이트 어댑터 생성에 필요한 공용 의존성입니다.
+type Deps struct {
+	Storage ports.Storage
+	UI      ports.Interaction
+}
+
+// Factory는 설정된 의존성으로 사이트 어댑터를 만듭니다.
+type Factory func(deps Deps) (ports.Site, error)
+
+// Registry는 사이트 이름별 Factory 목록입니다.
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// DefaultRegistry는 이 저장소에 포함된 모든 사이트 어댑터를 등록한 Registry를 돌려줍니다.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("botmadang", func(d Deps) (ports.Site, error) {
+		c, err := botmadang.NewClient(d.Storage, d.UI)
+		if err != nil { return nil, err } // nil *Client를 인터페이스로 감싸지 않도록
+		return c, nil
+	})
+	r.Register("moltbook", func(d Deps) (ports.Site, error) {
+		c, err := moltbook.NewClient(d.Storage, d.UI)
+		if err != nil { return nil, err }
+		return c, nil
+	})
+	r.Register("mastodon", func(d Deps) (ports.Site, error) {
+		c, err := mastodon.NewClient(d.Storage, d.UI)
+		if err != nil { return nil, err }
+		return c, nil
+	})
+	return r
+}
+
+func (r *Registry) Register(name string, f Factory) {
+	r.factories[strings.ToLower(name)] = f
+}
+
+func (r *Registry) Names() []string {
+	var names []string
+	for name := range r.factories { names = append(names, name) }
+	sort.Strings(names)
+	return names
+}
+
+// Build는 names 순서대로 사이트를 생성합니다. 등록되지 않은 이름은 에러입니다.
+func (r *Registry) Build(names []string, deps Deps) ([]ports.Site, error) {
+	var result []ports.Site
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] { continue }
+		f, ok := r.factories[name]
+		if !ok { return nil, fmt.Errorf("unknown site %q (available: %s)", name, strings.Join(r.Names(), ", ")) }
+		site, err := f(deps)
+		if err != nil { return nil, fmt.Errorf("create site %q: %v", name, err) }
+		seen[name] = true
+		result = append(result, site)
+	}
+	return result, nil
+}
+
+// EnabledFromEnv는 SITES 환경 변수(쉼표 구분)에서 활성화할 사이트 목록을 읽습니다.
+// 기본값은 botmadang이며, 이전 설정(MOLTBOOK_ENABLED=true)도 계속 인식합니다.
+func EnabledFromEnv() []string {
+	names := strings.Split(os.Getenv("SITES"), ",")
+	if strings.TrimSpace(os.Getenv("SITES")) == "" { names = []string{"botmadang"} }
+	if os.Getenv("MOLTBOOK_ENABLED") == "true" { names = append(names, "moltbook") }
+	return names
+}
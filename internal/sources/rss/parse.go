@@ -0,0 +1,123 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// feedDoc은 RSS 2.0, RSS 1.0(RDF), Atom 문서를 한 번에 받아들이는 디코딩용 구조체입니다.
+type feedDoc struct {
+	XMLName xml.Name
+	// RSS 2.0
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 (RDF)는 item이 루트 바로 아래에 있음
+	RDFItems []rssItem `xml:"item"`
+	// Atom
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Links     []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+}
+
+// entry는 포맷과 무관하게 정규화된 피드 항목입니다.
+type entry struct {
+	GUID      string
+	Title     string
+	Link      string
+	Summary   string
+	Published time.Time
+}
+
+func parseFeed(data []byte) (title string, entries []entry, err error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charset.NewReaderLabel // EUC-KR 등 XML 선언의 encoding을 UTF-8로 변환
+	var doc feedDoc
+	if err := dec.Decode(&doc); err != nil { return "", nil, err }
+
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		title = doc.Channel.Title
+		for _, it := range append(doc.Channel.Items, doc.RDFItems...) {
+			summary := it.Description
+			if summary == "" { summary = it.Encoded }
+			published := parseTime(it.PubDate)
+			if published.IsZero() { published = parseTime(it.Date) }
+			entries = append(entries, entry{GUID: it.GUID, Title: it.Title, Link: strings.TrimSpace(it.Link), Summary: summary, Published: published})
+		}
+	case "feed":
+		title = doc.Title
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" { link = l.Href; break }
+			}
+			summary := e.Summary
+			if summary == "" { summary = e.Content }
+			published := parseTime(e.Published)
+			if published.IsZero() { published = parseTime(e.Updated) }
+			entries = append(entries, entry{GUID: e.ID, Title: e.Title, Link: link, Summary: summary, Published: published})
+		}
+	default:
+		return "", nil, fmt.Errorf("unknown feed format <%s>", doc.XMLName.Local)
+	}
+
+	for i := range entries {
+		entries[i].Title = cleanText(entries[i].Title, 200)
+		entries[i].Summary = cleanText(entries[i].Summary, 500)
+	}
+	return strings.TrimSpace(title), entries, nil
+}
+
+var timeLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2006-01-02 15:04:05", "2006-01-02",
+}
+
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil { return t }
+	}
+	return time.Time{}
+}
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// cleanText는 HTML 태그와 엔티티를 제거하고 공백을 정리한 뒤 max 글자로 자릅니다.
+func cleanText(s string, max int) string {
+	s = html.UnescapeString(tagRe.ReplaceAllString(s, " "))
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max { s = string(r[:max]) + "…" }
+	return s
+}
@@ -0,0 +1,153 @@
+package rss
+
+import (
+	"context"
+	"crypto/sha256"
+	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Feed는 읽어올 피드 하나의 설정입니다. Location은 http(s) URL 또는 로컬 파일 경로입니다.
+type Feed struct {
+	Category string
+	Location string
+}
+
+// Reader는 설정된 RSS/Atom 피드를 읽는 읽기 전용 지식 소스(ports.Source)입니다.
+// 피드별 ETag/Last-Modified는 Storage 커서에 저장되어 조건부 요청에 사용됩니다.
+type Reader struct {
+	Feeds      []Feed
+	HTTPClient *http.Client
+	Storage    ports.Storage
+}
+
+func NewReader(storage ports.Storage, feeds []Feed) *Reader {
+	return &Reader{
+		Feeds:      feeds,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+		Storage:    storage,
+	}
+}
+
+var _ ports.Source = (*Reader)(nil)
+
+func (r *Reader) Name() string {
+	return "rss"
+}
+
+// ParseFeeds는 "카테고리=위치" 또는 "위치" 항목을 쉼표로 구분한 설정 문자열을 해석합니다.
+// 예: "금융 경제=https://example.com/finance.xml,IT 기술=./testdata/tech.xml"
+func ParseFeeds(spec string) []Feed {
+	var feeds []Feed
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" { continue }
+		f := Feed{Location: item}
+		if i := strings.Index(item, "="); i > 0 && !strings.Contains(item[:i], "/") {
+			f.Category = strings.TrimSpace(item[:i])
+			f.Location = strings.TrimSpace(item[i+1:])
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds
+}
+
+// validators는 조건부 요청용 검증 값입니다.
+type validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Fetch는 모든 피드를 읽어 항목을 돌려줍니다. 변경이 없는 피드(304, mtime 동일)는 건너뜁니다.
+// 한 피드의 실패는 로그만 남기고 나머지 피드는 계속 읽습니다.
+func (r *Reader) Fetch(ctx context.Context) ([]domain.FeedItem, error) {
+	var items []domain.FeedItem
+	failed := 0
+	for _, f := range r.Feeds {
+		got, err := r.fetchOne(ctx, f)
+		if err != nil {
+			failed++
+			fmt.Printf("    📰 [%s] %s: %v\n", r.Name(), f.Location, err)
+			continue
+		}
+		items = append(items, got...)
+	}
+	if failed > 0 && failed == len(r.Feeds) { return nil, fmt.Errorf("all %d feeds failed", failed) }
+	return items, nil
+}
+
+func (r *Reader) fetchOne(ctx context.Context, f Feed) ([]domain.FeedItem, error) {
+	cursorKey := "feed:" + f.Location
+	var prev validators
+	if raw, _ := r.Storage.LoadCursor(cursorKey); raw != "" { json.Unmarshal([]byte(raw), &prev) }
+
+	var data []byte
+	var next validators
+	var err error
+	if strings.HasPrefix(f.Location, "http://") || strings.HasPrefix(f.Location, "https://") {
+		data, next, err = r.fetchHTTP(ctx, f.Location, prev)
+	} else {
+		data, next, err = readFile(strings.TrimPrefix(f.Location, "file://"), prev)
+	}
+	if err != nil || data == nil { return nil, err }
+
+	title, entries, err := parseFeed(data)
+	if err != nil { return nil, fmt.Errorf("parse: %v", err) }
+
+	now := time.Now()
+	var items []domain.FeedItem
+	for _, e := range entries {
+		key := e.GUID
+		if key == "" { key = e.Link }
+		if key == "" { key = e.Title }
+		sum := sha256.Sum256([]byte(f.Location + "\n" + key))
+		published := e.Published
+		if published.IsZero() { published = now }
+		items = append(items, domain.FeedItem{
+			ID: hex.EncodeToString(sum[:16]), Feed: f.Location, FeedTitle: title, Category: f.Category,
+			Title: e.Title, Link: e.Link, Summary: e.Summary, PublishedAt: published, FetchedAt: now,
+		})
+	}
+
+	// 파싱까지 성공한 경우에만 검증 값을 갱신해 다음 요청에서 재시도할 수 있게 함
+	if encoded, err := json.Marshal(next); err == nil { r.Storage.SaveCursor(cursorKey, string(encoded)) }
+	return items, nil
+}
+
+// fetchHTTP는 If-None-Match/If-Modified-Since로 요청합니다. 304면 data가 nil입니다.
+func (r *Reader) fetchHTTP(ctx context.Context, url string, prev validators) ([]byte, validators, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil { return nil, prev, err }
+	req.Header.Set("User-Agent", "d3k-agent/1.0 (+https://github.com/dd3ok/d3k-agent)")
+	if prev.ETag != "" { req.Header.Set("If-None-Match", prev.ETag) }
+	if prev.LastModified != "" { req.Header.Set("If-Modified-Since", prev.LastModified) }
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil { return nil, prev, err }
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified { return nil, prev, nil }
+	if resp.StatusCode != http.StatusOK { return nil, prev, fmt.Errorf("status %d", resp.StatusCode) }
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+	if err != nil { return nil, prev, err }
+	return data, validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, nil
+}
+
+// readFile은 오프라인 테스트용 로컬 피드를 읽습니다. 수정 시각이 같으면 data가 nil입니다.
+func readFile(path string, prev validators) ([]byte, validators, error) {
+	info, err := os.Stat(path)
+	if err != nil { return nil, prev, err }
+	mtime := info.ModTime().UTC().Format(http.TimeFormat)
+	if mtime == prev.LastModified { return nil, prev, nil }
+	data, err := os.ReadFile(path)
+	if err != nil { return nil, prev, err }
+	return data, validators{LastModified: mtime}, nil
+}
@@ -0,0 +1,172 @@
+package rss
+
+import (
+	"context"
+	"d3k-agent/internal/core/ports"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// cursorStore는 Reader가 쓰는 커서 메서드만 구현합니다.
+type cursorStore struct {
+	ports.Storage
+	cursors map[string]string
+}
+
+func (s *cursorStore) SaveCursor(source, cursor string) error {
+	s.cursors[source] = cursor
+	return nil
+}
+
+func (s *cursorStore) LoadCursor(source string) (string, error) {
+	return s.cursors[source], nil
+}
+
+const rss2 = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel><title> 경제 뉴스 </title>
+<item><title>금리 &amp; 환율</title><link> https://example.com/a </link><guid>a-1</guid>
+<description>&lt;p&gt;한국은행이   기준금리를&lt;/p&gt; 동결</description><pubDate>Mon, 02 Jan 2006 15:04:05 +0900</pubDate></item>
+<item><title>본문만 있는 글</title><link>https://example.com/b</link><content:encoded><![CDATA[<b>본문</b>]]></content:encoded></item>
+</channel></rss>`
+
+const atom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Tech</title>
+<entry><id>urn:1</id><title>Go 1.30</title>
+<link rel="self" href="https://example.com/self"/><link href="https://example.com/go"/>
+<summary>릴리스</summary><updated>2026-01-02T03:04:05Z</updated></entry>
+</feed>`
+
+const rdf = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel><title>RDF</title></channel>
+<item><title>항목</title><link>https://example.com/r</link><dc:date>2026-03-04</dc:date></item>
+</rdf:RDF>`
+
+// eucKR은 EUC-KR로 인코딩된 RSS입니다 (국내 금융/IT 매체에서 아직 흔함).
+var eucKR = `<?xml version="1.0" encoding="EUC-KR"?><rss><channel><title>` + "\xb0\xe6\xc1\xa6" + `</title>
+<item><title>` + "\xb1\xdd\xb8\xae \xb5\xbf\xb0\xe1" + `</title><link>https://example.com/k</link></item></channel></rss>`
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantTitle string
+		want      []entry
+		wantErr   bool
+	}{
+		{"rss 2.0", rss2, "경제 뉴스", []entry{
+			{GUID: "a-1", Title: "금리 & 환율", Link: "https://example.com/a", Summary: "한국은행이 기준금리를 동결", Published: time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("", 9*3600))},
+			{Title: "본문만 있는 글", Link: "https://example.com/b", Summary: "본문"},
+		}, false},
+		{"atom", atom, "Tech", []entry{
+			{GUID: "urn:1", Title: "Go 1.30", Link: "https://example.com/go", Summary: "릴리스", Published: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		}, false},
+		{"rss 1.0", rdf, "RDF", []entry{
+			{Title: "항목", Link: "https://example.com/r", Published: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		}, false},
+		{"unknown root", `<html><title>x</title></html>`, "", nil, true},
+		{"euc-kr", eucKR, "경제", []entry{{Title: "금리 동결", Link: "https://example.com/k"}}, false},
+		{"unknown charset", `<?xml version="1.0" encoding="x-klingon"?><rss><channel></channel></rss>`, "", nil, true},
+		{"broken xml", `<rss><channel>`, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, entries, err := parseFeed([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil { t.Errorf("parseFeed = %q %+v, want error", title, entries) }
+				return
+			}
+			if err != nil { t.Fatalf("parseFeed: %v", err) }
+			if title != tt.wantTitle { t.Errorf("title = %q, want %q", title, tt.wantTitle) }
+			if len(entries) != len(tt.want) { t.Fatalf("got %d entries %+v, want %d", len(entries), entries, len(tt.want)) }
+			for i, w := range tt.want {
+				got := entries[i]
+				if !got.Published.Equal(w.Published) { t.Errorf("entry %d published = %v, want %v", i, got.Published, w.Published) }
+				got.Published, w.Published = time.Time{}, time.Time{}
+				if !reflect.DeepEqual(got, w) { t.Errorf("entry %d = %+v, want %+v", i, got, w) }
+			}
+		})
+	}
+}
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"<p>a</p><p>b</p>", 10, "a b"},
+		{"  여러   \n 공백  ", 10, "여러 공백"},
+		{"&lt;태그&gt; &quot;인용&quot;", 20, `<태그> "인용"`},
+		{"가나다라마", 3, "가나다…"},
+	}
+	for _, tt := range tests {
+		if got := cleanText(tt.in, tt.max); got != tt.want { t.Errorf("cleanText(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want) }
+	}
+}
+
+func TestParseFeeds(t *testing.T) {
+	got := ParseFeeds(" 금융 경제=https://example.com/f.xml, https://example.com/a?x=1, ,IT=./testdata/tech.xml")
+	want := []Feed{
+		{Category: "금융 경제", Location: "https://example.com/f.xml"},
+		{Location: "https://example.com/a?x=1"},
+		{Category: "IT", Location: "./testdata/tech.xml"},
+	}
+	if !reflect.DeepEqual(got, want) { t.Errorf("ParseFeeds = %+v, want %+v", got, want) }
+}
+
+func TestFetchConditionalGET(t *testing.T) {
+	var requests []http.Header
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Clone())
+		if r.Header.Get("If-None-Match") == `"v1"` && status == http.StatusOK { w.WriteHeader(http.StatusNotModified); return }
+		if status != http.StatusOK { w.WriteHeader(status); return }
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Write([]byte(rss2))
+	}))
+	defer srv.Close()
+
+	store := &cursorStore{cursors: map[string]string{}}
+	r := NewReader(store, []Feed{{Category: "경제", Location: srv.URL}})
+	ctx := context.Background()
+
+	items, err := r.Fetch(ctx)
+	if err != nil || len(items) != 2 { t.Fatalf("first fetch = %d items, %v; want 2", len(items), err) }
+	if items[0].Category != "경제" || items[0].FeedTitle != "경제 뉴스" || items[0].ID == items[1].ID { t.Errorf("items = %+v", items) }
+	if requests[0].Get("If-None-Match") != "" { t.Error("first request must be unconditional") }
+
+	items, err = r.Fetch(ctx)
+	if err != nil || len(items) != 0 { t.Fatalf("second fetch = %d items, %v; want 304 with no items", len(items), err) }
+	if requests[1].Get("If-None-Match") != `"v1"` || requests[1].Get("If-Modified-Since") != "Mon, 02 Jan 2006 15:04:05 GMT" { t.Errorf("conditional headers = %v", requests[1]) }
+
+	// 실패한 요청은 검증 값을 지우지 않음
+	status = http.StatusInternalServerError
+	if _, err := r.Fetch(ctx); err == nil { t.Error("all feeds failed, want error") }
+	if store.cursors["feed:"+srv.URL] == "" { t.Error("validators lost after a failed fetch") }
+}
+
+func TestFetchLocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tech.xml")
+	if err := os.WriteFile(path, []byte(atom), 0o644); err != nil { t.Fatal(err) }
+	store := &cursorStore{cursors: map[string]string{}}
+	r := NewReader(store, []Feed{{Location: path}, {Location: filepath.Join(t.TempDir(), "missing.xml")}})
+	ctx := context.Background()
+
+	items, err := r.Fetch(ctx)
+	if err != nil || len(items) != 1 { t.Fatalf("first read = %d items, %v; want 1 (missing feed only logged)", len(items), err) }
+	items, _ = r.Fetch(ctx)
+	if len(items) != 0 { t.Errorf("unchanged file returned %d items, want 0", len(items)) }
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil { t.Fatal(err) }
+	items, _ = r.Fetch(ctx)
+	if len(items) != 1 { t.Errorf("modified file returned %d items, want 1", len(items)) }
+}
@@ -1,34 +1,52 @@
 package storage
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 	"d3k-agent/internal/core/domain"
 	"d3k-agent/internal/core/ports"
 )
 
 type JSONStorage struct {
-	FilePath string
-	mu       sync.RWMutex
-	Data     StorageData
+	FilePath   string
+	VectorPath string // insight 임베딩을 한 줄씩 덧붙이는 파일 (벡터 때문에 본문 파일 전체를 다시 쓰지 않도록)
+	mu         sync.RWMutex
+	Data       StorageData
 }
 
 type StorageData struct {
-	Cursors           map[string]string   `json:"cursors"`
-	DailyPostCount    map[string]int      `json:"daily_post_count"`
-	LastPostDate      map[string]string   `json:"last_post_date"`
-	LastPostTimestamp map[string]int64    `json:"last_post_timestamp"`
-	DailyCommentCount map[string]int      `json:"daily_comment_count"`
-	LastCommentDate   map[string]string   `json:"last_comment_date"`
-	ProactivePostIDs  map[string][]string `json:"proactive_post_ids"`
+	Cursors           map[string]string                `json:"cursors"`
+	DailyPostCount    map[string]int                   `json:"daily_post_count"`
+	LastPostDate      map[string]string                `json:"last_post_date"`
+	LastPostTimestamp map[string]int64                 `json:"last_post_timestamp"`
+	DailyCommentCount map[string]int                   `json:"daily_comment_count"`
+	LastCommentDate   map[string]string                `json:"last_comment_date"`
+	ProactivePostIDs  map[string][]string              `json:"proactive_post_ids"`
+	Credentials       map[string]string                `json:"credentials"`
+	Heartbeats        []domain.Heartbeat               `json:"heartbeats"`
+	FeedItems         []domain.FeedItem                `json:"feed_items"`
+	Drafts            []domain.Draft                   `json:"drafts"`
+	Insights          []domain.Insight                 `json:"insights"`
+	Relationships     map[string]domain.Relationship   `json:"relationships"` // "source/author" 키
+	Usage             []domain.UsageEvent              `json:"usage"`
+	Cache             map[string]domain.CacheEntry     `json:"cache"`
+	Evaluations       map[string]domain.PostEvaluation `json:"evaluations"` // "source/post_id" 키
+	Knowledge         []domain.Knowledge               `json:"knowledge"`
+	PersonaFacts      []domain.PersonaFact             `json:"persona_facts"`
 }
 
 func NewJSONStorage(filePath string) (*JSONStorage, error) {
 	s := &JSONStorage{
-		FilePath: filePath,
+		FilePath:   filePath,
+		VectorPath: strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".vectors.jsonl",
 		Data: StorageData{
 			Cursors:           make(map[string]string),
 			DailyPostCount:    make(map[string]int),
@@ -37,11 +55,19 @@ func NewJSONStorage(filePath string) (*JSONStorage, error) {
 			DailyCommentCount: make(map[string]int),
 			LastCommentDate:   make(map[string]string),
 			ProactivePostIDs:  make(map[string][]string),
+			Credentials:       make(map[string]string),
+			Relationships:     make(map[string]domain.Relationship),
+			Cache:             make(map[string]domain.CacheEntry),
+			Evaluations:       make(map[string]domain.PostEvaluation),
 		},
 	}
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil { return nil, err }
 	if err := s.loadFromFile(); err != nil && !os.IsNotExist(err) { return nil, err }
+	if s.Data.Credentials == nil { s.Data.Credentials = make(map[string]string) }
+	if s.Data.Relationships == nil { s.Data.Relationships = make(map[string]domain.Relationship) }
+	if s.Data.Cache == nil { s.Data.Cache = make(map[string]domain.CacheEntry) }
+	if s.Data.Evaluations == nil { s.Data.Evaluations = make(map[string]domain.PostEvaluation) }
 	return s, nil
 }
 
@@ -50,7 +76,85 @@ var _ ports.Storage = (*JSONStorage)(nil)
 func (s *JSONStorage) loadFromFile() error {
 	file, err := os.ReadFile(s.FilePath)
 	if err != nil { return err }
-	return json.Unmarshal(file, &s.Data)
+	if err := json.Unmarshal(file, &s.Data); err != nil { return err }
+	return s.loadVectors(file)
+}
+
+// vectorRecord는 VectorPath 파일의 한 줄입니다. 같은 ID가 여러 번 나오면 마지막 줄이 이깁니다.
+type vectorRecord struct {
+	ID        int64     `json:"id"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// loadVectors는 VectorPath의 임베딩을 insight에 붙입니다.
+// 예전 형식(본문 파일의 insights[].Embedding)으로 저장된 벡터는 VectorPath로 옮기고 본문 파일에서 뺍니다.
+// 지워진 insight나 덮어쓴 벡터의 줄이 살아 있는 벡터보다 많이 쌓였으면 파일을 다시 씁니다.
+func (s *JSONStorage) loadVectors(raw []byte) error {
+	index := make(map[int64]int, len(s.Data.Insights))
+	for i, in := range s.Data.Insights { index[in.ID] = i }
+
+	lines := 0
+	f, err := os.Open(s.VectorPath)
+	if err != nil && !os.IsNotExist(err) { return err }
+	if err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 16<<20)
+		for sc.Scan() {
+			var rec vectorRecord
+			if json.Unmarshal(sc.Bytes(), &rec) != nil { continue } // 쓰다 만 마지막 줄
+			lines++
+			if i, ok := index[rec.ID]; ok { s.Data.Insights[i].Embedding = rec.Embedding }
+		}
+		f.Close()
+		if err := sc.Err(); err != nil { return err }
+	}
+
+	var legacy struct {
+		Insights []struct {
+			ID        int64
+			Embedding []float32
+		} `json:"insights"`
+	}
+	json.Unmarshal(raw, &legacy)
+	inline, migrated := false, false
+	for _, l := range legacy.Insights {
+		if len(l.Embedding) == 0 { continue }
+		inline = true
+		if i, ok := index[l.ID]; ok && len(s.Data.Insights[i].Embedding) == 0 { s.Data.Insights[i].Embedding, migrated = l.Embedding, true }
+	}
+
+	embedded := 0
+	for _, in := range s.Data.Insights {
+		if len(in.Embedding) > 0 { embedded++ }
+	}
+	if migrated || lines > 2*embedded {
+		if err := s.writeVectors(); err != nil { return err }
+	}
+	if inline { return s.saveToFile() }
+	return nil
+}
+
+// writeVectors는 살아 있는 insight의 임베딩만으로 VectorPath를 새로 씁니다.
+func (s *JSONStorage) writeVectors() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, in := range s.Data.Insights {
+		if len(in.Embedding) == 0 { continue }
+		if err := enc.Encode(vectorRecord{ID: in.ID, Embedding: in.Embedding}); err != nil { return err }
+	}
+	tmp := s.VectorPath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil { return err }
+	return os.Rename(tmp, s.VectorPath)
+}
+
+// appendVector는 insight 하나의 임베딩을 VectorPath 끝에 덧붙입니다.
+func (s *JSONStorage) appendVector(id int64, embedding []float32) error {
+	line, err := json.Marshal(vectorRecord{ID: id, Embedding: embedding})
+	if err != nil { return err }
+	f, err := os.OpenFile(s.VectorPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil { return err }
+	if _, err := f.Write(append(line, '\n')); err != nil { f.Close(); return err }
+	return f.Close()
 }
 
 func (s *JSONStorage) saveToFile() error {
@@ -126,6 +230,358 @@ func (s *JSONStorage) MarkProactive(source, postID string) error {
 	return s.saveToFile()
 }
 
-// Memory System Stubs
-func (s *JSONStorage) SaveInsight(ctx context.Context, insight domain.Insight) error { return nil }
-func (s *JSONStorage) GetRecentInsights(ctx context.Context, limit int) ([]domain.Insight, error) { return nil, nil }
+func (s *JSONStorage) CredentialsReady() error { return credentialsReady() }
+
+func (s *JSONStorage) SaveCredential(ctx context.Context, source, secret string) error {
+	sealed, err := sealSecret(source, secret)
+	if err != nil { return err }
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Data.Credentials[source] = sealed
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) LoadCredential(ctx context.Context, source string) (string, error) {
+	s.mu.RLock()
+	sealed := s.Data.Credentials[source]
+	s.mu.RUnlock()
+	if sealed == "" { return "", nil }
+	return openSecret(source, sealed)
+}
+
+// maxFeedItems는 JSON 파일에 보관할 피드 항목 개수입니다.
+const maxFeedItems = 500
+
+func (s *JSONStorage) SaveFeedItems(ctx context.Context, items []domain.FeedItem) ([]domain.FeedItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	known := make(map[string]bool, len(s.Data.FeedItems))
+	for _, it := range s.Data.FeedItems { known[it.ID] = true }
+
+	var added []domain.FeedItem
+	for _, it := range items {
+		if known[it.ID] { continue }
+		known[it.ID] = true
+		added = append(added, it)
+	}
+	if len(added) == 0 { return nil, nil }
+	s.Data.FeedItems = append(s.Data.FeedItems, added...)
+	sort.SliceStable(s.Data.FeedItems, func(i, j int) bool { return s.Data.FeedItems[i].PublishedAt.Before(s.Data.FeedItems[j].PublishedAt) })
+	if len(s.Data.FeedItems) > maxFeedItems {
+		s.Data.FeedItems = s.Data.FeedItems[len(s.Data.FeedItems)-maxFeedItems:]
+	}
+	return added, s.saveToFile()
+}
+
+func (s *JSONStorage) GetRecentFeedItems(ctx context.Context, limit int) ([]domain.FeedItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []domain.FeedItem
+	for i := len(s.Data.FeedItems) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, s.Data.FeedItems[i])
+	}
+	return res, nil
+}
+
+// maxHeartbeats는 JSON 파일에 보관할 하트비트 이력 개수입니다.
+const maxHeartbeats = 100
+
+func (s *JSONStorage) SaveHeartbeat(ctx context.Context, hb domain.Heartbeat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hb.ID = 1
+	if n := len(s.Data.Heartbeats); n > 0 { hb.ID = s.Data.Heartbeats[n-1].ID + 1 }
+	s.Data.Heartbeats = append(s.Data.Heartbeats, hb)
+	if len(s.Data.Heartbeats) > maxHeartbeats {
+		s.Data.Heartbeats = s.Data.Heartbeats[len(s.Data.Heartbeats)-maxHeartbeats:]
+	}
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) GetLatestHeartbeat(ctx context.Context, source string) (*domain.Heartbeat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := len(s.Data.Heartbeats) - 1; i >= 0; i-- {
+		if s.Data.Heartbeats[i].Source == source {
+			hb := s.Data.Heartbeats[i]
+			return &hb, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *JSONStorage) SaveKnowledge(ctx context.Context, k domain.Knowledge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if k.UpdatedAt.IsZero() { k.UpdatedAt = now }
+	for i := range s.Data.Knowledge {
+		if k.ID != 0 && s.Data.Knowledge[i].ID == k.ID {
+			k.CreatedAt = s.Data.Knowledge[i].CreatedAt
+			s.Data.Knowledge[i] = k
+			return s.saveToFile()
+		}
+	}
+	k.ID = 1
+	if n := len(s.Data.Knowledge); n > 0 { k.ID = s.Data.Knowledge[n-1].ID + 1 }
+	if k.CreatedAt.IsZero() { k.CreatedAt = now }
+	s.Data.Knowledge = append(s.Data.Knowledge, k)
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) GetKnowledge(ctx context.Context, limit int) ([]domain.Knowledge, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := append([]domain.Knowledge(nil), s.Data.Knowledge...)
+	sort.SliceStable(res, func(i, j int) bool { return res[i].UpdatedAt.After(res[j].UpdatedAt) })
+	if len(res) > limit { res = res[:limit] }
+	return res, nil
+}
+
+func (s *JSONStorage) DeleteStaleKnowledge(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []domain.Knowledge
+	for _, k := range s.Data.Knowledge {
+		if !k.UpdatedAt.Before(before) { kept = append(kept, k) }
+	}
+	removed := len(s.Data.Knowledge) - len(kept)
+	if removed == 0 { return 0, nil }
+	s.Data.Knowledge = kept
+	return removed, s.saveToFile()
+}
+
+func (s *JSONStorage) SavePersonaFact(ctx context.Context, f domain.PersonaFact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if f.LastSeen.IsZero() { f.LastSeen = now }
+	key := statementKey(f.Statement)
+	for i := range s.Data.PersonaFacts {
+		if statementKey(s.Data.PersonaFacts[i].Statement) == key {
+			s.Data.PersonaFacts[i].LastSeen = f.LastSeen
+			return s.saveToFile()
+		}
+	}
+	f.ID = 1
+	if n := len(s.Data.PersonaFacts); n > 0 { f.ID = s.Data.PersonaFacts[n-1].ID + 1 }
+	if f.CreatedAt.IsZero() { f.CreatedAt = now }
+	s.Data.PersonaFacts = append(s.Data.PersonaFacts, f)
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) RetirePersonaFact(ctx context.Context, statement string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := statementKey(statement)
+	kept := s.Data.PersonaFacts[:0]
+	for _, f := range s.Data.PersonaFacts {
+		if statementKey(f.Statement) != key { kept = append(kept, f) }
+	}
+	if len(kept) == len(s.Data.PersonaFacts) { return nil }
+	s.Data.PersonaFacts = kept
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) GetPersonaFacts(ctx context.Context, limit int) ([]domain.PersonaFact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := append([]domain.PersonaFact(nil), s.Data.PersonaFacts...)
+	sort.SliceStable(res, func(i, j int) bool { return res[i].LastSeen.After(res[j].LastSeen) })
+	if len(res) > limit { res = res[:limit] }
+	return res, nil
+}
+
+func (s *JSONStorage) GetRelationship(ctx context.Context, source, author string) (*domain.Relationship, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.Data.Relationships[source+"/"+author]
+	if !ok { return nil, nil }
+	return &r, nil
+}
+
+func (s *JSONStorage) SaveRelationship(ctx context.Context, r domain.Relationship) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Data.Relationships[r.Source+"/"+r.Author] = r
+	return s.saveToFile()
+}
+
+// usageRetention은 LLM 호출 기록 보관 기간입니다 (할당량 윈도우는 24시간). Postgres 저장소도 같은 값을 씁니다.
+const usageRetention = 7 * 24 * time.Hour
+
+func (s *JSONStorage) SaveUsage(ctx context.Context, e domain.UsageEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.ID = 1
+	if n := len(s.Data.Usage); n > 0 { e.ID = s.Data.Usage[n-1].ID + 1 }
+	s.Data.Usage = append(s.Data.Usage, e)
+	cut := 0
+	for cut < len(s.Data.Usage) && time.Since(s.Data.Usage[cut].At) > usageRetention { cut++ }
+	s.Data.Usage = s.Data.Usage[cut:]
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) GetUsageSince(ctx context.Context, since time.Time) ([]domain.UsageEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []domain.UsageEvent
+	for _, e := range s.Data.Usage {
+		if !e.At.Before(since) { res = append(res, e) }
+	}
+	return res, nil
+}
+
+// maxEvaluations는 JSON 파일에 보관할 글 평가 기록 개수입니다 (넘으면 오래된 평가부터 지움).
+const maxEvaluations = 1000
+
+func (s *JSONStorage) GetPostEvaluation(ctx context.Context, source, postID string) (*domain.PostEvaluation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.Data.Evaluations[source+"/"+postID]
+	if !ok { return nil, nil }
+	return &e, nil
+}
+
+func (s *JSONStorage) SavePostEvaluation(ctx context.Context, e domain.PostEvaluation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.EvaluatedAt.IsZero() { e.EvaluatedAt = time.Now() }
+	s.Data.Evaluations[e.Source+"/"+e.PostID] = e
+	for len(s.Data.Evaluations) > maxEvaluations {
+		oldest := ""
+		for k, v := range s.Data.Evaluations {
+			if oldest == "" || v.EvaluatedAt.Before(s.Data.Evaluations[oldest].EvaluatedAt) { oldest = k }
+		}
+		delete(s.Data.Evaluations, oldest)
+	}
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) GetCachedResponse(ctx context.Context, key string) (*domain.CacheEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.Data.Cache[key]
+	if !ok || time.Now().After(e.ExpiresAt) { return nil, nil }
+	return &e, nil
+}
+
+// SaveCachedResponse는 항목을 저장하면서 만료된 항목을 지웁니다.
+func (s *JSONStorage) SaveCachedResponse(ctx context.Context, e domain.CacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.CreatedAt.IsZero() { e.CreatedAt = time.Now() }
+	for k, old := range s.Data.Cache {
+		if time.Now().After(old.ExpiresAt) { delete(s.Data.Cache, k) }
+	}
+	s.Data.Cache[e.Key] = e
+	return s.saveToFile()
+}
+
+// maxDrafts는 JSON 파일에 보관할 생성 기록 개수입니다.
+const maxDrafts = 200
+
+func (s *JSONStorage) SaveDraft(ctx context.Context, d domain.Draft) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.ID = 1
+	if n := len(s.Data.Drafts); n > 0 { d.ID = s.Data.Drafts[n-1].ID + 1 }
+	if d.CreatedAt.IsZero() { d.CreatedAt = time.Now() }
+	s.Data.Drafts = append(s.Data.Drafts, d)
+	if len(s.Data.Drafts) > maxDrafts {
+		s.Data.Drafts = s.Data.Drafts[len(s.Data.Drafts)-maxDrafts:]
+	}
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) GetRecentDrafts(ctx context.Context, limit int) ([]domain.Draft, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []domain.Draft
+	for i := len(s.Data.Drafts) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, s.Data.Drafts[i])
+	}
+	return res, nil
+}
+
+// maxInsights는 JSON 파일에 보관할 insight(장기 기억) 개수입니다.
+const maxInsights = 1000
+
+func (s *JSONStorage) SaveInsight(ctx context.Context, insight domain.Insight) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.findInsight(insight.Source, insight.PostID, insight.ContentHash); i >= 0 {
+		old := &s.Data.Insights[i]
+		old.Kind, old.Topic, old.Content = insight.Kind, insight.Topic, insight.Content
+		if len(insight.Embedding) > 0 {
+			old.Embedding = insight.Embedding
+			if err := s.appendVector(old.ID, old.Embedding); err != nil { return err }
+		}
+		return s.saveToFile()
+	}
+	insight.ID = 1
+	if n := len(s.Data.Insights); n > 0 { insight.ID = s.Data.Insights[n-1].ID + 1 }
+	if insight.CreatedAt.IsZero() { insight.CreatedAt = time.Now() }
+	if len(insight.Embedding) > 0 {
+		if err := s.appendVector(insight.ID, insight.Embedding); err != nil { return err }
+	}
+	s.Data.Insights = append(s.Data.Insights, insight)
+	if len(s.Data.Insights) > maxInsights {
+		s.Data.Insights = s.Data.Insights[len(s.Data.Insights)-maxInsights:]
+	}
+	return s.saveToFile()
+}
+
+func (s *JSONStorage) HasInsight(ctx context.Context, source, postID, contentHash string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.findInsight(source, postID, contentHash) >= 0, nil
+}
+
+// findInsight는 같은 원문 키의 insight 위치를 돌려줍니다 (해시가 비어 있거나 없으면 -1).
+func (s *JSONStorage) findInsight(source, postID, contentHash string) int {
+	if contentHash == "" { return -1 }
+	for i := len(s.Data.Insights) - 1; i >= 0; i-- {
+		in := s.Data.Insights[i]
+		if in.Source == source && in.PostID == postID && in.ContentHash == contentHash { return i }
+	}
+	return -1
+}
+
+func (s *JSONStorage) GetRecentInsights(ctx context.Context, limit int) ([]domain.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []domain.Insight
+	for i := len(s.Data.Insights) - 1; i >= 0 && len(res) < limit; i-- {
+		res = append(res, s.Data.Insights[i])
+	}
+	return res, nil
+}
+
+func (s *JSONStorage) SearchInsights(ctx context.Context, query []float32, k int) ([]domain.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return rankBySimilarity(s.Data.Insights, query, k), nil
+}
+
+func (s *JSONStorage) GetUnembeddedInsights(ctx context.Context, limit int) ([]domain.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []domain.Insight
+	for i := len(s.Data.Insights) - 1; i >= 0 && len(res) < limit; i-- {
+		if len(s.Data.Insights[i].Embedding) == 0 { res = append(res, s.Data.Insights[i]) }
+	}
+	return res, nil
+}
+
+func (s *JSONStorage) SaveInsightEmbedding(ctx context.Context, id int64, embedding []float32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.Data.Insights {
+		if s.Data.Insights[i].ID == id {
+			s.Data.Insights[i].Embedding = embedding
+			return s.appendVector(id, embedding) // 본문 파일은 바뀌지 않음
+		}
+	}
+	return nil
+}
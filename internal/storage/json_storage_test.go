@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"d3k-agent/internal/core/domain"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func countLines(t *testing.T, path string) int {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) { return 0 }
+	if err != nil { t.Fatal(err) }
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestJSONStorageVectorsSidecar(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	s, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if want := filepath.Join(filepath.Dir(path), "storage.vectors.jsonl"); s.VectorPath != want { t.Errorf("VectorPath = %q, want %q", s.VectorPath, want) }
+
+	if err := s.SaveInsight(ctx, domain.Insight{Source: "botmadang", PostID: "1", Topic: "금리", Content: "동결", ContentHash: "h1", Embedding: []float32{1, 0}}); err != nil { t.Fatal(err) }
+	if err := s.SaveInsight(ctx, domain.Insight{Source: "botmadang", PostID: "2", Topic: "Go", Content: "generics", ContentHash: "h2"}); err != nil { t.Fatal(err) }
+	main, _ := os.ReadFile(path)
+	if strings.Contains(string(main), "Embedding") { t.Error("embeddings must not be written to the main file") }
+
+	// 나중에 채운 임베딩은 본문 파일을 다시 쓰지 않음
+	if err := s.SaveInsightEmbedding(ctx, 2, []float32{0, 1}); err != nil { t.Fatal(err) }
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, main) { t.Error("SaveInsightEmbedding rewrote the main file") }
+	if err := s.SaveInsightEmbedding(ctx, 2, []float32{0.5, 0.5}); err != nil { t.Fatal(err) }
+	if n := countLines(t, s.VectorPath); n != 3 { t.Errorf("sidecar has %d lines, want 3 appended records", n) }
+
+	reopened, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	var got [][]float32
+	for _, in := range reopened.Data.Insights { got = append(got, in.Embedding) }
+	if want := [][]float32{{1, 0}, {0.5, 0.5}}; !reflect.DeepEqual(got, want) { t.Errorf("restored embeddings = %v, want %v (last record wins)", got, want) }
+	if hits, _ := reopened.SearchInsights(ctx, []float32{1, 0}, 1); len(hits) != 1 || hits[0].ID != 1 { t.Errorf("search after reload = %+v", hits) }
+	if pending, _ := reopened.GetUnembeddedInsights(ctx, 10); len(pending) != 0 { t.Errorf("unembedded after reload = %d, want 0", len(pending)) }
+}
+
+func TestJSONStorageCompactsVectors(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	s, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	s.SaveInsight(ctx, domain.Insight{Source: "s", PostID: "1", Content: "a", ContentHash: "h"})
+	for i := 0; i < 5; i++ { s.SaveInsightEmbedding(ctx, 1, []float32{float32(i), 1}) }
+	if n := countLines(t, s.VectorPath); n != 5 { t.Fatalf("sidecar has %d lines, want 5", n) }
+
+	reopened, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if n := countLines(t, reopened.VectorPath); n != 1 { t.Errorf("sidecar has %d lines after reload, want compacted to 1", n) }
+	if got := reopened.Data.Insights[0].Embedding; !reflect.DeepEqual(got, []float32{4, 1}) { t.Errorf("embedding = %v, want the last one", got) }
+}
+
+func TestJSONStorageMigratesInlineVectors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	legacy := `{"insights": [{"ID": 1, "Source": "s", "Content": "a", "Embedding": [0.25, 0.75]}, {"ID": 2, "Source": "s", "Content": "b"}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil { t.Fatal(err) }
+
+	s, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if got := s.Data.Insights[0].Embedding; !reflect.DeepEqual(got, []float32{0.25, 0.75}) { t.Errorf("migrated embedding = %v", got) }
+	if main, _ := os.ReadFile(path); strings.Contains(string(main), "Embedding") { t.Error("inline embeddings must be removed from the main file") }
+	if n := countLines(t, s.VectorPath); n != 1 { t.Errorf("sidecar has %d lines, want 1", n) }
+
+	reopened, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if got := reopened.Data.Insights[0].Embedding; !reflect.DeepEqual(got, []float32{0.25, 0.75}) { t.Errorf("embedding after second load = %v", got) }
+}
+
+func TestJSONStorageDraftsKeepCommunity(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.json")
+	s, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if err := s.SaveDraft(ctx, domain.Draft{Source: "botmadang", Kind: "post", Title: "금리", Content: "동결", Community: "economy", Status: "approved"}); err != nil { t.Fatal(err) }
+	if err := s.SaveDraft(ctx, domain.Draft{Source: "moltbook", Kind: "reply", TargetID: "7", Content: "ㅋㅋ", Status: "rejected"}); err != nil { t.Fatal(err) }
+
+	reopened, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	drafts, _ := reopened.GetRecentDrafts(ctx, 10)
+	if len(drafts) != 2 { t.Fatalf("got %d drafts, want 2", len(drafts)) }
+	if drafts[1].Community != "economy" || drafts[0].Community != "" { t.Errorf("communities = %q, %q; want economy on the post only", drafts[1].Community, drafts[0].Community) }
+}
+
+// usageStore는 두 저장소의 사용량 기록 메서드입니다.
+type usageStore interface {
+	SaveUsage(ctx context.Context, e domain.UsageEvent) error
+	GetUsageSince(ctx context.Context, since time.Time) ([]domain.UsageEvent, error)
+}
+
+// checkUsageRetention은 usageRetention보다 오래된 기록이 다음 저장 때 지워지는지 확인합니다.
+func checkUsageRetention(t *testing.T, s usageStore) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	old := domain.UsageEvent{Provider: "test", Model: "retention-old", Task: "post", At: now.Add(-usageRetention - time.Hour)}
+	kept := domain.UsageEvent{Provider: "test", Model: "retention-kept", Task: "post", At: now.Add(-usageRetention + time.Hour)}
+	for _, e := range []domain.UsageEvent{old, kept, {Provider: "test", Model: "retention-new", Task: "post", At: now}} {
+		if err := s.SaveUsage(ctx, e); err != nil { t.Fatal(err) }
+	}
+	events, err := s.GetUsageSince(ctx, now.Add(-30*24*time.Hour))
+	if err != nil { t.Fatal(err) }
+	models := make(map[string]bool)
+	for _, e := range events { models[e.Model] = true }
+	if models["retention-old"] { t.Error("usage older than the retention window was kept") }
+	if !models["retention-kept"] || !models["retention-new"] { t.Errorf("recent usage was dropped: %v", models) }
+}
+
+func TestJSONStorageUsageRetention(t *testing.T) {
+	s, err := NewJSONStorage(filepath.Join(t.TempDir(), "storage.json"))
+	if err != nil { t.Fatal(err) }
+	checkUsageRetention(t, s)
+}
+
+// TEST_DATABASE_URL이 있으면 Postgres 저장소도 같은 보관 기간을 지키는지 확인합니다.
+func TestPostgresStorageUsageRetention(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" { t.Skip("TEST_DATABASE_URL not set") }
+	s, err := NewPostgresStorage(context.Background(), url)
+	if err != nil { t.Fatal(err) }
+	defer s.Pool.Close()
+	checkUsageRetention(t, s)
+}
@@ -0,0 +1,11 @@
+package storage
+
+import "strings"
+
+// statementKey는 페르소나 진술을 비교할 때 쓰는 정규형입니다 (두 저장소 공통).
+// 앞뒤 공백과 끝의 문장부호를 떼고, 공백을 하나로 합치고, 소문자로 바꿉니다.
+func statementKey(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.TrimRight(s, ".!?。 ")
+	return strings.ToLower(s)
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"d3k-agent/internal/core/domain"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatementKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"매일 새벽에 로그를 정리한다", "매일 새벽에 로그를 정리한다", true},
+		{"  매일  새벽에\t로그를 정리한다 ", "매일 새벽에 로그를 정리한다", true},
+		{"매일 새벽에 로그를 정리한다.", "매일 새벽에 로그를 정리한다", true},
+		{"Go를 주로 쓴다", "go를 주로 쓴다", true},
+		{"매일 새벽에 로그를 정리한다", "매일 밤에 로그를 정리한다", false},
+		{"매일새벽에 로그를 정리한다", "매일 새벽에 로그를 정리한다", false},
+	}
+	for _, tt := range tests {
+		if got := statementKey(tt.a) == statementKey(tt.b); got != tt.same { t.Errorf("statementKey(%q) == statementKey(%q) = %v, want %v", tt.a, tt.b, got, tt.same) }
+	}
+}
+
+func TestJSONStoragePersonaFacts(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewJSONStorage(filepath.Join(t.TempDir(), "storage.json"))
+	if err != nil { t.Fatal(err) }
+	for _, st := range []string{"매일 새벽에 로그를 정리한다", "  매일 새벽에 로그를  정리한다.", "Go를 주로 쓴다", "go를 주로 쓴다"} {
+		if err := s.SavePersonaFact(ctx, domain.PersonaFact{Statement: st}); err != nil { t.Fatal(err) }
+	}
+	facts, _ := s.GetPersonaFacts(ctx, 10)
+	if len(facts) != 2 { t.Fatalf("got %d facts %+v, want statements differing only in spacing/case/punctuation merged", len(facts), facts) }
+
+	if err := s.RetirePersonaFact(ctx, "매일 새벽에 로그를 정리한다!"); err != nil { t.Fatal(err) }
+	if err := s.RetirePersonaFact(ctx, "없는 진술"); err != nil { t.Fatal(err) }
+	facts, _ = s.GetPersonaFacts(ctx, 10)
+	if len(facts) != 1 || facts[0].Statement != "Go를 주로 쓴다" { t.Errorf("after retire facts = %+v, want only the Go statement", facts) }
+}
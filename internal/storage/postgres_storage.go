@@ -3,13 +3,19 @@ package storage
 import (
 	"context"
 	"d3k-agent/internal/core/domain"
+	"d3k-agent/internal/core/ports"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PostgresStorage struct {
-	Pool *pgxpool.Pool
+	Pool   *pgxpool.Pool
+	vector bool // insights.embedding이 pgvector 타입이면 true, REAL[] 폴백이면 false
+	dims   int  // PrepareVectorIndex로 고정한 embedding 차원 (0이면 아직 모름)
 }
 
 func NewPostgresStorage(ctx context.Context, connStr string) (*PostgresStorage, error) {
@@ -32,6 +38,27 @@ func (s *PostgresStorage) initSchema(ctx context.Context) error {
 		`CREATE TABLE IF NOT EXISTS post_stats (source TEXT PRIMARY KEY, count INT, last_date TEXT, last_timestamp BIGINT)`,
 		`CREATE TABLE IF NOT EXISTS comment_stats (source TEXT PRIMARY KEY, count INT, last_date TEXT)`,
 		`CREATE TABLE IF NOT EXISTS proactive_log (source TEXT, post_id TEXT, PRIMARY KEY(source, post_id))`,
+		`CREATE TABLE IF NOT EXISTS credentials (source TEXT PRIMARY KEY, secret TEXT, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE IF NOT EXISTS feed_items (
+			id TEXT PRIMARY KEY,
+			feed TEXT,
+			feed_title TEXT,
+			category TEXT,
+			title TEXT,
+			link TEXT,
+			summary TEXT,
+			published_at TIMESTAMP,
+			fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS heartbeats (
+			id SERIAL PRIMARY KEY,
+			source TEXT,
+			url TEXT,
+			hash TEXT,
+			content TEXT,
+			changed BOOLEAN,
+			fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
 		`CREATE TABLE IF NOT EXISTS insights (
 			id SERIAL PRIMARY KEY,
 			post_id TEXT,
@@ -40,6 +67,93 @@ func (s *PostgresStorage) initSchema(ctx context.Context) error {
 			content TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`CREATE TABLE IF NOT EXISTS relationships (
+			source TEXT,
+			author TEXT,
+			interactions INT,
+			topics TEXT[],
+			sentiment TEXT,
+			facts TEXT[],
+			last_exchange TEXT,
+			first_seen TIMESTAMP,
+			last_seen TIMESTAMP,
+			PRIMARY KEY(source, author)
+		)`,
+		`CREATE TABLE IF NOT EXISTS usage_events (
+			id SERIAL PRIMARY KEY,
+			provider TEXT,
+			model TEXT,
+			task TEXT,
+			site TEXT,
+			prompt_tokens INT,
+			output_tokens INT,
+			total_tokens INT,
+			failed BOOLEAN DEFAULT FALSE,
+			at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS usage_events_at ON usage_events (at)`,
+		`CREATE TABLE IF NOT EXISTS knowledge (
+			id SERIAL PRIMARY KEY,
+			kind TEXT,
+			topic TEXT,
+			content TEXT,
+			sources TEXT[],
+			insight_ids BIGINT[],
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS persona_facts (
+			id SERIAL PRIMARY KEY,
+			statement TEXT,
+			normalized TEXT,
+			source TEXT,
+			origin TEXT,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			last_seen TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS post_evaluations (
+			source TEXT,
+			post_id TEXT,
+			score INT,
+			reason TEXT,
+			model TEXT,
+			prompt_version TEXT,
+			content_hash TEXT,
+			comment_count INT,
+			evaluated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (source, post_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS llm_cache (
+			key TEXT PRIMARY KEY,
+			task TEXT,
+			value TEXT,
+			model TEXT,
+			expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS drafts (
+			id SERIAL PRIMARY KEY,
+			source TEXT,
+			kind TEXT,
+			target_id TEXT,
+			title TEXT,
+			content TEXT,
+			community TEXT,
+			prompt_version TEXT,
+			sources JSONB,
+			status TEXT,
+			candidate_group TEXT,
+			candidate_rank INT,
+			critique JSONB,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS sources JSONB`,
+		`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS candidate_group TEXT`,
+		`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS candidate_rank INT`,
+		`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS critique JSONB`,
+		`ALTER TABLE drafts ADD COLUMN IF NOT EXISTS community TEXT`,
+		`ALTER TABLE llm_cache ADD COLUMN IF NOT EXISTS model TEXT`,
+		`ALTER TABLE usage_events ADD COLUMN IF NOT EXISTS failed BOOLEAN DEFAULT FALSE`,
 	}
 
 	for _, q := range queries {
@@ -47,9 +161,130 @@ func (s *PostgresStorage) initSchema(ctx context.Context) error {
 			return fmt.Errorf("failed to init schema: %v", err)
 		}
 	}
+	if err := s.initVectors(ctx); err != nil { return err }
+	return s.initPersonaFacts(ctx)
+}
+
+// initPersonaFacts는 persona_facts의 유일성 기준을 원문에서 정규형(normalized, statementKey)으로 옮깁니다.
+// 정규형이 없는 예전 행은 채우고, 정규형이 같은 행은 먼저 저장된 행에 LastSeen을 합친 뒤 지웁니다.
+func (s *PostgresStorage) initPersonaFacts(ctx context.Context) error {
+	for _, q := range []string{
+		`ALTER TABLE persona_facts ADD COLUMN IF NOT EXISTS normalized TEXT`,
+		`ALTER TABLE persona_facts DROP CONSTRAINT IF EXISTS persona_facts_statement_key`,
+	} {
+		if _, err := s.Pool.Exec(ctx, q); err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+	}
+	rows, err := s.Pool.Query(ctx, "SELECT id, statement, normalized IS NULL, last_seen FROM persona_facts ORDER BY id")
+	if err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+	type fact struct {
+		id       int64
+		key      string
+		pending  bool
+		lastSeen time.Time
+	}
+	var facts []fact
+	for rows.Next() {
+		var f fact
+		var statement string
+		if err := rows.Scan(&f.id, &statement, &f.pending, &f.lastSeen); err != nil { rows.Close(); return fmt.Errorf("failed to init schema: %v", err) }
+		f.key = statementKey(statement)
+		facts = append(facts, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+
+	first := map[string]int64{}
+	for _, f := range facts {
+		if !f.pending { first[f.key] = f.id }
+	}
+	for _, f := range facts {
+		if !f.pending { continue }
+		if id, ok := first[f.key]; ok {
+			if _, err := s.Pool.Exec(ctx, "UPDATE persona_facts SET last_seen = GREATEST(last_seen, $2) WHERE id = $1", id, f.lastSeen); err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+			if _, err := s.Pool.Exec(ctx, "DELETE FROM persona_facts WHERE id = $1", f.id); err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+			continue
+		}
+		if _, err := s.Pool.Exec(ctx, "UPDATE persona_facts SET normalized = $2 WHERE id = $1", f.id, f.key); err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+		first[f.key] = f.id
+	}
+	if _, err := s.Pool.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS persona_facts_normalized_key ON persona_facts (normalized)`); err != nil {
+		return fmt.Errorf("failed to init schema: %v", err)
+	}
+	return nil
+}
+
+// initVectors는 insights에 kind/content_hash/embedding 컬럼을 추가합니다.
+// (source, post_id, content_hash)는 해시가 있는 행끼리만 유일합니다 (해시 없이 저장된 예전 행은 그대로 둠).
+// pgvector 확장을 쓸 수 있으면 vector 타입으로, 아니면 REAL[]로 만들고 검색은 전수 비교로 합니다.
+// 컬럼 차원과 색인은 임베더가 정해진 뒤 PrepareVectorIndex에서 맞춥니다.
+func (s *PostgresStorage) initVectors(ctx context.Context) error {
+	for _, q := range []string{
+		`ALTER TABLE insights ADD COLUMN IF NOT EXISTS kind TEXT DEFAULT ''`,
+		`ALTER TABLE insights ADD COLUMN IF NOT EXISTS content_hash TEXT DEFAULT ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS insights_content_key ON insights (source, post_id, content_hash) WHERE content_hash <> ''`,
+	} {
+		if _, err := s.Pool.Exec(ctx, q); err != nil { return fmt.Errorf("failed to init schema: %v", err) }
+	}
+	column := "vector"
+	if _, err := s.Pool.Exec(ctx, `CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+		fmt.Printf("⚠️  pgvector unavailable, using brute-force insight search: %v\n", err)
+		column = "REAL[]"
+	}
+	if _, err := s.Pool.Exec(ctx, `ALTER TABLE insights ADD COLUMN IF NOT EXISTS embedding `+column); err != nil {
+		return fmt.Errorf("failed to init schema: %v", err)
+	}
+	var udt string
+	if err := s.Pool.QueryRow(ctx, `SELECT udt_name FROM information_schema.columns WHERE table_name = 'insights' AND column_name = 'embedding'`).Scan(&udt); err != nil {
+		return fmt.Errorf("failed to init schema: %v", err)
+	}
+	s.vector = udt == "vector"
+	return nil
+}
+
+// hnswMaxDims는 pgvector HNSW 색인이 지원하는 최대 차원입니다.
+const hnswMaxDims = 2000
+
+var _ ports.VectorIndexer = (*PostgresStorage)(nil)
+
+// PrepareVectorIndex는 embedding 컬럼을 vector(dims)로 고정하고 코사인 거리 HNSW 색인을 만듭니다.
+// 차원이 바뀌면(임베딩 모델 변경) 다른 차원의 벡터는 비워 embedBacklog가 새 모델로 다시 채우게 합니다.
+// pgvector가 없으면(REAL[] 폴백) 아무것도 하지 않습니다.
+func (s *PostgresStorage) PrepareVectorIndex(ctx context.Context, dims int) error {
+	if !s.vector || dims <= 0 { return nil }
+	var typmod int
+	if err := s.Pool.QueryRow(ctx, `SELECT atttypmod FROM pg_attribute WHERE attrelid = 'insights'::regclass AND attname = 'embedding'`).Scan(&typmod); err != nil {
+		return fmt.Errorf("vector index: %v", err)
+	}
+	if typmod != dims {
+		fmt.Printf("🧬 Setting insight embeddings to vector(%d)\n", dims)
+		for _, q := range []string{
+			`DROP INDEX IF EXISTS insights_embedding_idx`,
+			fmt.Sprintf(`UPDATE insights SET embedding = NULL WHERE vector_dims(embedding) <> %d`, dims),
+			fmt.Sprintf(`ALTER TABLE insights ALTER COLUMN embedding TYPE vector(%d)`, dims),
+		} {
+			if _, err := s.Pool.Exec(ctx, q); err != nil { return fmt.Errorf("vector index: %v", err) }
+		}
+	}
+	s.dims = dims
+	if dims > hnswMaxDims {
+		fmt.Printf("⚠️  %d-dim embeddings exceed the HNSW limit (%d), using exact vector search\n", dims, hnswMaxDims)
+		return nil
+	}
+	if _, err := s.Pool.Exec(ctx, `CREATE INDEX IF NOT EXISTS insights_embedding_idx ON insights USING hnsw (embedding vector_cosine_ops)`); err != nil {
+		return fmt.Errorf("vector index: %v", err)
+	}
 	return nil
 }
 
+// embeddingArg는 embedding 컬럼 타입에 맞는 인자와 캐스트입니다. 벡터가 없거나 고정된 차원과 다르면 NULL입니다.
+func (s *PostgresStorage) embeddingArg(v []float32) (interface{}, string) {
+	cast := ""
+	if s.vector { cast = "::vector" }
+	if len(v) == 0 || (s.dims > 0 && len(v) != s.dims) { return nil, cast }
+	if s.vector { return vectorLiteral(v), cast }
+	return v, cast
+}
+
 func (s *PostgresStorage) SaveCursor(source, cursor string) error {
 	_, err := s.Pool.Exec(context.Background(), 
 		"INSERT INTO cursors (source, cursor) VALUES ($1, $2) ON CONFLICT (source) DO UPDATE SET cursor = $2", 
@@ -109,21 +344,316 @@ func (s *PostgresStorage) MarkProactive(source, postID string) error {
 	return err
 }
 
+func (s *PostgresStorage) CredentialsReady() error { return credentialsReady() }
+
+func (s *PostgresStorage) SaveCredential(ctx context.Context, source, secret string) error {
+	sealed, err := sealSecret(source, secret)
+	if err != nil { return err }
+	_, err = s.Pool.Exec(ctx,
+		"INSERT INTO credentials (source, secret) VALUES ($1, $2) ON CONFLICT (source) DO UPDATE SET secret = $2, updated_at = CURRENT_TIMESTAMP",
+		source, sealed)
+	return err
+}
+
+func (s *PostgresStorage) LoadCredential(ctx context.Context, source string) (string, error) {
+	var sealed string
+	err := s.Pool.QueryRow(ctx, "SELECT secret FROM credentials WHERE source = $1", source).Scan(&sealed)
+	if errors.Is(err, pgx.ErrNoRows) { return "", nil }
+	if err != nil { return "", err }
+	return openSecret(source, sealed)
+}
+
+func (s *PostgresStorage) SaveFeedItems(ctx context.Context, items []domain.FeedItem) ([]domain.FeedItem, error) {
+	var added []domain.FeedItem
+	for _, it := range items {
+		tag, err := s.Pool.Exec(ctx,
+			`INSERT INTO feed_items (id, feed, feed_title, category, title, link, summary, published_at, fetched_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (id) DO NOTHING`,
+			it.ID, it.Feed, it.FeedTitle, it.Category, it.Title, it.Link, it.Summary, it.PublishedAt, it.FetchedAt)
+		if err != nil { return added, err }
+		if tag.RowsAffected() > 0 { added = append(added, it) }
+	}
+	return added, nil
+}
+
+func (s *PostgresStorage) GetRecentFeedItems(ctx context.Context, limit int) ([]domain.FeedItem, error) {
+	rows, err := s.Pool.Query(ctx, "SELECT id, feed, feed_title, category, title, link, summary, published_at, fetched_at FROM feed_items ORDER BY published_at DESC LIMIT $1", limit)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var res []domain.FeedItem
+	for rows.Next() {
+		var it domain.FeedItem
+		if err := rows.Scan(&it.ID, &it.Feed, &it.FeedTitle, &it.Category, &it.Title, &it.Link, &it.Summary, &it.PublishedAt, &it.FetchedAt); err != nil { return nil, err }
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
+
+func (s *PostgresStorage) SaveHeartbeat(ctx context.Context, hb domain.Heartbeat) error {
+	_, err := s.Pool.Exec(ctx, "INSERT INTO heartbeats (source, url, hash, content, changed, fetched_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		hb.Source, hb.URL, hb.Hash, hb.Content, hb.Changed, hb.FetchedAt)
+	return err
+}
+
+func (s *PostgresStorage) GetLatestHeartbeat(ctx context.Context, source string) (*domain.Heartbeat, error) {
+	var hb domain.Heartbeat
+	err := s.Pool.QueryRow(ctx, "SELECT id, source, url, hash, content, changed, fetched_at FROM heartbeats WHERE source = $1 ORDER BY fetched_at DESC, id DESC LIMIT 1", source).
+		Scan(&hb.ID, &hb.Source, &hb.URL, &hb.Hash, &hb.Content, &hb.Changed, &hb.FetchedAt)
+	if errors.Is(err, pgx.ErrNoRows) { return nil, nil }
+	if err != nil { return nil, err }
+	return &hb, nil
+}
+
 func (s *PostgresStorage) SaveInsight(ctx context.Context, i domain.Insight) error {
-	_, err := s.Pool.Exec(ctx, "INSERT INTO insights (post_id, source, topic, content) VALUES ($1, $2, $3, $4)", i.PostID, i.Source, i.Topic, i.Content)
+	emb, cast := s.embeddingArg(i.Embedding)
+	if i.ContentHash == "" {
+		_, err := s.Pool.Exec(ctx, "INSERT INTO insights (post_id, source, kind, topic, content, embedding) VALUES ($1, $2, $3, $4, $5, $6"+cast+")",
+			i.PostID, i.Source, i.Kind, i.Topic, i.Content, emb)
+		return err
+	}
+	_, err := s.Pool.Exec(ctx, `INSERT INTO insights (post_id, source, kind, topic, content, content_hash, embedding) VALUES ($1, $2, $3, $4, $5, $6, $7`+cast+`)
+		ON CONFLICT (source, post_id, content_hash) WHERE content_hash <> '' DO UPDATE SET kind = EXCLUDED.kind, topic = EXCLUDED.topic, content = EXCLUDED.content,
+			embedding = COALESCE(EXCLUDED.embedding, insights.embedding)`,
+		i.PostID, i.Source, i.Kind, i.Topic, i.Content, i.ContentHash, emb)
 	return err
 }
 
+func (s *PostgresStorage) HasInsight(ctx context.Context, source, postID, contentHash string) (bool, error) {
+	var exists bool
+	err := s.Pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM insights WHERE source = $1 AND post_id = $2 AND content_hash = $3)", source, postID, contentHash).Scan(&exists)
+	return exists, err
+}
+
 func (s *PostgresStorage) GetRecentInsights(ctx context.Context, limit int) ([]domain.Insight, error) {
-	rows, err := s.Pool.Query(ctx, "SELECT id, post_id, source, topic, content, created_at FROM insights ORDER BY created_at DESC LIMIT $1", limit)
+	return s.queryInsights(ctx, "SELECT id, post_id, source, kind, topic, content, created_at FROM insights ORDER BY created_at DESC LIMIT $1", limit)
+}
+
+func (s *PostgresStorage) GetUnembeddedInsights(ctx context.Context, limit int) ([]domain.Insight, error) {
+	return s.queryInsights(ctx, "SELECT id, post_id, source, kind, topic, content, created_at FROM insights WHERE embedding IS NULL ORDER BY created_at DESC LIMIT $1", limit)
+}
+
+func (s *PostgresStorage) queryInsights(ctx context.Context, query string, args ...interface{}) ([]domain.Insight, error) {
+	rows, err := s.Pool.Query(ctx, query, args...)
 	if err != nil { return nil, err }
 	defer rows.Close()
 
 	var res []domain.Insight
 	for rows.Next() {
 		var i domain.Insight
-		rows.Scan(&i.ID, &i.PostID, &i.Source, &i.Topic, &i.Content, &i.CreatedAt)
+		var kind *string
+		if err := rows.Scan(&i.ID, &i.PostID, &i.Source, &kind, &i.Topic, &i.Content, &i.CreatedAt); err != nil { return nil, err }
+		if kind != nil { i.Kind = *kind }
 		res = append(res, i)
 	}
-	return res, nil
-}
\ No newline at end of file
+	return res, rows.Err()
+}
+
+func (s *PostgresStorage) SaveInsightEmbedding(ctx context.Context, id int64, embedding []float32) error {
+	emb, cast := s.embeddingArg(embedding)
+	_, err := s.Pool.Exec(ctx, "UPDATE insights SET embedding = $1"+cast+" WHERE id = $2", emb, id)
+	return err
+}
+
+func (s *PostgresStorage) SearchInsights(ctx context.Context, query []float32, k int) ([]domain.Insight, error) {
+	if len(query) == 0 { return nil, nil }
+	if !s.vector {
+		rows, err := s.Pool.Query(ctx, "SELECT id, post_id, source, kind, topic, content, created_at, embedding FROM insights WHERE array_length(embedding, 1) = $1", len(query))
+		if err != nil { return nil, err }
+		defer rows.Close()
+		var candidates []domain.Insight
+		for rows.Next() {
+			var i domain.Insight
+			var kind *string
+			if err := rows.Scan(&i.ID, &i.PostID, &i.Source, &kind, &i.Topic, &i.Content, &i.CreatedAt, &i.Embedding); err != nil { return nil, err }
+			if kind != nil { i.Kind = *kind }
+			candidates = append(candidates, i)
+		}
+		if err := rows.Err(); err != nil { return nil, err }
+		return rankBySimilarity(candidates, query, k), nil
+	}
+
+	var rows pgx.Rows
+	var err error
+	if s.dims > 0 {
+		// 차원이 고정되면 모든 벡터가 같은 차원이므로 차원 조건 없이 HNSW 색인 순서로 읽음
+		if len(query) != s.dims { return nil, nil }
+		rows, err = s.Pool.Query(ctx, `SELECT id, post_id, source, kind, topic, content, created_at, 1 - (embedding <=> $1::vector)
+			FROM insights WHERE embedding IS NOT NULL
+			ORDER BY embedding <=> $1::vector LIMIT $2`, vectorLiteral(query), k)
+	} else {
+		rows, err = s.Pool.Query(ctx, `SELECT id, post_id, source, kind, topic, content, created_at, 1 - (embedding <=> $1::vector)
+			FROM insights WHERE embedding IS NOT NULL AND vector_dims(embedding) = $2
+			ORDER BY embedding <=> $1::vector LIMIT $3`, vectorLiteral(query), len(query), k)
+	}
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var res []domain.Insight
+	for rows.Next() {
+		var i domain.Insight
+		var kind *string
+		if err := rows.Scan(&i.ID, &i.PostID, &i.Source, &kind, &i.Topic, &i.Content, &i.CreatedAt, &i.Similarity); err != nil { return nil, err }
+		if kind != nil { i.Kind = *kind }
+		res = append(res, i)
+	}
+	return res, rows.Err()
+}
+
+func (s *PostgresStorage) SaveKnowledge(ctx context.Context, k domain.Knowledge) error {
+	if k.UpdatedAt.IsZero() { k.UpdatedAt = time.Now() }
+	if k.ID == 0 {
+		_, err := s.Pool.Exec(ctx, "INSERT INTO knowledge (kind, topic, content, sources, insight_ids, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
+			k.Kind, k.Topic, k.Content, k.Sources, k.InsightIDs, k.UpdatedAt)
+		return err
+	}
+	_, err := s.Pool.Exec(ctx, "UPDATE knowledge SET kind = $1, topic = $2, content = $3, sources = $4, insight_ids = $5, updated_at = $6 WHERE id = $7",
+		k.Kind, k.Topic, k.Content, k.Sources, k.InsightIDs, k.UpdatedAt, k.ID)
+	return err
+}
+
+func (s *PostgresStorage) GetKnowledge(ctx context.Context, limit int) ([]domain.Knowledge, error) {
+	rows, err := s.Pool.Query(ctx, "SELECT id, kind, topic, content, sources, insight_ids, created_at, updated_at FROM knowledge ORDER BY updated_at DESC, id DESC LIMIT $1", limit)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var res []domain.Knowledge
+	for rows.Next() {
+		var k domain.Knowledge
+		if err := rows.Scan(&k.ID, &k.Kind, &k.Topic, &k.Content, &k.Sources, &k.InsightIDs, &k.CreatedAt, &k.UpdatedAt); err != nil { return nil, err }
+		res = append(res, k)
+	}
+	return res, rows.Err()
+}
+
+func (s *PostgresStorage) DeleteStaleKnowledge(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.Pool.Exec(ctx, "DELETE FROM knowledge WHERE updated_at < $1", before)
+	if err != nil { return 0, err }
+	return int(tag.RowsAffected()), nil
+}
+
+func (s *PostgresStorage) SavePersonaFact(ctx context.Context, f domain.PersonaFact) error {
+	if f.LastSeen.IsZero() { f.LastSeen = time.Now() }
+	_, err := s.Pool.Exec(ctx, `INSERT INTO persona_facts (statement, normalized, source, origin, last_seen) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (normalized) DO UPDATE SET last_seen = EXCLUDED.last_seen`,
+		f.Statement, statementKey(f.Statement), f.Source, f.Origin, f.LastSeen)
+	return err
+}
+
+func (s *PostgresStorage) RetirePersonaFact(ctx context.Context, statement string) error {
+	_, err := s.Pool.Exec(ctx, "DELETE FROM persona_facts WHERE normalized = $1", statementKey(statement))
+	return err
+}
+
+func (s *PostgresStorage) GetPersonaFacts(ctx context.Context, limit int) ([]domain.PersonaFact, error) {
+	rows, err := s.Pool.Query(ctx, "SELECT id, statement, source, origin, created_at, last_seen FROM persona_facts ORDER BY last_seen DESC, id DESC LIMIT $1", limit)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var res []domain.PersonaFact
+	for rows.Next() {
+		var f domain.PersonaFact
+		if err := rows.Scan(&f.ID, &f.Statement, &f.Source, &f.Origin, &f.CreatedAt, &f.LastSeen); err != nil { return nil, err }
+		res = append(res, f)
+	}
+	return res, rows.Err()
+}
+
+func (s *PostgresStorage) GetRelationship(ctx context.Context, source, author string) (*domain.Relationship, error) {
+	var r domain.Relationship
+	err := s.Pool.QueryRow(ctx, "SELECT source, author, interactions, topics, sentiment, facts, last_exchange, first_seen, last_seen FROM relationships WHERE source = $1 AND author = $2", source, author).
+		Scan(&r.Source, &r.Author, &r.Interactions, &r.Topics, &r.Sentiment, &r.Facts, &r.LastExchange, &r.FirstSeen, &r.LastSeen)
+	if errors.Is(err, pgx.ErrNoRows) { return nil, nil }
+	if err != nil { return nil, err }
+	return &r, nil
+}
+
+func (s *PostgresStorage) SaveRelationship(ctx context.Context, r domain.Relationship) error {
+	_, err := s.Pool.Exec(ctx,
+		`INSERT INTO relationships (source, author, interactions, topics, sentiment, facts, last_exchange, first_seen, last_seen)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (source, author) DO UPDATE SET interactions = $3, topics = $4, sentiment = $5, facts = $6, last_exchange = $7, last_seen = $9`,
+		r.Source, r.Author, r.Interactions, r.Topics, r.Sentiment, r.Facts, r.LastExchange, r.FirstSeen, r.LastSeen)
+	return err
+}
+
+// SaveUsage는 기록을 추가하고 usageRetention보다 오래된 기록을 지웁니다 (JSON 저장소와 같은 보관 기간).
+func (s *PostgresStorage) SaveUsage(ctx context.Context, e domain.UsageEvent) error {
+	_, err := s.Pool.Exec(ctx, "INSERT INTO usage_events (provider, model, task, site, prompt_tokens, output_tokens, total_tokens, failed, at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		e.Provider, e.Model, e.Task, e.Site, e.PromptTokens, e.OutputTokens, e.TotalTokens, e.Failed, e.At)
+	if err != nil { return err }
+	_, err = s.Pool.Exec(ctx, "DELETE FROM usage_events WHERE at < $1", time.Now().Add(-usageRetention))
+	return err
+}
+
+func (s *PostgresStorage) GetUsageSince(ctx context.Context, since time.Time) ([]domain.UsageEvent, error) {
+	rows, err := s.Pool.Query(ctx, "SELECT id, provider, model, task, site, prompt_tokens, output_tokens, total_tokens, COALESCE(failed, FALSE), at FROM usage_events WHERE at >= $1 ORDER BY at, id", since)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var res []domain.UsageEvent
+	for rows.Next() {
+		var e domain.UsageEvent
+		if err := rows.Scan(&e.ID, &e.Provider, &e.Model, &e.Task, &e.Site, &e.PromptTokens, &e.OutputTokens, &e.TotalTokens, &e.Failed, &e.At); err != nil { return nil, err }
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+func (s *PostgresStorage) GetPostEvaluation(ctx context.Context, source, postID string) (*domain.PostEvaluation, error) {
+	e := domain.PostEvaluation{Source: source, PostID: postID}
+	err := s.Pool.QueryRow(ctx, "SELECT score, reason, model, prompt_version, content_hash, comment_count, evaluated_at FROM post_evaluations WHERE source = $1 AND post_id = $2", source, postID).
+		Scan(&e.Score, &e.Reason, &e.Model, &e.PromptVersion, &e.ContentHash, &e.CommentCount, &e.EvaluatedAt)
+	if errors.Is(err, pgx.ErrNoRows) { return nil, nil }
+	if err != nil { return nil, err }
+	return &e, nil
+}
+
+func (s *PostgresStorage) SavePostEvaluation(ctx context.Context, e domain.PostEvaluation) error {
+	if e.EvaluatedAt.IsZero() { e.EvaluatedAt = time.Now() }
+	_, err := s.Pool.Exec(ctx, `INSERT INTO post_evaluations (source, post_id, score, reason, model, prompt_version, content_hash, comment_count, evaluated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (source, post_id) DO UPDATE SET score = EXCLUDED.score, reason = EXCLUDED.reason, model = EXCLUDED.model, prompt_version = EXCLUDED.prompt_version,
+			content_hash = EXCLUDED.content_hash, comment_count = EXCLUDED.comment_count, evaluated_at = EXCLUDED.evaluated_at`,
+		e.Source, e.PostID, e.Score, e.Reason, e.Model, e.PromptVersion, e.ContentHash, e.CommentCount, e.EvaluatedAt)
+	return err
+}
+
+func (s *PostgresStorage) GetCachedResponse(ctx context.Context, key string) (*domain.CacheEntry, error) {
+	var e domain.CacheEntry
+	err := s.Pool.QueryRow(ctx, "SELECT key, task, value, COALESCE(model, ''), expires_at, created_at FROM llm_cache WHERE key = $1 AND expires_at > NOW()", key).
+		Scan(&e.Key, &e.Task, &e.Value, &e.Model, &e.ExpiresAt, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) { return nil, nil }
+	if err != nil { return nil, err }
+	return &e, nil
+}
+
+// SaveCachedResponse는 항목을 덮어쓰고 만료된 항목을 지웁니다.
+func (s *PostgresStorage) SaveCachedResponse(ctx context.Context, e domain.CacheEntry) error {
+	_, err := s.Pool.Exec(ctx, `INSERT INTO llm_cache (key, task, value, model, expires_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key) DO UPDATE SET task = EXCLUDED.task, value = EXCLUDED.value, model = EXCLUDED.model, expires_at = EXCLUDED.expires_at, created_at = CURRENT_TIMESTAMP`,
+		e.Key, e.Task, e.Value, e.Model, e.ExpiresAt)
+	if err != nil { return err }
+	_, err = s.Pool.Exec(ctx, "DELETE FROM llm_cache WHERE expires_at < NOW()")
+	return err
+}
+
+func (s *PostgresStorage) SaveDraft(ctx context.Context, d domain.Draft) error {
+	_, err := s.Pool.Exec(ctx, "INSERT INTO drafts (source, kind, target_id, title, content, community, prompt_version, sources, status, candidate_group, candidate_rank, critique) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+		d.Source, d.Kind, d.TargetID, d.Title, d.Content, d.Community, d.PromptVersion, d.Sources, d.Status, d.Group, d.Rank, d.Critique)
+	return err
+}
+
+func (s *PostgresStorage) GetRecentDrafts(ctx context.Context, limit int) ([]domain.Draft, error) {
+	rows, err := s.Pool.Query(ctx, "SELECT id, source, kind, target_id, title, content, COALESCE(community, ''), prompt_version, COALESCE(sources, 'null'), status, COALESCE(candidate_group, ''), COALESCE(candidate_rank, 0), COALESCE(critique, 'null'), created_at FROM drafts ORDER BY created_at DESC, id DESC LIMIT $1", limit)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var res []domain.Draft
+	for rows.Next() {
+		var d domain.Draft
+		if err := rows.Scan(&d.ID, &d.Source, &d.Kind, &d.TargetID, &d.Title, &d.Content, &d.Community, &d.PromptVersion, &d.Sources, &d.Status, &d.Group, &d.Rank, &d.Critique, &d.CreatedAt); err != nil { return nil, err }
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// sealedPrefix는 사이트 이름을 추가 인증 데이터(AAD)로 묶어 암호화한 값의 접두사입니다.
+// 접두사가 없는 예전 값은 AAD 없이 복호화합니다.
+const sealedPrefix = "v2:"
+
+// credentialCipher는 CREDENTIAL_SECRET 환경 변수로부터 AES-256-GCM 암호기를 만듭니다.
+func credentialCipher() (cipher.AEAD, error) {
+	secret := os.Getenv("CREDENTIAL_SECRET")
+	if secret == "" { return nil, fmt.Errorf("CREDENTIAL_SECRET is required to store credentials") }
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil { return nil, err }
+	return cipher.NewGCM(block)
+}
+
+// credentialsReady는 자격 증명을 암호화해 저장할 수 있는지 확인합니다 (두 저장소 공통).
+func credentialsReady() error {
+	_, err := credentialCipher()
+	return err
+}
+
+// sealSecret은 평문 키를 암호화해 nonce와 함께 base64 문자열로 돌려줍니다.
+// source를 추가 인증 데이터로 넣어, 다른 사이트의 암호문으로 바꿔치기하면 복호화가 실패합니다.
+func sealSecret(source, plain string) (string, error) {
+	gcm, err := credentialCipher()
+	if err != nil { return "", err }
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil { return "", err }
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), []byte(source))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openSecret(source, encoded string) (string, error) {
+	gcm, err := credentialCipher()
+	if err != nil { return "", err }
+	var aad []byte
+	if rest, ok := strings.CutPrefix(encoded, sealedPrefix); ok { encoded, aad = rest, []byte(source) }
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil { return "", err }
+	if len(raw) < gcm.NonceSize() { return "", fmt.Errorf("credential too short") }
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], aad)
+	if err != nil { return "", fmt.Errorf("failed to decrypt credential: %v", err) }
+	return string(plain), nil
+}
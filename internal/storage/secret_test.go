@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSealSecret(t *testing.T) {
+	t.Setenv("CREDENTIAL_SECRET", "s3cret")
+	sealed, err := sealSecret("botmadang", "api-key-123")
+	if err != nil { t.Fatal(err) }
+	if strings.Contains(sealed, "api-key-123") { t.Fatalf("sealed value contains the plain key: %q", sealed) }
+	if again, _ := sealSecret("botmadang", "api-key-123"); again == sealed { t.Error("sealing twice gave the same ciphertext, want a fresh nonce") }
+
+	tests := []struct {
+		name    string
+		secret  string
+		source  string
+		value   string
+		wantErr bool
+	}{
+		{"round trip", "s3cret", "botmadang", sealed, false},
+		{"wrong secret", "other", "botmadang", sealed, true},
+		{"swapped between sites", "s3cret", "moltbook", sealed, true},
+		{"missing secret", "", "botmadang", sealed, true},
+		{"corrupt", "s3cret", "botmadang", sealedPrefix + "!!!", true},
+		{"too short", "s3cret", "botmadang", sealedPrefix + base64.StdEncoding.EncodeToString([]byte("x")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CREDENTIAL_SECRET", tt.secret)
+			got, err := openSecret(tt.source, tt.value)
+			if tt.wantErr {
+				if err == nil { t.Errorf("openSecret = %q, want error", got) }
+				return
+			}
+			if err != nil || got != "api-key-123" { t.Errorf("openSecret = %q, %v; want the original key", got, err) }
+		})
+	}
+}
+
+func TestSealSecretRequiresSecret(t *testing.T) {
+	t.Setenv("CREDENTIAL_SECRET", "")
+	if _, err := sealSecret("botmadang", "k"); err == nil || !strings.Contains(err.Error(), "CREDENTIAL_SECRET") { t.Errorf("sealSecret err = %v, want CREDENTIAL_SECRET error", err) }
+	if err := credentialsReady(); err == nil { t.Error("credentialsReady without CREDENTIAL_SECRET, want error") }
+	t.Setenv("CREDENTIAL_SECRET", "s3cret")
+	if err := credentialsReady(); err != nil { t.Errorf("credentialsReady: %v", err) }
+}
+
+// 접두사 없는 예전 값(AAD 없이 암호화)도 읽을 수 있어야 함
+func TestOpenLegacySecret(t *testing.T) {
+	t.Setenv("CREDENTIAL_SECRET", "s3cret")
+	key := sha256.Sum256([]byte("s3cret"))
+	block, _ := aes.NewCipher(key[:])
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte("old-key"), nil))
+	if got, err := openSecret("botmadang", legacy); err != nil || got != "old-key" { t.Errorf("openSecret(legacy) = %q, %v", got, err) }
+}
+
+func TestJSONStorageCredentials(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("CREDENTIAL_SECRET", "s3cret")
+	path := filepath.Join(t.TempDir(), "storage.json")
+	s, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if got, err := s.LoadCredential(ctx, "botmadang"); err != nil || got != "" { t.Errorf("LoadCredential before save = %q, %v; want empty", got, err) }
+	if err := s.SaveCredential(ctx, "botmadang", "api-key-123"); err != nil { t.Fatal(err) }
+	if raw, _ := os.ReadFile(path); strings.Contains(string(raw), "api-key-123") { t.Error("storage file contains the plain key") }
+
+	reopened, err := NewJSONStorage(path)
+	if err != nil { t.Fatal(err) }
+	if got, err := reopened.LoadCredential(ctx, "botmadang"); err != nil || got != "api-key-123" { t.Errorf("LoadCredential = %q, %v", got, err) }
+
+	t.Setenv("CREDENTIAL_SECRET", "other")
+	if _, err := reopened.LoadCredential(ctx, "botmadang"); err == nil { t.Error("LoadCredential with a wrong secret, want error") }
+	t.Setenv("CREDENTIAL_SECRET", "")
+	if err := reopened.SaveCredential(ctx, "moltbook", "k"); err == nil { t.Error("SaveCredential without CREDENTIAL_SECRET, want error") }
+}
+
+// TEST_DATABASE_URL이 있으면 Postgres 저장소에서도 같은 왕복을 확인합니다.
+func TestPostgresStorageCredentials(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" { t.Skip("TEST_DATABASE_URL not set") }
+	ctx := context.Background()
+	t.Setenv("CREDENTIAL_SECRET", "s3cret")
+	s, err := NewPostgresStorage(ctx, url)
+	if err != nil { t.Fatal(err) }
+	defer s.Pool.Close()
+	if err := s.SaveCredential(ctx, "test-site", "api-key-123"); err != nil { t.Fatal(err) }
+	if got, err := s.LoadCredential(ctx, "test-site"); err != nil || got != "api-key-123" { t.Errorf("LoadCredential = %q, %v", got, err) }
+	t.Setenv("CREDENTIAL_SECRET", "other")
+	if _, err := s.LoadCredential(ctx, "test-site"); err == nil { t.Error("LoadCredential with a wrong secret, want error") }
+}
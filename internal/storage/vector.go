@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"d3k-agent/internal/core/domain"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cosine은 두 벡터의 코사인 유사도입니다. 차원이 다르거나 영벡터면 0입니다.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 { return 0 }
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 { return 0 }
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// rankBySimilarity는 pgvector가 없을 때 쓰는 전수 비교 검색입니다.
+func rankBySimilarity(candidates []domain.Insight, query []float32, k int) []domain.Insight {
+	var res []domain.Insight
+	for _, in := range candidates {
+		if len(in.Embedding) != len(query) { continue }
+		in.Similarity = cosine(query, in.Embedding)
+		res = append(res, in)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Similarity > res[j].Similarity })
+	if len(res) > k { res = res[:k] }
+	return res
+}
+
+// vectorLiteral은 pgvector 입력 형식 "[0.1,0.2,...]"입니다.
+func vectorLiteral(v []float32) string {
+	parts := make([]string, len(v))
+	for i, x := range v { parts[i] = strconv.FormatFloat(float64(x), 'g', -1, 32) }
+	return "[" + strings.Join(parts, ",") + "]"
+}
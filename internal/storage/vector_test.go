@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"d3k-agent/internal/core/domain"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2}, []float32{2, 4}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 1}, []float32{-1, -1}, -1},
+		{"dimension mismatch", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 { t.Errorf("cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want) }
+		})
+	}
+}
+
+func TestRankBySimilarity(t *testing.T) {
+	candidates := []domain.Insight{
+		{ID: 1, Embedding: []float32{0, 1}},
+		{ID: 2, Embedding: []float32{1, 0}},
+		{ID: 3},                                // 임베딩 없음
+		{ID: 4, Embedding: []float32{1, 1}},
+		{ID: 5, Embedding: []float32{1, 0, 0}}, // 다른 모델의 차원
+		{ID: 6, Embedding: []float32{2, 0}},    // 2와 같은 방향 (먼저 나온 2가 앞)
+	}
+	tests := []struct {
+		name  string
+		query []float32
+		k     int
+		want  []int64
+	}{
+		{"nearest first", []float32{1, 0}, 10, []int64{2, 6, 4, 1}},
+		{"top k", []float32{0, 1}, 2, []int64{1, 4}},
+		{"other dimension", []float32{1, 0, 0}, 10, []int64{5}},
+		{"no candidates with dimension", []float32{1}, 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, in := range rankBySimilarity(candidates, tt.query, tt.k) {
+				got = append(got, in.ID)
+				if in.Similarity != cosine(tt.query, in.Embedding) { t.Errorf("insight %d similarity = %v", in.ID, in.Similarity) }
+			}
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("ranked = %v, want %v", got, tt.want) }
+		})
+	}
+	if candidates[0].Similarity != 0 { t.Error("rankBySimilarity modified its input") }
+}
+
+func TestVectorLiteral(t *testing.T) {
+	tests := []struct {
+		in   []float32
+		want string
+	}{
+		{nil, "[]"},
+		{[]float32{1}, "[1]"},
+		{[]float32{0.1, -2.5, 1e-7}, "[0.1,-2.5,1e-07]"},
+	}
+	for _, tt := range tests {
+		if got := vectorLiteral(tt.in); got != tt.want { t.Errorf("vectorLiteral(%v) = %q, want %q", tt.in, got, tt.want) }
+	}
+}
@@ -18,6 +18,13 @@ type TelegramUI struct {
 	lastResp ports.UserAction
 	respMu   sync.Mutex
 	lastMsgID int
+
+	// Prompt 응답용 텍스트 메시지
+	lastText    string
+	lastReplyTo int
+
+	// /명령 처리기 (HandleCommand로 등록)
+	commands map[string]func(ctx context.Context) string
 }
 
 func NewTelegramUI(token string, chatIDStr string) (*TelegramUI, error) {
@@ -28,8 +35,9 @@ func NewTelegramUI(token string, chatIDStr string) (*TelegramUI, error) {
 	if err != nil { return nil, err }
 
 	ui := &TelegramUI{
-		Bot:    bot,
-		ChatID: chatID,
+		Bot:      bot,
+		ChatID:   chatID,
+		commands: make(map[string]func(ctx context.Context) string),
 	}
 
 	go ui.listen()
@@ -42,6 +50,18 @@ func (ui *TelegramUI) listen() {
 	updates := ui.Bot.GetUpdatesChan(u)
 
 	for update := range updates {
+		if msg := update.Message; msg != nil && msg.Chat != nil && msg.Chat.ID == ui.ChatID && msg.Text != "" {
+			if handler := ui.command(msg); handler != nil {
+				go ui.reply(msg.MessageID, handler)
+				continue
+			}
+			ui.respMu.Lock()
+			ui.lastText = msg.Text
+			ui.lastReplyTo = 0
+			if msg.ReplyToMessage != nil { ui.lastReplyTo = msg.ReplyToMessage.MessageID }
+			ui.respMu.Unlock()
+			continue
+		}
 		if update.CallbackQuery == nil { continue }
 
 		callback := update.CallbackQuery
@@ -60,27 +80,109 @@ func (ui *TelegramUI) listen() {
 	}
 }
 
-func (ui *TelegramUI) Confirm(ctx context.Context, title, body string) (ports.UserAction, error) {
-	msgText := fmt.Sprintf("*[%s]*\n\n%s", escapeMarkdown(title), escapeMarkdown(body))
-	msg := tgbotapi.NewMessage(ui.ChatID, msgText)
-	msg.ParseMode = "Markdown"
+var _ ports.Commander = (*TelegramUI)(nil)
+
+func (ui *TelegramUI) HandleCommand(name string, handler func(ctx context.Context) string) {
+	ui.respMu.Lock()
+	defer ui.respMu.Unlock()
+	ui.commands[strings.TrimPrefix(name, "/")] = handler
+}
+
+// command는 등록된 /명령 메시지면 처리기를 돌려줍니다 ("/status@botname" 형식 포함).
+func (ui *TelegramUI) command(msg *tgbotapi.Message) func(ctx context.Context) string {
+	if !msg.IsCommand() { return nil }
+	ui.respMu.Lock()
+	defer ui.respMu.Unlock()
+	return ui.commands[msg.Command()]
+}
+
+func (ui *TelegramUI) reply(replyTo int, handler func(ctx context.Context) string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	msg := tgbotapi.NewMessage(ui.ChatID, handler(ctx))
+	msg.ReplyToMessageID = replyTo
+	ui.Bot.Send(msg)
+}
 
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+func (ui *TelegramUI) Confirm(ctx context.Context, title, body string) (ports.UserAction, error) {
+	markup := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("✅ 승인", string(ports.ActionApprove)),
 			tgbotapi.NewInlineKeyboardButtonData("🔄 재구성", string(ports.ActionRegenerate)),
 			tgbotapi.NewInlineKeyboardButtonData("❌ 거절", string(ports.ActionSkip)),
 		),
 	)
-
-	sentMsg, err := ui.Bot.Send(msg)
+	sentMsg, err := ui.sendLong(title, body, markup)
 	if err != nil { return ports.ActionSkip, err }
+	return ui.waitAction(ctx, sentMsg.MessageID)
+}
+
+var _ ports.Chooser = (*TelegramUI)(nil)
 
+// Choose는 후보마다 "✅ 라벨" 버튼을 붙여 보냅니다. 버튼 값은 "approve:번호"입니다.
+func (ui *TelegramUI) Choose(ctx context.Context, title, body string, labels []string) (int, ports.UserAction, error) {
+	var pick []tgbotapi.InlineKeyboardButton
+	for i, label := range labels {
+		pick = append(pick, tgbotapi.NewInlineKeyboardButtonData("✅ "+label, fmt.Sprintf("%s:%d", ports.ActionApprove, i)))
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(pick...),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 재구성", string(ports.ActionRegenerate)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 거절", string(ports.ActionSkip)),
+		),
+	)
+	sentMsg, err := ui.sendLong(title, body, markup)
+	if err != nil { return -1, ports.ActionSkip, err }
+	action, err := ui.waitAction(ctx, sentMsg.MessageID)
+	if err != nil { return -1, action, err }
+	value, index, ok := strings.Cut(string(action), ":")
+	if !ok { return -1, action, nil }
+	idx, err := strconv.Atoi(index)
+	if err != nil || idx < 0 || idx >= len(labels) { return -1, ports.ActionSkip, nil }
+	return idx, ports.UserAction(value), nil
+}
+
+// maxMessageRunes는 한 메시지에 담을 본문 길이입니다 (텔레그램 한도 4096자에서 제목과 이스케이프 여유를 뺌).
+const maxMessageRunes = 3500
+
+// sendLong은 본문이 길면 줄 단위로 나눠 보내고, 버튼(markup)은 마지막 메시지에 붙입니다.
+func (ui *TelegramUI) sendLong(title, body string, markup interface{}) (tgbotapi.Message, error) {
+	chunks := splitMessage(body, maxMessageRunes)
+	for i, chunk := range chunks {
+		text := escapeMarkdown(chunk)
+		if i == 0 { text = fmt.Sprintf("*[%s]*\n\n%s", escapeMarkdown(title), text) }
+		msg := tgbotapi.NewMessage(ui.ChatID, text)
+		msg.ParseMode = "Markdown"
+		if i < len(chunks)-1 {
+			if _, err := ui.Bot.Send(msg); err != nil { return tgbotapi.Message{}, err }
+			continue
+		}
+		msg.ReplyMarkup = markup
+		return ui.Bot.Send(msg)
+	}
+	return tgbotapi.Message{}, nil
+}
+
+func splitMessage(text string, limit int) []string {
+	var chunks []string
+	var cur []rune
+	for _, line := range strings.SplitAfter(text, "\n") {
+		r := []rune(line)
+		if len(cur)+len(r) > limit && len(cur) > 0 { chunks = append(chunks, string(cur)); cur = nil }
+		for len(r) > limit { chunks = append(chunks, string(r[:limit])); r = r[limit:] }
+		cur = append(cur, r...)
+	}
+	return append(chunks, string(cur))
+}
+
+// waitAction은 messageID 메시지의 버튼이 눌릴 때까지 기다립니다.
+func (ui *TelegramUI) waitAction(ctx context.Context, messageID int) (ports.UserAction, error) {
 	// 응답 대기 루프 (Polling 응답 대기)
 	for {
 		ui.respMu.Lock()
 		// 방금 보낸 메시지 ID에 대한 응답인지 확인
-		if ui.lastMsgID == sentMsg.MessageID && ui.lastResp != "" {
+		if ui.lastMsgID == messageID && ui.lastResp != "" {
 			action := ui.lastResp
 			ui.lastResp = "" // 초기화
 			ui.respMu.Unlock()
@@ -97,6 +199,46 @@ func (ui *TelegramUI) Confirm(ctx context.Context, title, body string) (ports.Us
 	}
 }
 
+func (ui *TelegramUI) Notify(ctx context.Context, title, body string) error {
+	msg := tgbotapi.NewMessage(ui.ChatID, fmt.Sprintf("*[%s]*\n\n%s", escapeMarkdown(title), escapeMarkdown(body)))
+	msg.ParseMode = "Markdown"
+	_, err := ui.Bot.Send(msg)
+	return err
+}
+
+func (ui *TelegramUI) Prompt(ctx context.Context, title, body string) (string, error) {
+	msgText := fmt.Sprintf("*[%s]*\n\n%s", escapeMarkdown(title), escapeMarkdown(body))
+	msg := tgbotapi.NewMessage(ui.ChatID, msgText)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+
+	ui.respMu.Lock()
+	ui.lastText = "" // 이전에 받은 메시지는 무시
+	ui.respMu.Unlock()
+
+	sentMsg, err := ui.Bot.Send(msg)
+	if err != nil { return "", err }
+
+	// 답장(Reply) 또는 이후에 도착한 일반 메시지를 답변으로 사용
+	for {
+		ui.respMu.Lock()
+		if ui.lastText != "" && (ui.lastReplyTo == 0 || ui.lastReplyTo == sentMsg.MessageID) {
+			text := strings.TrimSpace(ui.lastText)
+			ui.lastText = ""
+			ui.respMu.Unlock()
+			return text, nil
+		}
+		ui.respMu.Unlock()
+
+		select {
+		case <-time.After(500 * time.Millisecond):
+			continue
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
 func escapeMarkdown(text string) string {
 	// 텔레그램 마크다운 V1에서 문제가 되는 핵심 문자들 이스케이프
 	replacer := strings.NewReplacer(
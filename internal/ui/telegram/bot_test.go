@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"fits", "안녕하세요", 10, []string{"안녕하세요"}},
+		{"empty", "", 10, []string{""}},
+		{"exact limit", "가나다", 3, []string{"가나다"}},
+		{"splits on lines", "첫째 줄\n둘째 줄\n셋째", 9, []string{"첫째 줄\n", "둘째 줄\n셋째"}},
+		{"keeps lines together when possible", "aa\nbb\ncc\n", 6, []string{"aa\nbb\n", "cc\n"}},
+		{"hard splits long line by runes", "가나다라마바", 4, []string{"가나다라", "마바"}},
+		{"long line after short", "a\n가나다라마바사", 3, []string{"a\n", "가나다", "라마바", "사"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.text, tt.limit)
+			if !reflect.DeepEqual(got, tt.want) { t.Errorf("splitMessage(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want) }
+			if strings.Join(got, "") != tt.text { t.Error("chunks must join back to the original text") }
+			for _, c := range got {
+				if n := len([]rune(c)); n > tt.limit { t.Errorf("chunk %q has %d runes, limit %d", c, n, tt.limit) }
+			}
+		})
+	}
+}